@@ -0,0 +1,98 @@
+/*
+Copyright The Registry Operator Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ImagePolicyMode describes how the violations of an ImagePolicy are handled.
+// +kubebuilder:validation:Enum=Audit;Enforce
+type ImagePolicyMode string
+
+const (
+	// ImagePolicyModeAudit admits the violating Pods with a warning.
+	ImagePolicyModeAudit ImagePolicyMode = "Audit"
+	// ImagePolicyModeEnforce rejects the violating Pods.
+	ImagePolicyModeEnforce ImagePolicyMode = "Enforce"
+)
+
+const (
+	// ImagePolicyNamespaceLabel opts a namespace in the ImagePolicies when set to ImagePolicyNamespaceEnabled.
+	// The Pods of the other namespaces are not sent to the webhook enforcing the ImagePolicies.
+	ImagePolicyNamespaceLabel = "registry-operator.dev/image-policy"
+	// ImagePolicyNamespaceEnabled is the value of ImagePolicyNamespaceLabel opting a namespace in.
+	ImagePolicyNamespaceEnabled = "enabled"
+)
+
+// ImagePolicySpec restricts the images of the Pods in the selected namespaces to the ones pulled from
+// the endpoints of the allowed Registries.
+type ImagePolicySpec struct {
+	// NamespaceSelector selects the namespaces the policy applies to, among the ones labeled with
+	// registry-operator.dev/image-policy=enabled. An empty selector selects every labeled namespace.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// Registries are the Registries the Pods may pull from. When empty, every Registry managed
+	// by the operator is allowed.
+	// +optional
+	Registries []RegistryReference `json:"registries,omitempty"`
+
+	// Mode is Audit to admit the violating Pods with a warning, or Enforce to reject them.
+	// +optional
+	// +kubebuilder:default="Audit"
+	Mode ImagePolicyMode `json:"mode,omitempty"`
+}
+
+// RegistryReference references a Registry in any namespace.
+type RegistryReference struct {
+	// Namespace is the namespace of the Registry.
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the Registry.
+	Name string `json:"name"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Mode",type="string",JSONPath=".spec.mode"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ImagePolicy is the Schema for the imagepolicies API.
+// It is enforced by the validating admission webhook of the operator when the Pods are created or updated,
+// ephemeral containers included, in the namespaces labeled with registry-operator.dev/image-policy=enabled.
+// The webhook rejects the Pods of these namespaces when it is unavailable, so the namespace of the operator
+// must not be labeled. The Pods of the workloads the operator runs for the Registries are exempt, as long as
+// they only run the images of their workload.
+type ImagePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ImagePolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ImagePolicyList contains a list of ImagePolicy.
+type ImagePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ImagePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ImagePolicy{}, &ImagePolicyList{})
+}
@@ -0,0 +1,211 @@
+/*
+Copyright The Registry Operator Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const ImageSyncKind = "ImageSync"
+
+// ImageSyncSpec defines the desired state of ImageSync.
+type ImageSyncSpec struct {
+	// Source is the registry the images are copied from.
+	Source ImageSyncSource `json:"source"`
+
+	// Target is the Registry the images are copied to.
+	Target ImageSyncTarget `json:"target"`
+
+	// Images selects the images to copy.
+	// +kubebuilder:validation:MinItems=1
+	Images []ImageFilter `json:"images"`
+
+	// Schedule is the cron expression the images are synced on, e.g. "0 * * * *" or "@daily".
+	// +optional
+	// +kubebuilder:default="@hourly"
+	Schedule string `json:"schedule,omitempty"`
+
+	// Suspend stops the synchronization, without removing the synced images.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// ImageSyncSource defines the registry the images are copied from.
+//nolint:lll // kubebuilder directives
+// +kubebuilder:validation:XValidation:rule="has(self.registryRef) != has(self.url)",message="exactly one of registryRef or url must be set"
+type ImageSyncSource struct {
+	// RegistryRef references a Registry in the namespace of the ImageSync.
+	// +optional
+	RegistryRef *corev1.LocalObjectReference `json:"registryRef,omitempty"`
+
+	// URL is the address of an external registry, e.g. https://registry-1.docker.io.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url,omitempty"`
+
+	// CredentialsSecretRef references a Secret of type kubernetes.io/dockerconfigjson or
+	// kubernetes.io/basic-auth holding the credentials for the registry.
+	// +optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// ImageSyncTarget defines the Registry the images are copied to.
+type ImageSyncTarget struct {
+	// RegistryRef references a Registry in the namespace of the ImageSync.
+	RegistryRef corev1.LocalObjectReference `json:"registryRef"`
+
+	// CredentialsSecretRef references a Secret of type kubernetes.io/dockerconfigjson or
+	// kubernetes.io/basic-auth holding the credentials for the Registry.
+	// +optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+
+	// RepositoryPrefix is prepended to the name of the repositories in the target Registry, e.g. mirror/.
+	// +optional
+	RepositoryPrefix string `json:"repositoryPrefix,omitempty"`
+}
+
+// ImageFilter selects the tags of a repository.
+type ImageFilter struct {
+	// Repository is the name of the repository in the source registry, e.g. library/nginx.
+	// +kubebuilder:validation:MinLength=1
+	Repository string `json:"repository"`
+
+	// Tags lists regular expressions of the tags to copy. Defaults to all the tags.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+}
+
+// ImageSyncResultStatus is the outcome of the synchronization of an image.
+// +kubebuilder:validation:Enum=Synced;UpToDate;Failed
+type ImageSyncResultStatus string
+
+const (
+	// ImageSyncResultSynced means that the image was copied.
+	ImageSyncResultSynced ImageSyncResultStatus = "Synced"
+	// ImageSyncResultUpToDate means that the target already had the image.
+	ImageSyncResultUpToDate ImageSyncResultStatus = "UpToDate"
+	// ImageSyncResultFailed means that the image could not be copied.
+	ImageSyncResultFailed ImageSyncResultStatus = "Failed"
+)
+
+// ImageSyncResult is the outcome of the last synchronization of an image.
+type ImageSyncResult struct {
+	// Image is the reference of the image in the target Registry, e.g. mirror/library/nginx:1.27.
+	Image string `json:"image"`
+
+	// Digest of the image manifest.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// Status is the outcome of the synchronization.
+	Status ImageSyncResultStatus `json:"status"`
+
+	// Message details the failure, if any.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ImageSyncSummary counts the images of a synchronization by outcome.
+type ImageSyncSummary struct {
+	// Synced is the number of copied images.
+	// +optional
+	Synced int32 `json:"synced,omitempty"`
+
+	// UpToDate is the number of images the target already had.
+	// +optional
+	UpToDate int32 `json:"upToDate,omitempty"`
+
+	// Failed is the number of images which could not be copied.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+
+	// Pending is the number of images left to the next reconciliations of a synchronization in progress.
+	// +optional
+	Pending int32 `json:"pending,omitempty"`
+}
+
+// ImageSyncStatus defines the observed state of ImageSync.
+type ImageSyncStatus struct {
+	// Conditions represent the latest available observations of the ImageSync state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// ObservedGeneration is the most recent generation observed for this ImageSync.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastSyncTime is the time of the last synchronization.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// NextSyncTime is the time of the next scheduled synchronization.
+	// +optional
+	NextSyncTime *metav1.Time `json:"nextSyncTime,omitempty"`
+
+	// Summary counts the images of the last synchronization by outcome.
+	// +optional
+	Summary *ImageSyncSummary `json:"summary,omitempty"`
+
+	// ResumeAfter is the last image handled by a synchronization in progress, which copies its images over
+	// several reconciliations. The next reconciliation resumes after it.
+	// +optional
+	ResumeAfter string `json:"resumeAfter,omitempty"`
+
+	// Images lists the outcome of the last synchronization for up to 100 images, the failed ones first.
+	// +optional
+	// +listType=map
+	// +listMapKey=image
+	// +kubebuilder:validation:MaxItems=100
+	Images []ImageSyncResult `json:"images,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.target.registryRef.name"
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule"
+//nolint:lll // kubebuilder directives
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Last Sync",type="date",JSONPath=".status.lastSyncTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ImageSync is the Schema for the imagesyncs API.
+// It copies images from a source registry into a Registry on a schedule.
+type ImageSync struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ImageSyncSpec   `json:"spec,omitempty"`
+	Status ImageSyncStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ImageSyncList contains a list of ImageSync.
+type ImageSyncList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ImageSync `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ImageSync{}, &ImageSyncList{})
+}
@@ -18,11 +18,17 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // RegistrySpec defines the desired state of Registry.
 type RegistrySpec struct {
+	// RegistryClassName is the name of the RegistryClass providing the defaults of this Registry.
+	// If not set when the Registry is created, it is set to the default RegistryClass, if any.
+	// +optional
+	RegistryClassName string `json:"registryClassName,omitempty"`
+
 	// Image indicates the container image to use for the Registry.
 	// +optional
 	Image string `json:"image,omitempty"`
@@ -44,9 +50,597 @@ type RegistrySpec struct {
 	// It allows specifying different storage sources to manage storage lifecycle and persistence.
 	// +optional
 	Storage Storage `json:"storage,omitempty"`
+
+	// StorageUpdateStrategy is how a change of the storage is rolled out. Replace serves the images from the new
+	// storage right away. Migrate keeps serving them from the previous storage in read-only mode while a Job copies
+	// them to the new storage and verifies their digests, then switches to the new storage, reported by the
+	// StorageMigrated condition. It migrates between a persistentVolumeClaim or persistentVolumeClaimTemplate
+	// storage and an s3 storage with static credentials. A failed migration is retried once its Job is deleted.
+	// +optional
+	// +kubebuilder:default="Replace"
+	StorageUpdateStrategy StorageUpdateStrategy `json:"storageUpdateStrategy,omitempty"`
+
+	// PersistentVolumeClaimRetentionPolicy is Retain to keep the PersistentVolumeClaim created from the
+	// persistentVolumeClaimTemplate once the storage no longer uses it, releasing it from the Registry,
+	// or Delete to delete it.
+	// +optional
+	// +kubebuilder:default="Retain"
+	PersistentVolumeClaimRetentionPolicy ClaimRetentionPolicy `json:"persistentVolumeClaimRetentionPolicy,omitempty"`
+
+	// DeletionPolicy is what happens to the stored images when the Registry is deleted.
+	// Retain keeps the PersistentVolumeClaim created from the persistentVolumeClaimTemplate, released from
+	// the Registry, and the S3 objects. Delete deletes the PersistentVolumeClaim and the S3 objects under the
+	// root directory of the Registry. Snapshot takes a VolumeSnapshot of the PersistentVolumeClaim before
+	// deleting it, the S3 objects being kept. The images are retained when the namespace is being deleted, or
+	// when the S3 credentials are gone.
+	// +optional
+	// +kubebuilder:default="Retain"
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// RevisionHistoryLimit is the number of previous configuration Secrets and ReplicaSets kept
+	// to allow rolling the Registry back. Defaults to 3.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// RolloutPolicy configures how the changes of the image and configuration are rolled out.
+	// +optional
+	RolloutPolicy *RolloutPolicy `json:"rolloutPolicy,omitempty"`
+
+	// UpdatePolicy keeps the image of the Registry on the newest release of a channel. It cannot be set
+	// with image.
+	// +optional
+	UpdatePolicy *UpdatePolicy `json:"updatePolicy,omitempty"`
+
+	// Log configures the logging of the Registry.
+	// +optional
+	Log *Log `json:"log,omitempty"`
+
+	// Monitoring configures the monitoring resources generated for the Registry.
+	// +optional
+	Monitoring *Monitoring `json:"monitoring,omitempty"`
+
+	// Authentication requires the clients of the Registry to authenticate as one of its RegistryUsers.
+	// +optional
+	Authentication *Authentication `json:"authentication,omitempty"`
+
+	// NetworkPolicy configures the NetworkPolicy restricting the traffic of the Registry pods.
+	// +optional
+	NetworkPolicy *NetworkPolicy `json:"networkPolicy,omitempty"`
+
+	// PullSecretDistribution replicates a pull Secret of the Registry into the namespaces consuming it.
+	// +optional
+	PullSecretDistribution *PullSecretDistribution `json:"pullSecretDistribution,omitempty"`
+
+	// Proxy runs the Registry as a pull-through cache of a remote registry.
+	// +optional
+	Proxy *Proxy `json:"proxy,omitempty"`
+
+	// NodeMirror configures containerd on the nodes to pull the images of upstream registries through the Registry.
+	// +optional
+	NodeMirror *NodeMirror `json:"nodeMirror,omitempty"`
+
+	// Backup periodically archives the stored images to an S3-compatible bucket.
+	// It requires a persistentVolumeClaim or persistentVolumeClaimTemplate storage.
+	// +optional
+	Backup *Backup `json:"backup,omitempty"`
+
+	// Snapshots takes scheduled VolumeSnapshots of the PersistentVolumeClaim created from the
+	// persistentVolumeClaimTemplate, and restores it from a VolumeSnapshot when it is created.
+	// It requires the CSI snapshot CRDs and a persistentVolumeClaimTemplate storage.
+	// +optional
+	Snapshots *Snapshots `json:"snapshots,omitempty"`
+}
+
+// RolloutPolicy configures the rollouts of the Registry Deployment.
+type RolloutPolicy struct {
+	// ProgressDeadlineSeconds is the time a rollout may take to make progress before it is considered failed.
+	// Defaults to 600 seconds.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// AutoRollback reverts the Registry to the image and configuration Secret of its last successful rollout
+	// when a rollout exceeds its progress deadline. The Registry is Degraded until its image or configuration
+	// changes again, and the failed revision is recorded in its status. The rolled back configuration
+	// authenticates the current RegistryUsers. A rollout which also changed the rest of the Pod template,
+	// such as the storage, resources or environment of the Registry, is not rolled back.
+	// +optional
+	AutoRollback bool `json:"autoRollback,omitempty"`
 }
 
+// RolloutRevision identifies a revision of the Registry Deployment.
+type RolloutRevision struct {
+	// Image is the container image of the Registry.
+	Image string `json:"image"`
+
+	// ConfigSecret is the name of the configuration Secret of the Registry, named after its hash.
+	ConfigSecret string `json:"configSecret"`
+
+	// Template is the hash of the rest of the Pod template of the Registry, which a rollback does not revert.
+	// +optional
+	Template string `json:"template,omitempty"`
+}
+
+// UpdatePolicy configures the automatic image updates of the Registry. The operator periodically lists the tags
+// of the source repository, resolves the newest release of the channel, and rolls it out inside the maintenance
+// window. A release older than the version of the Registry is never rolled out. The upgrade annotations hold the
+// updates as they hold the upgrades of the operator, and the decisions are recorded in the imageUpdate status.
+type UpdatePolicy struct {
+	// Channel is the release line followed by the Registry, as a version with a wildcard, e.g. "3.0.x" for the
+	// patch releases of 3.0, or "3.x" for the minor and patch releases of 3. Only the tags of full semantic
+	// versions are considered, pre-releases excluded.
+	// +kubebuilder:validation:Pattern=`^[0-9]+\.([0-9]+\.)?x$`
+	Channel string `json:"channel"`
+
+	// Source is the repository the releases are resolved from. Defaults to the repository of the default image.
+	// +optional
+	Source *UpdateSource `json:"source,omitempty"`
+
+	// Interval is the time between two resolutions of the channel. Defaults to 1h.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// MaintenanceWindow restricts the rollouts of the new releases to a recurring window.
+	// The new releases are rolled out as soon as they are resolved when it is not set.
+	// +optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+}
+
+// UpdateSource locates the repository the releases of an update channel are resolved from.
+type UpdateSource struct {
+	// Repository is the image repository, e.g. docker.io/library/registry, the image of the Registry being
+	// <repository>:<tag>. Defaults to the repository of the default image.
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// URL is the address of the registry serving the repository, e.g. https://registry-1.docker.io.
+	// Defaults to the host of the repository over HTTPS.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url,omitempty"`
+
+	// CredentialsSecretRef references a Secret of type kubernetes.io/dockerconfigjson or
+	// kubernetes.io/basic-auth holding the credentials for the registry.
+	// +optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// MaintenanceWindow is a recurring window of time.
+type MaintenanceWindow struct {
+	// Schedule is the cron schedule of the start of the window, e.g. "0 2 * * 0".
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// Duration is the length of the window, e.g. 2h.
+	Duration metav1.Duration `json:"duration"`
+}
+
+// ImageUpdateDecision is the outcome of the last resolution of the update channel of a Registry.
+// +kubebuilder:validation:Enum=Updated;UpToDate;Deferred;Held;Rejected;Failed
+type ImageUpdateDecision string
+
+const (
+	// ImageUpdateUpdated means the newest release of the channel was rolled out.
+	ImageUpdateUpdated ImageUpdateDecision = "Updated"
+	// ImageUpdateUpToDate means the Registry runs the newest release of the channel.
+	ImageUpdateUpToDate ImageUpdateDecision = "UpToDate"
+	// ImageUpdateDeferred means the newest release of the channel waits for the maintenance window.
+	ImageUpdateDeferred ImageUpdateDecision = "Deferred"
+	// ImageUpdateHeld means the newest release of the channel is held by an upgrade annotation.
+	ImageUpdateHeld ImageUpdateDecision = "Held"
+	// ImageUpdateRejected means the newest release of the channel is older than the version of the Registry.
+	ImageUpdateRejected ImageUpdateDecision = "Rejected"
+	// ImageUpdateFailed means the channel could not be resolved, or its newest release could not be applied.
+	ImageUpdateFailed ImageUpdateDecision = "Failed"
+)
+
+// ImageUpdate records the last resolution of the update channel of the Registry.
+type ImageUpdate struct {
+	// Channel is the channel that was resolved.
+	Channel string `json:"channel"`
+
+	// Image is the release of the channel run by the Registry.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// LatestImage is the newest release of the channel.
+	// +optional
+	LatestImage string `json:"latestImage,omitempty"`
+
+	// Decision is the outcome of the last resolution.
+	Decision ImageUpdateDecision `json:"decision"`
+
+	// Message is a human-readable explanation of the decision.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastCheckTime is the time the channel was last resolved.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+
+	// LastUpdateTime is the time a release of the channel was last rolled out.
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// Backup configures the scheduled backups of the Registry storage. Every backup switches the Registry to
+// read-only mode, archives its storage root into a gzipped tarball uploaded to the destination as
+// <prefix>/<backup name>.tar.gz, then switches it back. The backup names are the names of the Jobs created
+// by the backup CronJob, and are restored with a RegistryRestore.
+type Backup struct {
+	// Schedule is the cron schedule of the backups, e.g. "0 2 * * *".
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// Retention is the number of backups kept in the destination, the older ones being deleted
+	// after every successful backup.
+	// +optional
+	// +kubebuilder:default=7
+	// +kubebuilder:validation:Minimum=1
+	Retention int32 `json:"retention,omitempty"`
+
+	// Destination is the bucket the backups are uploaded to.
+	Destination BackupDestination `json:"destination"`
+}
+
+//nolint:lll // kubebuilder directives
+// +kubebuilder:validation:XValidation:rule="has(self.accessKey) && has(self.secretKey)",message="accessKey and secretKey are required, the backups are uploaded with static credentials"
+
+// BackupDestination locates the backups in an S3-compatible bucket, e.g. served by MinIO.
+// The backups are uploaded with the static credentials of the accessKey and secretKey, which are required:
+// the credentials of the service account or the node, e.g. IRSA, are not used.
+type BackupDestination struct {
+	S3StorageSource `json:",inline"`
+
+	// Prefix is the prefix of the keys of the backups in the bucket, e.g. backups/my-registry.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^([^/](.*[^/])?)?$`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// BackupRecord describes a backup or a VolumeSnapshot of the Registry.
+type BackupRecord struct {
+	// Name of the backup, to be restored with a RegistryRestore, or of the VolumeSnapshot.
+	Name string `json:"name"`
+
+	// CompletionTime is the time the backup completed, or the VolumeSnapshot was taken.
+	CompletionTime metav1.Time `json:"completionTime"`
+}
+
+// UpgradeRecord describes an automatic upgrade of the Registry from one release to the next.
+type UpgradeRecord struct {
+	// From is the version the Registry was upgraded from.
+	From string `json:"from"`
+
+	// To is the version the Registry was upgraded to.
+	To string `json:"to"`
+
+	// Time is the time the upgrade was applied, or first held.
+	Time metav1.Time `json:"time"`
+
+	// Changes lists the changes the upgrade routines made, or would make, to the spec of the Registry.
+	// +optional
+	Changes []string `json:"changes,omitempty"`
+}
+
+// Snapshots configures the VolumeSnapshots of the PersistentVolumeClaim of the Registry. The scheduled
+// VolumeSnapshots are not owned by the Registry, so that they outlive it.
+type Snapshots struct {
+	// Schedule is the cron schedule of the VolumeSnapshots, e.g. "0 2 * * *".
+	// No VolumeSnapshot is taken when it is not set.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Retention is the number of scheduled VolumeSnapshots ready to use kept, the older ones being deleted.
+	// +optional
+	// +kubebuilder:default=7
+	// +kubebuilder:validation:Minimum=1
+	Retention int32 `json:"retention,omitempty"`
+
+	// VolumeSnapshotClassName is the VolumeSnapshotClass of the scheduled VolumeSnapshots.
+	// Defaults to the default VolumeSnapshotClass of the CSI driver.
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+
+	// RestoreFrom is the name of a VolumeSnapshot in the namespace of the Registry the PersistentVolumeClaim
+	// is restored from through its dataSourceRef. It is only used when the PersistentVolumeClaim is created.
+	// +optional
+	RestoreFrom string `json:"restoreFrom,omitempty"`
+}
+
+// NodeMirror configures the nodes to use the Registry as a mirror of upstream registries. A DaemonSet writes
+// a containerd hosts.toml file for every upstream host, pointing to the cluster IP of the Registry Service, and
+// removes them when it is deleted. containerd falls back to the upstream host when the Registry is unavailable.
+// It requires a proxy Registry. The nodes reach the Registry from their own addresses, which must be allowed
+// by the networkPolicy when it restricts the sources of the registry API.
+type NodeMirror struct {
+	// Hosts are the upstream registries mirrored by the Registry, e.g. docker.io.
+	// They must be the remote registry of the proxy. Their existing hosts.toml files on the nodes are replaced.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Pattern=`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?(:[0-9]+)?$`
+	// +listType=set
+	Hosts []string `json:"hosts"`
+
+	// NodeSelector selects the nodes configured. Defaults to every node.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations allow the Pods configuring the nodes to be scheduled onto tainted nodes.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// ConfigPath is the directory of the registry host configurations of containerd on the nodes,
+	// its config_path setting. It is one of the default directories of containerd, k3s and RKE2.
+	// +optional
+	// +kubebuilder:default="/etc/containerd/certs.d"
+	ConfigPath string `json:"configPath,omitempty"`
+}
+
+// Proxy configures the Registry as a pull-through cache, a read-only mirror of a remote registry.
+type Proxy struct {
+	// RemoteURL is the URL of the mirrored registry, e.g. https://registry-1.docker.io.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	RemoteURL string `json:"remoteURL"`
+
+	// Username is an optional reference to the secret key containing the username
+	// used to authenticate to the mirrored registry.
+	// +optional
+	Username *SecretKeySelector `json:"username,omitempty"`
+
+	// Password is an optional reference to the secret key containing the password
+	// used to authenticate to the mirrored registry.
+	// +optional
+	Password *SecretKeySelector `json:"password,omitempty"`
+
+	// TTL is the time the cached content is kept for. Defaults to 7 days, zero keeps it forever.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// ImageRewrite rewrites the images of the Pods pulling from the mirrored registry to the Registry.
+	// +optional
+	ImageRewrite *ImageRewrite `json:"imageRewrite,omitempty"`
+}
+
+const (
+	// ImageRewriteAnnotation opts a Pod out of the image rewriting when set to ImageRewriteDisabled.
+	ImageRewriteAnnotation = "registry-operator.dev/image-rewrite"
+	// ImageRewriteDisabled is the value of ImageRewriteAnnotation opting a Pod out.
+	ImageRewriteDisabled = "disabled"
+
+	// ImageRewritesAnnotation records the images of a Pod rewritten to a Registry, or to be rewritten
+	// in dry-run mode, as a JSON object mapping the original images to the rewritten ones.
+	ImageRewritesAnnotation = "registry-operator.dev/image-rewrites"
+
+	// TrustedRegistriesAnnotation is set on a Namespace to list the Registries of other namespaces allowed to
+	// rewrite the images of its Pods and to distribute pull Secrets into it, as <namespace>/<name> entries
+	// separated by commas, <namespace>/* trusting every Registry of a namespace. A Registry is always trusted
+	// by its own namespace. Namespaces are cluster-scoped, so that only the cluster administrators grant it.
+	TrustedRegistriesAnnotation = "registry-operator.dev/trusted-registries"
+
+	// AllowStorageChangeAnnotation allows switching the storage backend of a Registry, or moving its location,
+	// losing the images it persisted. It is set to the metadata.generation of the Registry the change is made
+	// from, so that it only allows the next change of its spec.
+	AllowStorageChangeAnnotation = "registry-operator.dev/allow-storage-change"
+
+	// UpgradeHoldAnnotation blocks the automatic upgrades of a Registry when set to "true". A Registry left
+	// to the default image keeps running the image of its status version until the annotation is removed.
+	UpgradeHoldAnnotation = "registry-operator.dev/upgrade-hold"
+
+	// UpgradeDryRunAnnotation holds the automatic upgrades of a Registry like UpgradeHoldAnnotation when set
+	// to "true", reporting the changes they would make as Events instead.
+	UpgradeDryRunAnnotation = "registry-operator.dev/upgrade-dry-run"
+)
+
+// ImageRewrite configures the rewriting of the Pod images to the pull prefix of the Registry,
+// applied by the mutating admission webhook of the operator when the Pods are created. The images are only
+// rewritten once the pull prefix is a LoadBalancer, Ingress or Gateway endpoint the nodes can pull from.
+// A Pod opts out with the registry-operator.dev/image-rewrite: disabled annotation, the Pods of the Registries
+// themselves are never rewritten.
+type ImageRewrite struct {
+	// Prefixes are the prefixes of the normalized image references rewritten to the Registry,
+	// e.g. docker.io/ rewrites both nginx:1 and docker.io/library/nginx:1. They must start with the host of
+	// the remote registry, which is the only part of the images replaced by the pull prefix of the Registry.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	Prefixes []string `json:"prefixes"`
+
+	// NamespaceSelector selects the namespaces whose Pods are rewritten. An empty selector selects
+	// every namespace. The namespaces other than the one of the Registry must also trust it with the
+	// registry-operator.dev/trusted-registries annotation.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// DryRun only records the rewrites in the registry-operator.dev/image-rewrites annotation of the Pods,
+	// leaving their images untouched.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// PullSecretDistribution configures the replication of a pull Secret of the Registry into other namespaces.
+// The copies are deleted once their namespace stops matching, stops trusting the Registry, or the Registry
+// is deleted.
+type PullSecretDistribution struct {
+	// SecretRef references the kubernetes.io/dockerconfigjson Secret to replicate, in the namespace
+	// of the Registry, e.g. the one generated for a RegistryUser.
+	SecretRef corev1.LocalObjectReference `json:"secretRef"`
+
+	// NamespaceSelector selects the namespaces the Secret is replicated into. The namespaces other than
+	// the one of the Registry must also trust it with the registry-operator.dev/trusted-registries annotation.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// PatchDefaultServiceAccount adds the replicated Secret to the imagePullSecrets of the default
+	// ServiceAccount of every selected namespace.
+	// +optional
+	PatchDefaultServiceAccount bool `json:"patchDefaultServiceAccount,omitempty"`
+}
+
+// Log configures the logging of the Registry.
+type Log struct {
+	// Level is the granularity of the Registry logs. Defaults to debug.
+	// +optional
+	// +kubebuilder:validation:Enum=error;warn;info;debug
+	Level string `json:"level,omitempty"`
+
+	// Formatter selects the format of the Registry logs. Defaults to text.
+	// +optional
+	// +kubebuilder:validation:Enum=text;json;logstash
+	Formatter string `json:"formatter,omitempty"`
+}
+
+// NetworkPolicy configures the NetworkPolicy generated for the Registry pods.
+// Once enabled, only the traffic described here (and DNS lookups) is allowed.
+type NetworkPolicy struct {
+	// Enabled indicates whether the NetworkPolicy should be created for the Registry.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// From lists the namespaces, pods and CIDRs allowed to reach the registry API.
+	// If empty, the registry API is reachable from any source. The operator Pods are always allowed.
+	// +optional
+	From []networkingv1.NetworkPolicyPeer `json:"from,omitempty"`
+
+	// MonitoringNamespaceSelector selects the namespaces allowed to scrape the metrics port.
+	// If not set, the metrics port is not reachable.
+	// +optional
+	MonitoringNamespaceSelector *metav1.LabelSelector `json:"monitoringNamespaceSelector,omitempty"`
+
+	// Storage lists the peers serving the S3 storage endpoint. If empty, the peer is derived from
+	// the endpoint URL when it is an IP address, otherwise egress to the endpoint port is allowed
+	// to any destination.
+	// +optional
+	Storage []networkingv1.NetworkPolicyPeer `json:"storage,omitempty"`
+
+	// Proxy lists the peers serving the remote registry of the proxy, and its token service. If empty, the peer
+	// is derived from the remote URL when it is an IP address, otherwise egress to the remote port is allowed
+	// to any destination.
+	// +optional
+	Proxy []networkingv1.NetworkPolicyPeer `json:"proxy,omitempty"`
+
+	// Notifications lists the egress rules allowing the Registry to reach its notification targets.
+	// +optional
+	Notifications []networkingv1.NetworkPolicyEgressRule `json:"notifications,omitempty"`
+}
+
+// Authentication configures the htpasswd authentication of the Registry, built from its RegistryUsers.
+// The anonymous clients are rejected once it is enabled: it cannot be enabled with nodeMirror, nor with
+// proxy.imageRewrite unless pullSecretDistribution provides the rewritten Pods with credentials, and the
+// Repositories and ImageSyncs of the Registry must reference the credentials of a RegistryUser.
+type Authentication struct {
+	// Enabled indicates whether the clients must authenticate. Every client is rejected while the Registry
+	// has no RegistryUser.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// Monitoring configures the monitoring resources generated for the Registry.
+type Monitoring struct {
+	// Alerts configures the PrometheusRule with the alerts for the Registry.
+	// It requires the Prometheus Operator CRDs to be installed in the cluster.
+	// +optional
+	Alerts *Alerts `json:"alerts,omitempty"`
+}
+
+// Alerts configures the bundle of alerting rules generated for the Registry.
+// Every alert is enabled by default once the bundle itself is enabled. The alerts on the metrics of the
+// Registry select them by the namespace and service labels, set by a ServiceMonitor scraping the metrics port
+// of the Service of the Registry. No alert covers the garbage collection, the operator running no garbage
+// collection Job.
+type Alerts struct {
+	// Enabled indicates whether the PrometheusRule should be created for the Registry.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Labels are additional labels attached to every alert, e.g. to route them in Alertmanager.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// PodsNotReady fires when more Registry replicas than the threshold are not available.
+	// The threshold is the number of unavailable replicas and defaults to 0.
+	// +optional
+	PodsNotReady *AlertRule `json:"podsNotReady,omitempty"`
+
+	// StorageUnhealthy fires when the storage driver health check fails, which makes the
+	// Registry answer all requests with 503. The threshold is the number of such responses
+	// per second and defaults to 0.
+	// +optional
+	StorageUnhealthy *AlertRule `json:"storageUnhealthy,omitempty"`
+
+	// HighErrorRate fires when the percentage of 5xx responses exceeds the threshold.
+	// The threshold defaults to 5.
+	// +optional
+	HighErrorRate *AlertRule `json:"highErrorRate,omitempty"`
+
+	// PersistentVolumeFillingUp fires when the used space of the storage PersistentVolumeClaim
+	// exceeds the threshold percentage. It is only generated for PersistentVolumeClaim storage.
+	// The threshold defaults to 85.
+	// +optional
+	PersistentVolumeFillingUp *AlertRule `json:"persistentVolumeFillingUp,omitempty"`
+}
+
+// AlertRule overrides the defaults of a single generated alert.
+type AlertRule struct {
+	// Enabled indicates whether the alert is generated.
+	// +optional
+	// +default=true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Threshold overrides the value the alert expression is compared against.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Threshold *int32 `json:"threshold,omitempty"`
+
+	// For overrides how long the condition must hold before the alert fires.
+	// +optional
+	For *metav1.Duration `json:"for,omitempty"`
+
+	// Severity overrides the severity label of the alert.
+	// +optional
+	Severity string `json:"severity,omitempty"`
+}
+
+// ClaimRetentionPolicy describes what happens to the PersistentVolumeClaim of a Registry
+// once its storage no longer uses it.
+// +kubebuilder:validation:Enum=Retain;Delete
+type ClaimRetentionPolicy string
+
+const (
+	// ClaimRetentionPolicyRetain releases the PersistentVolumeClaim, which is kept with the stored images.
+	ClaimRetentionPolicyRetain ClaimRetentionPolicy = "Retain"
+	// ClaimRetentionPolicyDelete deletes the PersistentVolumeClaim.
+	ClaimRetentionPolicyDelete ClaimRetentionPolicy = "Delete"
+)
+
+// StorageUpdateStrategy describes how a change of the storage of a Registry is rolled out.
+// +kubebuilder:validation:Enum=Replace;Migrate
+type StorageUpdateStrategy string
+
+const (
+	// StorageUpdateStrategyReplace switches to the new storage, without the images of the previous one.
+	StorageUpdateStrategyReplace StorageUpdateStrategy = "Replace"
+	// StorageUpdateStrategyMigrate copies the images to the new storage before switching to it.
+	StorageUpdateStrategyMigrate StorageUpdateStrategy = "Migrate"
+)
+
+// DeletionPolicy describes what happens to the stored images of a Registry when it is deleted.
+// +kubebuilder:validation:Enum=Retain;Delete;Snapshot
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRetain keeps the stored images.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyDelete deletes the stored images.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicySnapshot takes a VolumeSnapshot of the stored images before deleting them.
+	DeletionPolicySnapshot DeletionPolicy = "Snapshot"
+)
+
+// DefaultRevisionHistoryLimit is the number of previous revisions of a Registry kept when its
+// RevisionHistoryLimit is not set.
+const DefaultRevisionHistoryLimit int32 = 3
+
 // Storage specifies various types of storage sources that a registry can use for persistence.
+// Once the images are persisted, the backend can only be switched, and the claim, host path, bucket,
+// region, endpoint or root directory changed, with the registry-operator.dev/allow-storage-change annotation
+// or the Migrate storageUpdateStrategy, and a persistentVolumeClaimTemplate cannot shrink.
 type Storage struct {
 	// EmptyDir represents a temporary directory that shares a pod's lifetime.
 	// +optional
@@ -78,19 +672,154 @@ type Storage struct {
 	S3 *S3StorageSource `json:"s3,omitempty"`
 }
 
+// Condition types reported in the status of a Registry.
+const (
+	// ConditionTypeAvailable indicates that the Registry is serving requests.
+	ConditionTypeAvailable = "Available"
+	// ConditionTypeProgressing indicates that a rollout of the Registry is in progress.
+	ConditionTypeProgressing = "Progressing"
+	// ConditionTypeDegraded indicates that the Registry failed to reach or keep its desired state.
+	ConditionTypeDegraded = "Degraded"
+	// ConditionTypeStorageReady indicates that the storage of the Registry is usable.
+	ConditionTypeStorageReady = "StorageReady"
+	// ConditionTypeConfigValid indicates that the configuration of the Registry could be generated.
+	ConditionTypeConfigValid = "ConfigValid"
+	// ConditionTypeStorageMigrated indicates that the images have been migrated to the storage of the Registry.
+	ConditionTypeStorageMigrated = "StorageMigrated"
+	// ConditionTypeImageSupported indicates that the image of the Registry reads the configuration generated by
+	// the operator.
+	ConditionTypeImageSupported = "ImageSupported"
+)
+
+// RegistryPhase is a human-readable summary of the Registry conditions.
+// +kubebuilder:validation:Enum=Pending;Progressing;Running;Degraded;Failed
+type RegistryPhase string
+
+const (
+	// RegistryPhasePending means that the Registry workload has not been created yet.
+	RegistryPhasePending RegistryPhase = "Pending"
+	// RegistryPhaseProgressing means that the Registry is being rolled out.
+	RegistryPhaseProgressing RegistryPhase = "Progressing"
+	// RegistryPhaseRunning means that the Registry is available.
+	RegistryPhaseRunning RegistryPhase = "Running"
+	// RegistryPhaseDegraded means that the Registry failed to reach its desired state.
+	RegistryPhaseDegraded RegistryPhase = "Degraded"
+	// RegistryPhaseFailed means that the Registry configuration is invalid.
+	RegistryPhaseFailed RegistryPhase = "Failed"
+)
+
+// EndpointType describes how an Endpoint of the Registry is exposed.
+// +kubebuilder:validation:Enum=ClusterIP;LoadBalancer;Ingress;Gateway
+type EndpointType string
+
+const (
+	// EndpointTypeClusterIP is the in-cluster address of the Registry Service.
+	EndpointTypeClusterIP EndpointType = "ClusterIP"
+	// EndpointTypeLoadBalancer is an address assigned to the Registry Service by a load balancer.
+	EndpointTypeLoadBalancer EndpointType = "LoadBalancer"
+	// EndpointTypeIngress is a host of an Ingress routing to the Registry Service.
+	EndpointTypeIngress EndpointType = "Ingress"
+	// EndpointTypeGateway is a hostname of an HTTPRoute routing to the Registry Service.
+	EndpointTypeGateway EndpointType = "Gateway"
+)
+
+// Endpoint is an address the Registry can be reached at.
+type Endpoint struct {
+	// Type describes how the endpoint is exposed.
+	Type EndpointType `json:"type"`
+
+	// Address is the host, optionally followed by a port, the Registry can be reached at.
+	Address string `json:"address"`
+
+	// TLS indicates whether the endpoint is served over TLS.
+	// +optional
+	TLS bool `json:"tls,omitempty"`
+}
+
 // RegistryStatus defines the observed state of Registry.
 type RegistryStatus struct {
-	// Ready is a boolean field that is true when the Registry is ready to be used.
+	// Conditions represent the latest available observations of the Registry state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// ObservedGeneration is the most recent generation observed for this Registry.
 	// +optional
-	Ready bool `json:"ready"`
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase is a human-readable summary of the Registry conditions.
+	// +optional
+	Phase RegistryPhase `json:"phase,omitempty"`
+
+	// DesiredReplicas is the number of pod replicas the Registry workload should run.
+	// +optional
+	DesiredReplicas int32 `json:"desiredReplicas,omitempty"`
+
+	// ReadyReplicas is the number of pod replicas of the Registry workload that are ready.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Replicas is the number of pod replicas of the Registry workload that currently exist.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Selector is the label selector of the Registry pods, in the string form used by the scale subresource.
+	// +optional
+	Selector string `json:"selector,omitempty"`
 
 	// Version of the managed Registry.
 	// +optional
 	Version string `json:"version,omitempty"`
 
+	// UpgradeHistory lists the most recent automatic upgrades of the Registry, oldest first.
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	UpgradeHistory []UpgradeRecord `json:"upgradeHistory,omitempty"`
+
+	// HeldUpgrade is the upgrade held by the upgrade hold or dry-run annotation, reported once as an Event.
+	// +optional
+	HeldUpgrade *UpgradeRecord `json:"heldUpgrade,omitempty"`
+
 	// Image indicates the container image to use for the Registry.
 	// +optional
 	Image string `json:"image,omitempty"`
+
+	// Endpoints lists the addresses the Registry can be reached at.
+	// +optional
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+
+	// PullPrefix is the preferred address to prefix image references with when pulling from the Registry.
+	// The first external endpoint is preferred over the in-cluster address.
+	// +optional
+	PullPrefix string `json:"pullPrefix,omitempty"`
+
+	// LastBackup is the most recent successful backup of the Registry.
+	// +optional
+	LastBackup *BackupRecord `json:"lastBackup,omitempty"`
+
+	// LastSnapshot is the most recent scheduled VolumeSnapshot of the Registry ready to use.
+	// +optional
+	LastSnapshot *BackupRecord `json:"lastSnapshot,omitempty"`
+
+	// ServedStorage is the storage the Registry serves the images from, which differs from its storage
+	// while the images are migrated.
+	// +optional
+	ServedStorage *Storage `json:"servedStorage,omitempty"`
+
+	// LastKnownGoodRevision is the revision of the last successful rollout of the Registry.
+	// +optional
+	LastKnownGoodRevision *RolloutRevision `json:"lastKnownGoodRevision,omitempty"`
+
+	// FailedRevision is the revision whose rollout exceeded its progress deadline and was rolled back.
+	// +optional
+	FailedRevision *RolloutRevision `json:"failedRevision,omitempty"`
+
+	// ImageUpdate records the last resolution of the update channel of the Registry.
+	// +optional
+	ImageUpdate *ImageUpdate `json:"imageUpdate,omitempty"`
 }
 
 // S3StorageSource defines the configuration for connecting to an S3-compatible
@@ -117,6 +846,21 @@ type S3StorageSource struct {
 	// override for the S3 endpoint URL.
 	// +optional
 	EndpointURL *SecretKeySelector `json:"endpointURL,omitempty"`
+
+	// RootDirectory is the prefix of the keys of the objects stored by the Registry in the bucket. It defaults to
+	// /registry/<namespace>/<name> when the s3 storage of the Registry is set, keeping apart the Registries sharing
+	// a bucket, and to /registry otherwise. The Delete deletion policy only purges the default of the Registry.
+	// It is not used by the backups, located by their prefix.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(/[a-zA-Z0-9._-]+)+$`
+	RootDirectory string `json:"rootDirectory,omitempty"`
+
+	// ForcePathStyle addresses the bucket in the path of the requests of the Registry rather than in the host
+	// name, as expected by most S3-compatible services. It defaults to false since the 3.0 releases of the
+	// distribution, the 2.x releases using the path of the requests with an endpoint URL. The Registries upgraded
+	// from 2.x with an endpoint URL have it set to true. The backups always address the bucket in the path.
+	// +optional
+	ForcePathStyle *bool `json:"forcePathStyle,omitempty"`
 }
 
 // SecretKeySelector selects a key of a Secret.
@@ -133,9 +877,14 @@ type SecretKeySelector struct {
 // +kubebuilder:conversion:hub
 // +kubebuilder:storageversion
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 // +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.version"
-// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready"
-// +kubebuilder:printcolumn:name="Image",type="string",JSONPath=".status.image"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//nolint:lll // kubebuilder directives
+// +kubebuilder:printcolumn:name="Available",type="string",JSONPath=".status.conditions[?(@.type==\"Available\")].status"
+// +kubebuilder:printcolumn:name="Pull Prefix",type="string",JSONPath=".status.pullPrefix"
+// +kubebuilder:printcolumn:name="Image",type="string",JSONPath=".status.image",priority=1
+// +kubebuilder:printcolumn:name="Endpoints",type="string",JSONPath=".status.endpoints[*].address",priority=1
 
 // Registry is the Schema for the registries API.
 type Registry struct {
@@ -0,0 +1,90 @@
+/*
+Copyright The Registry Operator Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	RegistryClassKind = "RegistryClass"
+
+	// DefaultRegistryClassAnnotation marks the RegistryClass assigned to the Registries created without a class.
+	// The most recently created class is assigned when several are marked.
+	DefaultRegistryClassAnnotation = "registry-operator.dev/is-default-class"
+)
+
+// RegistryClassSpec holds the defaults applied to the Registries using the class.
+// A field set on the Registry itself always replaces the field of the class as a whole.
+type RegistryClassSpec struct {
+	// Image indicates the container image to use for the Registry.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Resources describe the compute resource requirements.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Affinity specifies the scheduling constraints for Pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Storage defines the storage used by Registries that do not configure any storage themselves.
+	// +optional
+	Storage Storage `json:"storage,omitempty"`
+
+	// Log configures the logging of the Registry.
+	// +optional
+	Log *Log `json:"log,omitempty"`
+
+	// Monitoring configures the monitoring resources generated for the Registry.
+	// +optional
+	Monitoring *Monitoring `json:"monitoring,omitempty"`
+
+	// NetworkPolicy configures the NetworkPolicy restricting the traffic of the Registry pods.
+	// +optional
+	NetworkPolicy *NetworkPolicy `json:"networkPolicy,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+//nolint:lll // kubebuilder directives
+// +kubebuilder:printcolumn:name="Default",type="string",JSONPath=".metadata.annotations.registry-operator\\.dev/is-default-class"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RegistryClass is the Schema for the registryclasses API.
+// It holds the defaults shared by a set of Registries, analogous to a StorageClass.
+type RegistryClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RegistryClassSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RegistryClassList contains a list of RegistryClass.
+type RegistryClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RegistryClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RegistryClass{}, &RegistryClassList{})
+}
@@ -0,0 +1,117 @@
+/*
+Copyright The Registry Operator Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const RegistryRestoreKind = "RegistryRestore"
+
+// ConditionTypeRestored indicates that the backup was restored into the Registry.
+const ConditionTypeRestored = "Restored"
+
+// RegistryRestoreSpec defines the desired state of RegistryRestore.
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+type RegistryRestoreSpec struct {
+	// RegistryRef references the Registry restored, in the namespace of the RegistryRestore.
+	// It is scaled down while its storage is replaced by the content of the backup, and may be created
+	// after the RegistryRestore.
+	RegistryRef corev1.LocalObjectReference `json:"registryRef"`
+
+	// BackupName is the name of the restored backup, as recorded in the lastBackup status of the Registry
+	// which took it.
+	// +kubebuilder:validation:MinLength=1
+	BackupName string `json:"backupName"`
+
+	// Source is the bucket holding the backup. Defaults to the backup destination of the Registry.
+	// +optional
+	Source *BackupDestination `json:"source,omitempty"`
+}
+
+// RegistryRestorePhase is the progress of a RegistryRestore.
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+type RegistryRestorePhase string
+
+const (
+	// RegistryRestorePhasePending means that the Registry is not ready to be restored yet.
+	RegistryRestorePhasePending RegistryRestorePhase = "Pending"
+	// RegistryRestorePhaseRunning means that the backup is being restored.
+	RegistryRestorePhaseRunning RegistryRestorePhase = "Running"
+	// RegistryRestorePhaseSucceeded means that the backup has been restored.
+	RegistryRestorePhaseSucceeded RegistryRestorePhase = "Succeeded"
+	// RegistryRestorePhaseFailed means that the backup could not be restored.
+	RegistryRestorePhaseFailed RegistryRestorePhase = "Failed"
+)
+
+// RegistryRestoreStatus defines the observed state of RegistryRestore.
+type RegistryRestoreStatus struct {
+	// Conditions represent the latest available observations of the RegistryRestore state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// ObservedGeneration is the most recent generation observed for this RegistryRestore.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase is the progress of the restore.
+	// +optional
+	Phase RegistryRestorePhase `json:"phase,omitempty"`
+
+	// StartTime is the time the restore Job was created.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the restore succeeded or failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Registry",type="string",JSONPath=".spec.registryRef.name"
+// +kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".spec.backupName"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RegistryRestore is the Schema for the registryrestores API.
+// It replaces the storage of a Registry with the content of a backup.
+type RegistryRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RegistryRestoreSpec   `json:"spec,omitempty"`
+	Status RegistryRestoreStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RegistryRestoreList contains a list of RegistryRestore.
+type RegistryRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RegistryRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RegistryRestore{}, &RegistryRestoreList{})
+}
@@ -0,0 +1,108 @@
+/*
+Copyright The Registry Operator Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const RegistryUserKind = "RegistryUser"
+
+// RegistryUserSpec defines the desired state of RegistryUser.
+type RegistryUserSpec struct {
+	// RegistryRef references the Registry the user authenticates to, in the namespace of the RegistryUser.
+	RegistryRef corev1.LocalObjectReference `json:"registryRef"`
+
+	// Username is the name the user authenticates with. Defaults to the name of the RegistryUser object.
+	// +optional
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[^:\s]+$`
+	Username string `json:"username,omitempty"`
+
+	// ExpiresAt is the time after which the user can no longer authenticate.
+	// Its Secret is deleted once expired.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// RotationInterval is the time after which a new password is generated.
+	// Without it, the password is never rotated.
+	// +optional
+	RotationInterval *metav1.Duration `json:"rotationInterval,omitempty"`
+
+	// SecretName is the name of the kubernetes.io/dockerconfigjson Secret holding the credentials of the user.
+	// Defaults to the name of the RegistryUser object followed by -registry-credentials. An existing Secret
+	// which is not controlled by the RegistryUser is left untouched and reported by the Ready condition.
+	// +optional
+	// +kubebuilder:validation:MaxLength=253
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// RegistryUserStatus defines the observed state of RegistryUser.
+type RegistryUserStatus struct {
+	// Conditions represent the latest available observations of the RegistryUser state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// ObservedGeneration is the most recent generation observed for this RegistryUser.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// SecretName is the name of the Secret holding the credentials of the user.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// LastRotationTime is the time the current password was generated.
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Registry",type="string",JSONPath=".spec.registryRef.name"
+// +kubebuilder:printcolumn:name="Secret",type="string",JSONPath=".status.secretName"
+//nolint:lll // kubebuilder directives
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Expires",type="date",JSONPath=".spec.expiresAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RegistryUser is the Schema for the registryusers API.
+// It grants access to a Registry through a generated password. Every user may pull and push all the repositories.
+type RegistryUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RegistryUserSpec   `json:"spec,omitempty"`
+	Status RegistryUserStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RegistryUserList contains a list of RegistryUser.
+type RegistryUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RegistryUser `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RegistryUser{}, &RegistryUserList{})
+}
@@ -0,0 +1,157 @@
+/*
+Copyright The Registry Operator Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	RepositoryKind = "Repository"
+
+	// ConditionTypeReady indicates that the Repository was cleaned up as configured.
+	ConditionTypeReady = "Ready"
+)
+
+// RepositorySpec defines the desired state of Repository.
+type RepositorySpec struct {
+	// RegistryRef references the Registry hosting the repository, in the namespace of the Repository.
+	RegistryRef corev1.LocalObjectReference `json:"registryRef"`
+
+	// Name is the name of the repository in the Registry, e.g. team/app.
+	// Defaults to the name of the Repository object.
+	// +optional
+	// +kubebuilder:validation:MaxLength=255
+	//nolint:lll // kubebuilder directives
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`
+	Name string `json:"name,omitempty"`
+
+	// CredentialsSecretRef references a Secret of type kubernetes.io/dockerconfigjson or
+	// kubernetes.io/basic-auth holding the credentials for the Registry, e.g. the one of a RegistryUser.
+	// +optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+
+	// Retention configures which manifests of the repository are deleted.
+	// Without it, the repository is only observed.
+	// +optional
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+
+	// Interval is the time between two cleanups of the repository.
+	// +optional
+	// +kubebuilder:default="1h"
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// RetentionPolicy defines the manifests to keep in a repository.
+type RetentionPolicy struct {
+	// KeepLast is the number of most recently created tags to keep, the older ones are deleted.
+	// Without it, no tag is deleted.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	KeepLast *int32 `json:"keepLast,omitempty"`
+
+	// KeepMatching lists regular expressions of tags which are always kept.
+	// +optional
+	KeepMatching []string `json:"keepMatching,omitempty"`
+
+	// UntaggedOlderThan deletes the manifests which have no tag for longer than the given duration.
+	// The registry API does not list untagged manifests, so only the manifests seen losing their
+	// last tag by the operator are deleted.
+	// +optional
+	UntaggedOlderThan *metav1.Duration `json:"untaggedOlderThan,omitempty"`
+}
+
+// UntaggedManifest is a manifest which lost its last tag.
+type UntaggedManifest struct {
+	// Digest of the manifest.
+	Digest string `json:"digest"`
+
+	// Since is the time the manifest was first seen without tag.
+	Since metav1.Time `json:"since"`
+}
+
+// RepositoryStatus defines the observed state of Repository.
+type RepositoryStatus struct {
+	// Conditions represent the latest available observations of the Repository state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// ObservedGeneration is the most recent generation observed for this Repository.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// TagCount is the number of tags in the repository after the last cleanup.
+	// +optional
+	TagCount int32 `json:"tagCount"`
+
+	// DeletedManifests is the number of manifests deleted by the last cleanup.
+	// +optional
+	DeletedManifests int32 `json:"deletedManifests,omitempty"`
+
+	// LastCleanupTime is the time of the last successful cleanup.
+	// +optional
+	LastCleanupTime *metav1.Time `json:"lastCleanupTime,omitempty"`
+
+	// TaggedDigests lists the digests of the tagged manifests as of the last cleanup.
+	// It is only tracked when untagged manifests are to be deleted.
+	// +optional
+	// +listType=set
+	TaggedDigests []string `json:"taggedDigests,omitempty"`
+
+	// UntaggedManifests lists the manifests which lost their last tag and are not deleted yet.
+	// +optional
+	// +listType=map
+	// +listMapKey=digest
+	UntaggedManifests []UntaggedManifest `json:"untaggedManifests,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Registry",type="string",JSONPath=".spec.registryRef.name"
+// +kubebuilder:printcolumn:name="Tags",type="integer",JSONPath=".status.tagCount"
+//nolint:lll // kubebuilder directives
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Last Cleanup",type="date",JSONPath=".status.lastCleanupTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// Repository is the Schema for the repositories API.
+// It manages a repository of a Registry and the retention of its manifests.
+type Repository struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RepositorySpec   `json:"spec,omitempty"`
+	Status RepositoryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RepositoryList contains a list of Repository.
+type RepositoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Repository `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Repository{}, &RepositoryList{})
+}
@@ -0,0 +1,330 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConversionDataAnnotation holds the fields of the hub spec of a Registry served as v1alpha2 which cannot be
+// represented in v1alpha2, so that they survive a round-trip through it. The status is not kept, its fields
+// unknown to v1alpha2 being written by the operator through the hub.
+const ConversionDataAnnotation = "registry-operator.dev/conversion-data"
+
+var _ conversion.Convertible = &Registry{}
+
+// ConvertTo converts this Registry to the hub version (v1alpha1).
+func (src *Registry) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*registryv1alpha1.Registry)
+	if !ok {
+		return fmt.Errorf("expected a v1alpha1 Registry but got %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	// Start from the stashed hub fields, so that anything v1alpha2 does not know about is kept.
+	var stashed registryv1alpha1.RegistrySpec
+	if err := unstash(dst, &stashed); err != nil {
+		return err
+	}
+
+	dst.Spec = *stashed.DeepCopy()
+	dst.Spec.Image = src.Spec.Image
+	dst.Spec.Replicas = src.Spec.Replicas
+	dst.Spec.Resources = src.Spec.Resources.DeepCopy()
+	dst.Spec.Affinity = src.Spec.Affinity.DeepCopy()
+	dst.Spec.Storage = convertStorageTo(src.Spec.Storage, stashed.Storage)
+	dst.Spec.NetworkPolicy = nil
+	if src.Spec.Network != nil {
+		dst.Spec.NetworkPolicy = convertNetworkPolicyTo(src.Spec.Network.Policy)
+	}
+	dst.Spec.Monitoring = convertMonitoringTo(src.Spec.Monitoring)
+
+	dst.Status = convertStatusTo(src.Status)
+
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version.
+func (dst *Registry) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*registryv1alpha1.Registry)
+	if !ok {
+		return fmt.Errorf("expected a v1alpha1 Registry but got %T", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	if err := stash(dst, unrepresentable(src.Spec)); err != nil {
+		return err
+	}
+
+	dst.Spec = RegistrySpec{
+		Image:      src.Spec.Image,
+		Replicas:   src.Spec.Replicas,
+		Resources:  src.Spec.Resources.DeepCopy(),
+		Affinity:   src.Spec.Affinity.DeepCopy(),
+		Storage:    convertStorageFrom(src.Spec.Storage),
+		Monitoring: convertMonitoringFrom(src.Spec.Monitoring),
+	}
+	if src.Spec.NetworkPolicy != nil {
+		dst.Spec.Network = &Network{Policy: convertNetworkPolicyFrom(src.Spec.NetworkPolicy)}
+	}
+
+	dst.Status = convertStatusFrom(src.Status)
+
+	return nil
+}
+
+// unrepresentable returns the fields of the given hub spec which v1alpha2 cannot represent, the others being
+// left empty.
+func unrepresentable(spec registryv1alpha1.RegistrySpec) registryv1alpha1.RegistrySpec {
+	spec = *spec.DeepCopy()
+	spec.Image = ""
+	spec.Replicas = 0
+	spec.Resources = nil
+	spec.Affinity = nil
+	spec.NetworkPolicy = nil
+	spec.Monitoring = nil
+
+	storage := registryv1alpha1.Storage{}
+	if s3 := spec.Storage.S3; s3 != nil {
+		rest := registryv1alpha1.S3StorageSource{ForcePathStyle: s3.ForcePathStyle}
+		// a single static credential
+		if (s3.AccessKey == nil) != (s3.SecretKey == nil) {
+			rest.AccessKey, rest.SecretKey = s3.AccessKey, s3.SecretKey
+		}
+		if !reflect.DeepEqual(rest, registryv1alpha1.S3StorageSource{}) {
+			storage.S3 = &rest
+		}
+	}
+	spec.Storage = storage
+	return spec
+}
+
+// stash records the given hub fields in the ConversionDataAnnotation of the v1alpha2 Registry, when any is set.
+func stash(dst *Registry, spec registryv1alpha1.RegistrySpec) error {
+	if reflect.DeepEqual(spec, registryv1alpha1.RegistrySpec{}) {
+		return nil
+	}
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s annotation: %w", ConversionDataAnnotation, err)
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = map[string]string{}
+	}
+	dst.Annotations[ConversionDataAnnotation] = string(raw)
+	return nil
+}
+
+// unstash decodes the hub fields recorded in the ConversionDataAnnotation of the hub Registry, and removes it.
+func unstash(dst *registryv1alpha1.Registry, spec *registryv1alpha1.RegistrySpec) error {
+	raw, ok := dst.Annotations[ConversionDataAnnotation]
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(raw), spec); err != nil {
+		return fmt.Errorf("invalid %s annotation: %w", ConversionDataAnnotation, err)
+	}
+	delete(dst.Annotations, ConversionDataAnnotation)
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+	return nil
+}
+
+func convertStorageTo(src *Storage, stashed registryv1alpha1.Storage) registryv1alpha1.Storage {
+	dst := registryv1alpha1.Storage{}
+	if src == nil {
+		return dst
+	}
+
+	switch src.Type {
+	case StorageTypeEmptyDir:
+		dst.EmptyDir = src.EmptyDir.DeepCopy()
+	case StorageTypeEphemeral:
+		dst.Ephemeral = src.Ephemeral.DeepCopy()
+	case StorageTypeHostPath:
+		dst.HostPath = src.HostPath.DeepCopy()
+	case StorageTypePersistentVolumeClaim:
+		dst.PersistentVolumeClaim = src.PersistentVolumeClaim.DeepCopy()
+	case StorageTypePersistentVolumeClaimTemplate:
+		dst.PersistentVolumeClaimTemplate = src.PersistentVolumeClaimTemplate.DeepCopy()
+	case StorageTypeS3:
+		if src.S3 == nil {
+			break
+		}
+		dst.S3 = &registryv1alpha1.S3StorageSource{
+			BucketName:    registryv1alpha1.SecretKeySelector(src.S3.Bucket),
+			Region:        registryv1alpha1.SecretKeySelector(src.S3.Region),
+			EndpointURL:   (*registryv1alpha1.SecretKeySelector)(src.S3.Endpoint.DeepCopy()),
+			RootDirectory: src.S3.RootDirectory,
+		}
+		if s3 := stashed.S3; s3 != nil {
+			// The path style and a single static credential cannot be represented in v1alpha2, restore them.
+			dst.S3.ForcePathStyle = s3.ForcePathStyle
+			dst.S3.AccessKey = s3.AccessKey.DeepCopy()
+			dst.S3.SecretKey = s3.SecretKey.DeepCopy()
+		}
+		if auth := src.S3.Auth; auth != nil {
+			dst.S3.AccessKey = ptr.To(registryv1alpha1.SecretKeySelector(auth.AccessKey))
+			dst.S3.SecretKey = ptr.To(registryv1alpha1.SecretKeySelector(auth.SecretKey))
+		}
+	}
+
+	return dst
+}
+
+func convertStorageFrom(src registryv1alpha1.Storage) *Storage {
+	switch {
+	case src.EmptyDir != nil:
+		return &Storage{Type: StorageTypeEmptyDir, EmptyDir: src.EmptyDir.DeepCopy()}
+	case src.Ephemeral != nil:
+		return &Storage{Type: StorageTypeEphemeral, Ephemeral: src.Ephemeral.DeepCopy()}
+	case src.HostPath != nil:
+		return &Storage{Type: StorageTypeHostPath, HostPath: src.HostPath.DeepCopy()}
+	case src.PersistentVolumeClaim != nil:
+		return &Storage{
+			Type:                  StorageTypePersistentVolumeClaim,
+			PersistentVolumeClaim: src.PersistentVolumeClaim.DeepCopy(),
+		}
+	case src.PersistentVolumeClaimTemplate != nil:
+		return &Storage{
+			Type:                          StorageTypePersistentVolumeClaimTemplate,
+			PersistentVolumeClaimTemplate: src.PersistentVolumeClaimTemplate.DeepCopy(),
+		}
+	case src.S3 != nil:
+		s3 := &S3Storage{
+			Bucket:        SecretKeySelector(src.S3.BucketName),
+			Region:        SecretKeySelector(src.S3.Region),
+			Endpoint:      (*SecretKeySelector)(src.S3.EndpointURL.DeepCopy()),
+			RootDirectory: src.S3.RootDirectory,
+		}
+		if src.S3.AccessKey != nil && src.S3.SecretKey != nil {
+			s3.Auth = &S3Auth{
+				AccessKey: SecretKeySelector(*src.S3.AccessKey),
+				SecretKey: SecretKeySelector(*src.S3.SecretKey),
+			}
+		}
+		return &Storage{Type: StorageTypeS3, S3: s3}
+	default:
+		return nil
+	}
+}
+
+func convertNetworkPolicyTo(src *NetworkPolicy) *registryv1alpha1.NetworkPolicy {
+	if src == nil {
+		return nil
+	}
+	return (*registryv1alpha1.NetworkPolicy)(src.DeepCopy())
+}
+
+func convertNetworkPolicyFrom(src *registryv1alpha1.NetworkPolicy) *NetworkPolicy {
+	if src == nil {
+		return nil
+	}
+	return (*NetworkPolicy)(src.DeepCopy())
+}
+
+func convertMonitoringTo(src *Monitoring) *registryv1alpha1.Monitoring {
+	if src == nil {
+		return nil
+	}
+	dst := &registryv1alpha1.Monitoring{}
+	if a := src.Alerts; a != nil {
+		dst.Alerts = &registryv1alpha1.Alerts{
+			Enabled:                   a.Enabled,
+			Labels:                    a.DeepCopy().Labels,
+			PodsNotReady:              (*registryv1alpha1.AlertRule)(a.PodsNotReady.DeepCopy()),
+			StorageUnhealthy:          (*registryv1alpha1.AlertRule)(a.StorageUnhealthy.DeepCopy()),
+			HighErrorRate:             (*registryv1alpha1.AlertRule)(a.HighErrorRate.DeepCopy()),
+			PersistentVolumeFillingUp: (*registryv1alpha1.AlertRule)(a.PersistentVolumeFillingUp.DeepCopy()),
+		}
+	}
+	return dst
+}
+
+func convertMonitoringFrom(src *registryv1alpha1.Monitoring) *Monitoring {
+	if src == nil {
+		return nil
+	}
+	dst := &Monitoring{}
+	if a := src.Alerts; a != nil {
+		dst.Alerts = &Alerts{
+			Enabled:                   a.Enabled,
+			Labels:                    a.DeepCopy().Labels,
+			PodsNotReady:              (*AlertRule)(a.PodsNotReady.DeepCopy()),
+			StorageUnhealthy:          (*AlertRule)(a.StorageUnhealthy.DeepCopy()),
+			HighErrorRate:             (*AlertRule)(a.HighErrorRate.DeepCopy()),
+			PersistentVolumeFillingUp: (*AlertRule)(a.PersistentVolumeFillingUp.DeepCopy()),
+		}
+	}
+	return dst
+}
+
+func convertStatusTo(src RegistryStatus) registryv1alpha1.RegistryStatus {
+	src = *src.DeepCopy()
+	dst := registryv1alpha1.RegistryStatus{
+		Conditions:         src.Conditions,
+		ObservedGeneration: src.ObservedGeneration,
+		Phase:              registryv1alpha1.RegistryPhase(src.Phase),
+		DesiredReplicas:    src.DesiredReplicas,
+		ReadyReplicas:      src.ReadyReplicas,
+		Replicas:           src.Replicas,
+		Selector:           src.Selector,
+		Version:            src.Version,
+		Image:              src.Image,
+		PullPrefix:         src.PullPrefix,
+	}
+	for _, e := range src.Endpoints {
+		dst.Endpoints = append(dst.Endpoints, registryv1alpha1.Endpoint{
+			Type:    registryv1alpha1.EndpointType(e.Type),
+			Address: e.Address,
+			TLS:     e.TLS,
+		})
+	}
+	return dst
+}
+
+func convertStatusFrom(src registryv1alpha1.RegistryStatus) RegistryStatus {
+	src = *src.DeepCopy()
+	dst := RegistryStatus{
+		Conditions:         src.Conditions,
+		ObservedGeneration: src.ObservedGeneration,
+		Phase:              RegistryPhase(src.Phase),
+		DesiredReplicas:    src.DesiredReplicas,
+		ReadyReplicas:      src.ReadyReplicas,
+		Replicas:           src.Replicas,
+		Selector:           src.Selector,
+		Version:            src.Version,
+		Image:              src.Image,
+		PullPrefix:         src.PullPrefix,
+	}
+	for _, e := range src.Endpoints {
+		dst.Endpoints = append(dst.Endpoints, Endpoint{
+			Type:    EndpointType(e.Type),
+			Address: e.Address,
+			TLS:     e.TLS,
+		})
+	}
+	return dst
+}
@@ -0,0 +1,238 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestRegistryConversion(t *testing.T) {
+	meta := metav1.ObjectMeta{
+		Name:        "my-instance",
+		Namespace:   "my-namespace",
+		Annotations: map[string]string{"foo": "bar"},
+	}
+	ref := func(key string) registryv1alpha1.SecretKeySelector {
+		return registryv1alpha1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "s3"},
+			Key:                  key,
+		}
+	}
+
+	t.Run("should round-trip hub through v1alpha2", func(t *testing.T) {
+		hubs := map[string]*registryv1alpha1.Registry{
+			"emptyDir": {
+				ObjectMeta: meta,
+				Spec: registryv1alpha1.RegistrySpec{
+					Image:    "registry:3.0.0",
+					Replicas: 2,
+					Storage:  registryv1alpha1.Storage{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+					NetworkPolicy: &registryv1alpha1.NetworkPolicy{
+						Enabled: true,
+						From: []networkingv1.NetworkPolicyPeer{
+							{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8"}},
+						},
+					},
+					Monitoring: &registryv1alpha1.Monitoring{
+						Alerts: &registryv1alpha1.Alerts{
+							Enabled:      true,
+							PodsNotReady: &registryv1alpha1.AlertRule{Threshold: ptr.To[int32](1)},
+						},
+					},
+				},
+				Status: registryv1alpha1.RegistryStatus{
+					Phase: registryv1alpha1.RegistryPhaseRunning,
+					Endpoints: []registryv1alpha1.Endpoint{
+						{Type: registryv1alpha1.EndpointTypeClusterIP, Address: "my-instance-registry.my-namespace.svc:5000"},
+					},
+				},
+			},
+			"fields unknown to v1alpha2": {
+				ObjectMeta: meta,
+				Spec: registryv1alpha1.RegistrySpec{
+					RegistryClassName: "default",
+					Image:             "registry:3.0.0",
+					DeletionPolicy:    registryv1alpha1.DeletionPolicyDelete,
+					Storage: registryv1alpha1.Storage{
+						S3: &registryv1alpha1.S3StorageSource{
+							BucketName:     ref("bucket"),
+							Region:         ref("region"),
+							EndpointURL:    ptr.To(ref("endpoint")),
+							ForcePathStyle: ptr.To(true),
+						},
+					},
+				},
+			},
+			"s3": {
+				ObjectMeta: meta,
+				Spec: registryv1alpha1.RegistrySpec{
+					Storage: registryv1alpha1.Storage{
+						S3: &registryv1alpha1.S3StorageSource{
+							BucketName:  ref("bucket"),
+							Region:      ref("region"),
+							EndpointURL: ptr.To(ref("endpoint")),
+							AccessKey:   ptr.To(ref("accessKey")),
+							SecretKey:   ptr.To(ref("secretKey")),
+						},
+					},
+				},
+			},
+			"s3 with a single credential": {
+				ObjectMeta: meta,
+				Spec: registryv1alpha1.RegistrySpec{
+					Storage: registryv1alpha1.Storage{
+						S3: &registryv1alpha1.S3StorageSource{
+							BucketName: ref("bucket"),
+							Region:     ref("region"),
+							AccessKey:  ptr.To(ref("accessKey")),
+						},
+					},
+				},
+			},
+			"no storage": {
+				ObjectMeta: meta,
+			},
+		}
+
+		for name, hub := range hubs {
+			t.Run(name, func(t *testing.T) {
+				spoke := &Registry{}
+				require.NoError(t, spoke.ConvertFrom(hub))
+
+				actual := &registryv1alpha1.Registry{}
+				require.NoError(t, spoke.ConvertTo(actual))
+				assert.Equal(t, hub, actual)
+			})
+		}
+	})
+
+	t.Run("should convert discriminated storage", func(t *testing.T) {
+		spoke := &Registry{
+			ObjectMeta: meta,
+			Spec: RegistrySpec{
+				Storage: &Storage{
+					Type: StorageTypeS3,
+					S3: &S3Storage{
+						Bucket: SecretKeySelector(ref("bucket")),
+						Region: SecretKeySelector(ref("region")),
+						Auth: &S3Auth{
+							AccessKey: SecretKeySelector(ref("accessKey")),
+							SecretKey: SecretKeySelector(ref("secretKey")),
+						},
+					},
+				},
+				Network: &Network{Policy: &NetworkPolicy{Enabled: true}},
+			},
+		}
+
+		hub := &registryv1alpha1.Registry{}
+		require.NoError(t, spoke.ConvertTo(hub))
+		assert.Equal(t, ref("bucket"), hub.Spec.Storage.S3.BucketName)
+		assert.Equal(t, ref("accessKey"), *hub.Spec.Storage.S3.AccessKey)
+		assert.Equal(t, ref("secretKey"), *hub.Spec.Storage.S3.SecretKey)
+		assert.True(t, hub.Spec.NetworkPolicy.Enabled)
+
+		actual := &Registry{}
+		require.NoError(t, actual.ConvertFrom(hub))
+		assert.Equal(t, spoke, actual)
+	})
+
+	t.Run("should stash only the spec fields v1alpha2 cannot represent", func(t *testing.T) {
+		hub := &registryv1alpha1.Registry{
+			ObjectMeta: meta,
+			Spec: registryv1alpha1.RegistrySpec{
+				RegistryClassName: "default",
+				Image:             "registry:3.0.0",
+				Replicas:          2,
+				Storage: registryv1alpha1.Storage{
+					S3: &registryv1alpha1.S3StorageSource{
+						BucketName: ref("bucket"),
+						Region:     ref("region"),
+						AccessKey:  ptr.To(ref("accessKey")),
+						SecretKey:  ptr.To(ref("secretKey")),
+					},
+				},
+			},
+			Status: registryv1alpha1.RegistryStatus{
+				UpgradeHistory: []registryv1alpha1.UpgradeRecord{{From: "2.8.3", To: "3.0.0"}},
+			},
+		}
+
+		spoke := &Registry{}
+		require.NoError(t, spoke.ConvertFrom(hub))
+		require.Contains(t, spoke.Annotations, ConversionDataAnnotation)
+		stashed := registryv1alpha1.RegistrySpec{}
+		require.NoError(t, json.Unmarshal([]byte(spoke.Annotations[ConversionDataAnnotation]), &stashed))
+		assert.Equal(t, registryv1alpha1.RegistrySpec{RegistryClassName: "default"}, stashed)
+		assert.Len(t, spoke.Annotations, 2)
+
+		hub.Spec.RegistryClassName = ""
+		spoke = &Registry{}
+		require.NoError(t, spoke.ConvertFrom(hub))
+		assert.Equal(t, meta.Annotations, spoke.Annotations)
+	})
+
+	t.Run("should keep fields unknown to v1alpha2 when edited through it", func(t *testing.T) {
+		hub := &registryv1alpha1.Registry{
+			ObjectMeta: meta,
+			Spec: registryv1alpha1.RegistrySpec{
+				RegistryClassName: "default",
+				Image:             "registry:3.0.0",
+				Replicas:          2,
+				Storage: registryv1alpha1.Storage{
+					S3: &registryv1alpha1.S3StorageSource{
+						BucketName: ref("bucket"),
+						Region:     ref("region"),
+						SecretKey:  ptr.To(ref("secretKey")),
+					},
+				},
+				NetworkPolicy: &registryv1alpha1.NetworkPolicy{Enabled: true},
+			},
+		}
+
+		spoke := &Registry{}
+		require.NoError(t, spoke.ConvertFrom(hub))
+		spoke.Spec.Image = "registry:3.0.1"
+		spoke.Spec.Replicas = 3
+		spoke.Spec.Network = nil
+		spoke.Status.Phase = RegistryPhase(registryv1alpha1.RegistryPhaseDegraded)
+
+		actual := &registryv1alpha1.Registry{}
+		require.NoError(t, spoke.ConvertTo(actual))
+		assert.Equal(t, "registry:3.0.1", actual.Spec.Image)
+		assert.Equal(t, int32(3), actual.Spec.Replicas)
+		assert.Nil(t, actual.Spec.NetworkPolicy)
+		assert.Equal(t, "default", actual.Spec.RegistryClassName)
+		assert.Equal(t, ref("secretKey"), *actual.Spec.Storage.S3.SecretKey)
+		assert.Equal(t, registryv1alpha1.RegistryPhaseDegraded, actual.Status.Phase)
+		assert.NotContains(t, actual.Annotations, ConversionDataAnnotation)
+
+		spoke.Spec.Storage = &Storage{Type: StorageTypeEmptyDir, EmptyDir: &corev1.EmptyDirVolumeSource{}}
+		actual = &registryv1alpha1.Registry{}
+		require.NoError(t, spoke.ConvertTo(actual))
+		assert.Equal(t, registryv1alpha1.Storage{EmptyDir: &corev1.EmptyDirVolumeSource{}}, actual.Spec.Storage)
+	})
+}
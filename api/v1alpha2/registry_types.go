@@ -0,0 +1,365 @@
+/*
+Copyright The Registry Operator Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RegistrySpec defines the desired state of Registry.
+type RegistrySpec struct {
+	// Image indicates the container image to use for the Registry.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Replicas indicates the number of the pod replicas that will be created.
+	// +optional
+	// +default=1
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Resources describe the compute resource requirements.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Affinity specifies the scheduling constraints for Pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Storage defines the storage backend of the Registry.
+	// If not set, a size limited emptyDir is used.
+	// +optional
+	Storage *Storage `json:"storage,omitempty"`
+
+	// Network configures how the Registry pods are exposed on the network.
+	// +optional
+	Network *Network `json:"network,omitempty"`
+
+	// Monitoring configures the monitoring resources generated for the Registry.
+	// +optional
+	Monitoring *Monitoring `json:"monitoring,omitempty"`
+}
+
+// StorageType is the discriminator of the Storage union.
+// +kubebuilder:validation:Enum=EmptyDir;Ephemeral;HostPath;PersistentVolumeClaim;PersistentVolumeClaimTemplate;S3
+type StorageType string
+
+const (
+	StorageTypeEmptyDir                      StorageType = "EmptyDir"
+	StorageTypeEphemeral                     StorageType = "Ephemeral"
+	StorageTypeHostPath                      StorageType = "HostPath"
+	StorageTypePersistentVolumeClaim         StorageType = "PersistentVolumeClaim"
+	StorageTypePersistentVolumeClaimTemplate StorageType = "PersistentVolumeClaimTemplate"
+	StorageTypeS3                            StorageType = "S3"
+)
+
+// Storage is a discriminated union of the storage backends a registry can use for persistence.
+// Exactly the member matching the type must be set.
+//
+// +union
+//nolint:lll // kubebuilder directives
+// +kubebuilder:validation:XValidation:rule="self.type == 'EmptyDir' ? has(self.emptyDir) : !has(self.emptyDir)",message="emptyDir must be set if and only if type is EmptyDir"
+//nolint:lll // kubebuilder directives
+// +kubebuilder:validation:XValidation:rule="self.type == 'Ephemeral' ? has(self.ephemeral) : !has(self.ephemeral)",message="ephemeral must be set if and only if type is Ephemeral"
+//nolint:lll // kubebuilder directives
+// +kubebuilder:validation:XValidation:rule="self.type == 'HostPath' ? has(self.hostPath) : !has(self.hostPath)",message="hostPath must be set if and only if type is HostPath"
+//nolint:lll // kubebuilder directives
+// +kubebuilder:validation:XValidation:rule="self.type == 'PersistentVolumeClaim' ? has(self.persistentVolumeClaim) : !has(self.persistentVolumeClaim)",message="persistentVolumeClaim must be set if and only if type is PersistentVolumeClaim"
+//nolint:lll // kubebuilder directives
+// +kubebuilder:validation:XValidation:rule="self.type == 'PersistentVolumeClaimTemplate' ? has(self.persistentVolumeClaimTemplate) : !has(self.persistentVolumeClaimTemplate)",message="persistentVolumeClaimTemplate must be set if and only if type is PersistentVolumeClaimTemplate"
+//nolint:lll // kubebuilder directives
+// +kubebuilder:validation:XValidation:rule="self.type == 'S3' ? has(self.s3) : !has(self.s3)",message="s3 must be set if and only if type is S3"
+type Storage struct {
+	// Type selects the storage backend.
+	// +unionDiscriminator
+	Type StorageType `json:"type"`
+
+	// EmptyDir represents a temporary directory that shares a pod's lifetime.
+	// +optional
+	EmptyDir *corev1.EmptyDirVolumeSource `json:"emptyDir,omitempty"`
+
+	// Ephemeral represents a volume that is handled by a cluster storage driver.
+	// The volume's lifecycle is tied to the pod that defines it - it will be created before the pod starts,
+	// and deleted when the pod is removed.
+	// +optional
+	Ephemeral *corev1.EphemeralVolumeSource `json:"ephemeral,omitempty"`
+
+	// HostPath represents a directory on the host.
+	// +optional
+	HostPath *corev1.HostPathVolumeSource `json:"hostPath,omitempty"`
+
+	// PersistentVolumeClaim represents a reference to a PersistentVolumeClaim in the same namespace.
+	// +optional
+	PersistentVolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
+
+	// PersistentVolumeClaimTemplate allows creating PVCs dynamically.
+	// This defines a PVC template that will be instantiated for the pod.
+	// +optional
+	PersistentVolumeClaimTemplate *corev1.PersistentVolumeClaimSpec `json:"persistentVolumeClaimTemplate,omitempty"`
+
+	// S3 defines an S3-compatible storage backend for persisting registry data.
+	// +optional
+	S3 *S3Storage `json:"s3,omitempty"`
+}
+
+// S3Storage defines the configuration for connecting to an S3-compatible storage backend.
+type S3Storage struct {
+	// Bucket is a reference to the secret key containing the bucket name.
+	Bucket SecretKeySelector `json:"bucket"`
+
+	// Region is a reference to the secret key containing the S3 region name.
+	Region SecretKeySelector `json:"region"`
+
+	// Endpoint is a reference to the secret key containing an override for the S3 endpoint URL.
+	// +optional
+	Endpoint *SecretKeySelector `json:"endpoint,omitempty"`
+
+	// Auth configures static credentials for the S3 backend.
+	// If not set, the credentials are taken from the environment of the Registry, e.g. an IAM role.
+	// +optional
+	Auth *S3Auth `json:"auth,omitempty"`
+
+	// RootDirectory is the prefix of the keys of the objects stored by the Registry in the bucket.
+	// Defaults to /registry/<namespace>/<name> when the s3 storage is set.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(/[a-zA-Z0-9._-]+)+$`
+	RootDirectory string `json:"rootDirectory,omitempty"`
+}
+
+// S3Auth holds the static credentials of an S3-compatible storage backend.
+type S3Auth struct {
+	// AccessKey is a reference to the secret key containing the S3 access key.
+	AccessKey SecretKeySelector `json:"accessKey"`
+
+	// SecretKey is a reference to the secret key containing the S3 secret key.
+	SecretKey SecretKeySelector `json:"secretKey"`
+}
+
+// SecretKeySelector selects a key of a Secret.
+type SecretKeySelector struct {
+	// The name of the secret in the object's namespace to select from.
+	corev1.LocalObjectReference `json:",inline"`
+
+	// The key of the secret to select from. Must be a valid secret key.
+	Key string `json:"key"`
+}
+
+// Network configures how the Registry pods are exposed on the network.
+type Network struct {
+	// Policy configures the NetworkPolicy restricting the traffic of the Registry pods.
+	// +optional
+	Policy *NetworkPolicy `json:"policy,omitempty"`
+}
+
+// NetworkPolicy configures the NetworkPolicy generated for the Registry pods.
+// Once enabled, only the traffic described here (and DNS lookups) is allowed.
+type NetworkPolicy struct {
+	// Enabled indicates whether the NetworkPolicy should be created for the Registry.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// From lists the namespaces, pods and CIDRs allowed to reach the registry API.
+	// If empty, the registry API is reachable from any source. The operator Pods are always allowed.
+	// +optional
+	From []networkingv1.NetworkPolicyPeer `json:"from,omitempty"`
+
+	// MonitoringNamespaceSelector selects the namespaces allowed to scrape the metrics port.
+	// If not set, the metrics port is not reachable.
+	// +optional
+	MonitoringNamespaceSelector *metav1.LabelSelector `json:"monitoringNamespaceSelector,omitempty"`
+
+	// Storage lists the peers serving the S3 storage endpoint. If empty, the peer is derived from
+	// the endpoint URL when it is an IP address, otherwise egress to the endpoint port is allowed
+	// to any destination.
+	// +optional
+	Storage []networkingv1.NetworkPolicyPeer `json:"storage,omitempty"`
+
+	// Proxy lists the peers serving the remote registry of the proxy, and its token service. If empty, the peer
+	// is derived from the remote URL when it is an IP address, otherwise egress to the remote port is allowed
+	// to any destination.
+	// +optional
+	Proxy []networkingv1.NetworkPolicyPeer `json:"proxy,omitempty"`
+
+	// Notifications lists the egress rules allowing the Registry to reach its notification targets.
+	// +optional
+	Notifications []networkingv1.NetworkPolicyEgressRule `json:"notifications,omitempty"`
+}
+
+// Monitoring configures the monitoring resources generated for the Registry.
+type Monitoring struct {
+	// Alerts configures the PrometheusRule with the alerts for the Registry.
+	// It requires the Prometheus Operator CRDs to be installed in the cluster.
+	// +optional
+	Alerts *Alerts `json:"alerts,omitempty"`
+}
+
+// Alerts configures the bundle of alerting rules generated for the Registry.
+// Every alert is enabled by default once the bundle itself is enabled.
+type Alerts struct {
+	// Enabled indicates whether the PrometheusRule should be created for the Registry.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Labels are additional labels attached to every alert, e.g. to route them in Alertmanager.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// PodsNotReady fires when more Registry replicas than the threshold are not available.
+	// +optional
+	PodsNotReady *AlertRule `json:"podsNotReady,omitempty"`
+
+	// StorageUnhealthy fires when the storage driver health check fails.
+	// +optional
+	StorageUnhealthy *AlertRule `json:"storageUnhealthy,omitempty"`
+
+	// HighErrorRate fires when the percentage of 5xx responses exceeds the threshold.
+	// +optional
+	HighErrorRate *AlertRule `json:"highErrorRate,omitempty"`
+
+	// PersistentVolumeFillingUp fires when the used space of the storage PersistentVolumeClaim
+	// exceeds the threshold percentage.
+	// +optional
+	PersistentVolumeFillingUp *AlertRule `json:"persistentVolumeFillingUp,omitempty"`
+}
+
+// AlertRule overrides the defaults of a single generated alert.
+type AlertRule struct {
+	// Enabled indicates whether the alert is generated.
+	// +optional
+	// +default=true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Threshold overrides the value the alert expression is compared against.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Threshold *int32 `json:"threshold,omitempty"`
+
+	// For overrides how long the condition must hold before the alert fires.
+	// +optional
+	For *metav1.Duration `json:"for,omitempty"`
+
+	// Severity overrides the severity label of the alert.
+	// +optional
+	Severity string `json:"severity,omitempty"`
+}
+
+// RegistryPhase is a human-readable summary of the Registry conditions.
+// +kubebuilder:validation:Enum=Pending;Progressing;Running;Degraded;Failed
+type RegistryPhase string
+
+// EndpointType describes how an Endpoint of the Registry is exposed.
+// +kubebuilder:validation:Enum=ClusterIP;LoadBalancer;Ingress;Gateway
+type EndpointType string
+
+// Endpoint is an address the Registry can be reached at.
+type Endpoint struct {
+	// Type describes how the endpoint is exposed.
+	Type EndpointType `json:"type"`
+
+	// Address is the host, optionally followed by a port, the Registry can be reached at.
+	Address string `json:"address"`
+
+	// TLS indicates whether the endpoint is served over TLS.
+	// +optional
+	TLS bool `json:"tls,omitempty"`
+}
+
+// RegistryStatus defines the observed state of Registry.
+type RegistryStatus struct {
+	// Conditions represent the latest available observations of the Registry state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// ObservedGeneration is the most recent generation observed for this Registry.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase is a human-readable summary of the Registry conditions.
+	// +optional
+	Phase RegistryPhase `json:"phase,omitempty"`
+
+	// DesiredReplicas is the number of pod replicas the Registry workload should run.
+	// +optional
+	DesiredReplicas int32 `json:"desiredReplicas,omitempty"`
+
+	// ReadyReplicas is the number of pod replicas of the Registry workload that are ready.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Replicas is the number of pod replicas of the Registry workload that currently exist.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Selector is the label selector of the Registry pods, in the string form used by the scale subresource.
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
+	// Version of the managed Registry.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Image indicates the container image to use for the Registry.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Endpoints lists the addresses the Registry can be reached at.
+	// +optional
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+
+	// PullPrefix is the preferred address to prefix image references with when pulling from the Registry.
+	// +optional
+	PullPrefix string `json:"pullPrefix,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.version"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Storage",type="string",JSONPath=".spec.storage.type"
+//nolint:lll // kubebuilder directives
+// +kubebuilder:printcolumn:name="Available",type="string",JSONPath=".status.conditions[?(@.type==\"Available\")].status"
+// +kubebuilder:printcolumn:name="Pull Prefix",type="string",JSONPath=".status.pullPrefix"
+// +kubebuilder:printcolumn:name="Image",type="string",JSONPath=".status.image",priority=1
+// +kubebuilder:printcolumn:name="Endpoints",type="string",JSONPath=".status.endpoints[*].address",priority=1
+
+// Registry is the Schema for the registries API.
+type Registry struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RegistrySpec   `json:"spec,omitempty"`
+	Status RegistryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RegistryList contains a list of Registry.
+type RegistryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Registry `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Registry{}, &RegistryList{})
+}
@@ -17,12 +17,22 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"flag"
 	"os"
 
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
 	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	registryv1alpha2 "github.com/registry-operator/registry-operator/api/v1alpha2"
+	"github.com/registry-operator/registry-operator/internal/backup"
 	"github.com/registry-operator/registry-operator/internal/controller"
+	"github.com/registry-operator/registry-operator/internal/migration"
+	registryupgrade "github.com/registry-operator/registry-operator/internal/upgrade/registry"
+	"github.com/registry-operator/registry-operator/internal/version"
+	webhookcorev1 "github.com/registry-operator/registry-operator/internal/webhook/core/v1"
 	webhookv1alpha1 "github.com/registry-operator/registry-operator/internal/webhook/v1alpha1"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -31,6 +41,7 @@ import (
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -46,11 +57,35 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
+	utilruntime.Must(monitoringv1.AddToScheme(scheme))
+
 	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha2.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
+	// The backup, restore and migration Jobs of the Registries run the operator image with a command.
+	if len(os.Args) > 1 && backup.IsCommand(os.Args[1]) {
+		ctrl.SetLogger(klog.NewKlogr())
+		if err := backup.Run(ctrl.SetupSignalHandler(), os.Args[1]); err != nil {
+			setupLog.Error(err, "command failed", "command", os.Args[1])
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && migration.IsCommand(os.Args[1]) {
+		ctrl.SetLogger(klog.NewKlogr())
+		if err := migration.Run(ctrl.SetupSignalHandler(), os.Args[1]); err != nil {
+			setupLog.Error(err, "command failed", "command", os.Args[1])
+			if errors.Is(err, migration.ErrVerificationFailed) {
+				os.Exit(migration.ExitCodeVerificationFailed)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
@@ -139,23 +174,105 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The image of the operator runs the backup and restore Jobs.
+	operatorImage := os.Getenv("OPERATOR_IMAGE")
+	if operatorImage == "" {
+		setupLog.Info("OPERATOR_IMAGE is not set, the Registries cannot be backed up nor restored")
+	}
+
+	// The Pods of the operator namespace are allowed through the NetworkPolicies of the Registries.
+	operatorNamespace := os.Getenv("OPERATOR_NAMESPACE")
+	if operatorNamespace == "" {
+		setupLog.Info("OPERATOR_NAMESPACE is not set, the NetworkPolicies of the Registries block the operator")
+	}
+
 	if err = (&controller.RegistryReconciler{
+		Client:            mgr.GetClient(),
+		Recorder:          mgr.GetEventRecorderFor("RegistryReconciler"),
+		Scheme:            mgr.GetScheme(),
+		OperatorImage:     operatorImage,
+		OperatorNamespace: operatorNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Registry")
+		os.Exit(1)
+	}
+
+	if err = (&controller.RegistryRestoreReconciler{
+		Client:        mgr.GetClient(),
+		Recorder:      mgr.GetEventRecorderFor("RegistryRestoreReconciler"),
+		Scheme:        mgr.GetScheme(),
+		OperatorImage: operatorImage,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RegistryRestore")
+		os.Exit(1)
+	}
+
+	if err = (&controller.RepositoryReconciler{
 		Client:   mgr.GetClient(),
-		Recorder: mgr.GetEventRecorderFor("RegistryReconciler"),
+		Recorder: mgr.GetEventRecorderFor("RepositoryReconciler"),
 		Scheme:   mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Registry")
+		setupLog.Error(err, "unable to create controller", "controller", "Repository")
 		os.Exit(1)
 	}
 
+	if err = (&controller.ImageSyncReconciler{
+		Client:   mgr.GetClient(),
+		Recorder: mgr.GetEventRecorderFor("ImageSyncReconciler"),
+		Scheme:   mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ImageSync")
+		os.Exit(1)
+	}
+
+	if err = (&controller.RegistryUserReconciler{
+		Client:   mgr.GetClient(),
+		Recorder: mgr.GetEventRecorderFor("RegistryUserReconciler"),
+		Scheme:   mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RegistryUser")
+		os.Exit(1)
+	}
+
+	// The webhook server also serves the conversion between the Registry versions.
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
 		if err = webhookv1alpha1.SetupRegistryWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Registry")
 			os.Exit(1)
 		}
+		if err = webhookcorev1.SetupPodWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Pod")
+			os.Exit(1)
+		}
 	}
 	// +kubebuilder:scaffold:builder
 
+	// Bring the existing instances up to date once this replica becomes the leader.
+	if err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		recorder := mgr.GetEventRecorderFor("registry-upgrade")
+
+		migration := registryupgrade.GroupMigration{
+			Client:   mgr.GetClient(),
+			Recorder: recorder,
+		}
+		if err := migration.ManagedInstances(ctx); err != nil {
+			setupLog.Error(err, "failed to migrate legacy instances")
+		}
+
+		up := registryupgrade.VersionUpgrade{
+			Client:   mgr.GetClient(),
+			Recorder: recorder,
+			Version:  version.Get(),
+		}
+		if err := up.ManagedInstances(ctx); err != nil {
+			setupLog.Error(err, "failed to upgrade managed instances")
+		}
+		return nil
+	})); err != nil {
+		setupLog.Error(err, "unable to add upgrade routine")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -0,0 +1,175 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// lostAndFound is created at the root of the ext filesystems of the volumes, and belongs to them.
+const lostAndFound = "lost+found"
+
+// restoreStaging is the directory under the storage root the backup is extracted into before replacing its
+// content, on the same filesystem so that the files are moved rather than copied.
+const restoreStaging = ".restore"
+
+// writeArchive writes the gzipped tarball of the directories and regular files under the given root,
+// with paths relative to it.
+func writeArchive(w io.Writer, root string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := filepath.WalkDir(root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, name)
+		if err != nil || rel == "." {
+			return err
+		}
+		if rel == lostAndFound || rel == restoreStaging {
+			return filepath.SkipDir
+		}
+		if !entry.IsDir() && !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint:errcheck // read-only file
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// extractArchive extracts the directories and regular files of the given gzipped tarball under the given root,
+// which the paths of the archive cannot escape.
+func extractArchive(r io.Reader, root string) error {
+	dir, err := os.OpenRoot(root)
+	if err != nil {
+		return err
+	}
+	defer dir.Close() //nolint:errcheck // directory
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		name := path.Clean(header.Name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := dir.MkdirAll(name, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := dir.MkdirAll(path.Dir(name), 0o755); err != nil {
+				return err
+			}
+			if err := extractFile(dir, name, tr, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+			if err := dir.Chtimes(name, header.ModTime, header.ModTime); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func extractFile(dir *os.Root, name string, r io.Reader, perm fs.FileMode) error {
+	f, err := dir.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// replaceDir replaces the content of the given directory, a volume mount point which cannot be removed itself,
+// with the content of its staging directory.
+func replaceDir(root string) error {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == lostAndFound || entry.Name() == restoreStaging {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(root, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	staging := filepath.Join(root, restoreStaging)
+	entries, err = os.ReadDir(staging)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.Rename(filepath.Join(staging, entry.Name()), filepath.Join(root, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return os.Remove(staging)
+}
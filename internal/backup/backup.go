@@ -0,0 +1,271 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backup implements the backup and restore commands of the operator image, run by the Jobs archiving
+// the storage of the Registries to an S3-compatible bucket and restoring it.
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/registry-operator/registry-operator/internal/s3client"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Commands of the operator image.
+const (
+	CommandBackup  = "backup"
+	CommandRestore = "restore"
+)
+
+// Environment variables configuring the commands.
+const (
+	EnvBucket       = "BACKUP_S3_BUCKET"
+	EnvRegion       = "BACKUP_S3_REGION"
+	EnvEndpoint     = "BACKUP_S3_ENDPOINT"
+	EnvAccessKey    = "BACKUP_S3_ACCESS_KEY"
+	EnvSecretKey    = "BACKUP_S3_SECRET_KEY"
+	EnvPrefix       = "BACKUP_PREFIX"
+	EnvName         = "BACKUP_NAME"
+	EnvSeries       = "BACKUP_SERIES"
+	EnvRetention    = "BACKUP_RETENTION"
+	EnvStorageRoot  = "BACKUP_STORAGE_ROOT"
+	EnvReadOnlyFile = "BACKUP_READ_ONLY_FILE"
+)
+
+// ReadOnlyKey is the key of the state ConfigMap set to "true" once the Registry serves in read-only mode.
+const ReadOnlyKey = "readOnly"
+
+const (
+	readOnlyTimeout  = 10 * time.Minute
+	readOnlyInterval = 5 * time.Second
+)
+
+var errReadOnlyTimeout = errors.New("timed out waiting for the Registry to be read-only")
+
+// Options configures a backup or a restore.
+type Options struct {
+	// S3 locates the bucket holding the backups.
+	S3 s3client.Config
+	// Prefix is the prefix of the keys of the backups in the bucket.
+	Prefix string
+	// Name is the name of the backup taken or restored.
+	Name string
+	// Series is the prefix of the names of the backups the retention applies to, the ones of the CronJob.
+	Series string
+	// Retention is the number of backups of the series kept.
+	Retention int
+	// StorageRoot is the storage root directory of the Registry.
+	StorageRoot string
+	// ReadOnlyFile is the file holding "true" once the Registry is read-only, waited for before a backup.
+	ReadOnlyFile string
+}
+
+// Key returns the key of the given backup in the bucket.
+func Key(prefix, name string) string {
+	return path.Join(prefix, name+".tar.gz")
+}
+
+// IsCommand reports whether the given argument is a command of this package.
+func IsCommand(arg string) bool {
+	return arg == CommandBackup || arg == CommandRestore
+}
+
+// Run runs the given command configured by the environment.
+func Run(ctx context.Context, command string) error {
+	opts, err := OptionsFromEnv()
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case CommandBackup:
+		return Backup(ctx, opts)
+	case CommandRestore:
+		return Restore(ctx, opts)
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+// OptionsFromEnv reads the Options from the environment.
+func OptionsFromEnv() (Options, error) {
+	opts := Options{
+		S3: s3client.Config{
+			Bucket:    os.Getenv(EnvBucket),
+			Region:    os.Getenv(EnvRegion),
+			Endpoint:  s3client.EndpointURL(os.Getenv(EnvEndpoint)),
+			AccessKey: os.Getenv(EnvAccessKey),
+			SecretKey: os.Getenv(EnvSecretKey),
+		},
+		Prefix:       os.Getenv(EnvPrefix),
+		Name:         os.Getenv(EnvName),
+		Series:       os.Getenv(EnvSeries),
+		StorageRoot:  os.Getenv(EnvStorageRoot),
+		ReadOnlyFile: os.Getenv(EnvReadOnlyFile),
+	}
+
+	if retention := os.Getenv(EnvRetention); retention != "" {
+		n, err := strconv.Atoi(retention)
+		if err != nil {
+			return opts, fmt.Errorf("invalid %s: %w", EnvRetention, err)
+		}
+		opts.Retention = n
+	}
+
+	var errs error
+	for name, value := range map[string]string{
+		EnvBucket:      opts.S3.Bucket,
+		EnvRegion:      opts.S3.Region,
+		EnvAccessKey:   opts.S3.AccessKey,
+		EnvSecretKey:   opts.S3.SecretKey,
+		EnvName:        opts.Name,
+		EnvStorageRoot: opts.StorageRoot,
+	} {
+		if value == "" {
+			errs = errors.Join(errs, fmt.Errorf("%s is not set", name))
+		}
+	}
+	return opts, errs
+}
+
+// Backup waits for the Registry to be read-only, then uploads the archive of its storage root and deletes
+// the backups of the series beyond the retention.
+func Backup(ctx context.Context, opts Options) error {
+	log := ctrl.Log.WithName("backup")
+
+	cli, err := s3client.New(opts.S3, nil)
+	if err != nil {
+		return err
+	}
+
+	if opts.ReadOnlyFile != "" {
+		log.Info("Waiting for the Registry to be read-only")
+		if err := waitReadOnly(ctx, opts.ReadOnlyFile, readOnlyTimeout, readOnlyInterval); err != nil {
+			return err
+		}
+	}
+
+	// the archive is uploaded while it is written, not to be staged on the node
+	archive, w := io.Pipe()
+	go func() {
+		if err := writeArchive(w, opts.StorageRoot); err != nil {
+			_ = w.CloseWithError(fmt.Errorf("failed to archive %s: %w", opts.StorageRoot, err))
+			return
+		}
+		_ = w.Close()
+	}()
+
+	key := Key(opts.Prefix, opts.Name)
+	log.Info("Uploading the archive of the storage", "root", opts.StorageRoot, "bucket", opts.S3.Bucket, "key", key)
+	err = cli.Upload(ctx, key, archive)
+	// unblocks the archive when the upload stopped reading it
+	_ = archive.CloseWithError(err)
+	if err != nil {
+		return err
+	}
+
+	return prune(ctx, cli, opts)
+}
+
+// prune deletes the oldest backups of the series beyond the retention. The names of the backups taken by
+// a CronJob end with their scheduled time, so that they sort chronologically.
+func prune(ctx context.Context, cli *s3client.Client, opts Options) error {
+	if opts.Series == "" || opts.Retention < 1 {
+		return nil
+	}
+
+	keys, err := cli.List(ctx, path.Join(opts.Prefix, opts.Series)+"-")
+	if err != nil {
+		return err
+	}
+	keys = slices.DeleteFunc(keys, func(key string) bool { return !strings.HasSuffix(key, ".tar.gz") })
+	slices.Sort(keys)
+
+	for _, key := range keys[:max(len(keys)-opts.Retention, 0)] {
+		ctrl.Log.WithName("backup").Info("Deleting expired backup", "key", key)
+		if err := cli.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restore replaces the content of the storage root with the archive of the backup. The archive is extracted
+// into a staging directory while it is downloaded, so that the storage is left untouched when either fails.
+func Restore(ctx context.Context, opts Options) error {
+	log := ctrl.Log.WithName("restore")
+
+	cli, err := s3client.New(opts.S3, nil)
+	if err != nil {
+		return err
+	}
+
+	key := Key(opts.Prefix, opts.Name)
+	archive, err := cli.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer archive.Close() //nolint:errcheck // read-only body
+
+	// a staging directory left by a failed attempt is replaced
+	staging := filepath.Join(opts.StorageRoot, restoreStaging)
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+	if err := os.Mkdir(staging, 0o755); err != nil {
+		return err
+	}
+	log.Info("Extracting the backup", "bucket", opts.S3.Bucket, "key", key, "directory", staging)
+	if err := extractArchive(archive, staging); err != nil {
+		return fmt.Errorf("failed to extract %s: %w", key, err)
+	}
+
+	log.Info("Restoring the storage", "root", opts.StorageRoot)
+	return replaceDir(opts.StorageRoot)
+}
+
+// waitReadOnly waits for the given file, a key of the mounted state ConfigMap updated by the kubelet,
+// to hold "true".
+func waitReadOnly(ctx context.Context, file string, timeout, interval time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		content, err := os.ReadFile(file)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if strings.TrimSpace(string(content)) == "true" {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errReadOnlyTimeout
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/registry-operator/registry-operator/internal/s3client/s3test"
+)
+
+func TestBackupRestore(t *testing.T) {
+	s3 := s3test.NewServer(t, map[string][]byte{
+		"backups/my-registry-registry-backup-100.tar.gz": nil,
+		"backups/my-registry-registry-backup-101.tar.gz": nil,
+		"backups/other-registry-backup-100.tar.gz":       nil,
+	})
+
+	source := t.TempDir()
+	s3test.WriteFile(t, filepath.Join(source, "docker/registry/v2/blobs/sha256/ab/abcd/data"), "layer")
+	s3test.WriteFile(t, filepath.Join(source, "docker/registry/v2/repositories/app/_layers/link"), "sha256:abcd")
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "docker/registry/v2/repositories/empty"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(source, lostAndFound), 0o700))
+
+	readOnly := filepath.Join(t.TempDir(), ReadOnlyKey)
+	s3test.WriteFile(t, readOnly, "true")
+
+	// the archive is uploaded in several parts
+	s3Config := s3.Config()
+	s3Config.PartSize = 256
+
+	opts := Options{
+		S3:           s3Config,
+		Prefix:       "backups",
+		Name:         "my-registry-registry-backup-102",
+		Series:       "my-registry-registry-backup",
+		Retention:    2,
+		StorageRoot:  source,
+		ReadOnlyFile: readOnly,
+	}
+
+	t.Run("uploads the archive and applies the retention", func(t *testing.T) {
+		require.NoError(t, Backup(t.Context(), opts))
+
+		assert.Equal(t, []string{
+			"backups/my-registry-registry-backup-101.tar.gz",
+			"backups/my-registry-registry-backup-102.tar.gz",
+			"backups/other-registry-backup-100.tar.gz",
+		}, s3.Keys())
+		assert.Greater(t, len(s3.Objects()["backups/my-registry-registry-backup-102.tar.gz"]), s3Config.PartSize)
+	})
+
+	t.Run("aborts the upload when the storage cannot be archived", func(t *testing.T) {
+		opts := opts
+		opts.Name = "my-registry-registry-backup-103"
+		opts.StorageRoot = filepath.Join(t.TempDir(), "missing")
+		assert.ErrorContains(t, Backup(t.Context(), opts), "failed to archive")
+
+		assert.NotContains(t, s3.Keys(), "backups/my-registry-registry-backup-103.tar.gz")
+		assert.Zero(t, s3.Uploads())
+	})
+
+	t.Run("replaces the storage with the archive", func(t *testing.T) {
+		target := t.TempDir()
+		s3test.WriteFile(t, filepath.Join(target, "docker/registry/v2/repositories/stale/_layers/link"), "sha256:ef")
+
+		opts := opts
+		opts.StorageRoot = target
+		require.NoError(t, Restore(t.Context(), opts))
+
+		content, err := os.ReadFile(filepath.Join(target, "docker/registry/v2/blobs/sha256/ab/abcd/data"))
+		require.NoError(t, err)
+		assert.Equal(t, "layer", string(content))
+		assert.DirExists(t, filepath.Join(target, "docker/registry/v2/repositories/empty"))
+		assert.NoDirExists(t, filepath.Join(target, "docker/registry/v2/repositories/stale"))
+		assert.NoDirExists(t, filepath.Join(target, lostAndFound))
+	})
+
+	t.Run("replaces a staging directory left by a failed attempt", func(t *testing.T) {
+		target := t.TempDir()
+		s3test.WriteFile(t, filepath.Join(target, restoreStaging, "partial"), "x")
+
+		opts := opts
+		opts.StorageRoot = target
+		require.NoError(t, Restore(t.Context(), opts))
+
+		assert.FileExists(t, filepath.Join(target, "docker/registry/v2/blobs/sha256/ab/abcd/data"))
+		assert.NoFileExists(t, filepath.Join(target, "partial"))
+		assert.NoDirExists(t, filepath.Join(target, restoreStaging))
+	})
+
+	t.Run("fails to restore a missing backup", func(t *testing.T) {
+		target := t.TempDir()
+		s3test.WriteFile(t, filepath.Join(target, "docker/registry/v2/repositories/kept/_layers/link"), "sha256:ef")
+
+		opts := opts
+		opts.Name = "missing"
+		opts.StorageRoot = target
+		assert.ErrorContains(t, Restore(t.Context(), opts), "404")
+		assert.FileExists(t, filepath.Join(target, "docker/registry/v2/repositories/kept/_layers/link"))
+	})
+
+	t.Run("keeps the storage when the backup cannot be extracted", func(t *testing.T) {
+		target := t.TempDir()
+		s3test.WriteFile(t, filepath.Join(target, "docker/registry/v2/repositories/kept/_layers/link"), "sha256:ef")
+		s3.Put("backups/corrupted.tar.gz", []byte("not an archive"))
+
+		opts := opts
+		opts.Name = "corrupted"
+		opts.StorageRoot = target
+		assert.Error(t, Restore(t.Context(), opts))
+		assert.FileExists(t, filepath.Join(target, "docker/registry/v2/repositories/kept/_layers/link"))
+	})
+}
+
+func TestWaitReadOnly(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ReadOnlyKey)
+
+	t.Run("times out while the Registry is writable", func(t *testing.T) {
+		s3test.WriteFile(t, file, "false")
+		err := waitReadOnly(t.Context(), file, 20*time.Millisecond, time.Millisecond)
+		assert.ErrorIs(t, err, errReadOnlyTimeout)
+	})
+
+	t.Run("returns once the Registry is read-only", func(t *testing.T) {
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_ = os.WriteFile(file, []byte("true"), 0o644)
+		}()
+		assert.NoError(t, waitReadOnly(t.Context(), file, time.Second, time.Millisecond))
+	})
+}
+
+func TestExtractArchiveEscape(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../escaped", Typeflag: tar.TypeReg, Mode: 0o644, Size: 1}))
+	_, err := tw.Write([]byte("x"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	parent := t.TempDir()
+	target := filepath.Join(parent, "storage")
+	require.NoError(t, os.Mkdir(target, 0o755))
+
+	assert.Error(t, extractArchive(&buf, target))
+	assert.NoFileExists(t, filepath.Join(parent, "escaped"))
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
+	"github.com/registry-operator/registry-operator/internal/manifests/registry"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// setBackupParams sets the parameters of the given Registry following its backups and restores: it is read-only
+// while a backup Job runs, and scaled down while a RegistryRestore is in progress. It tells whether every
+// replica of a read-only Registry serves in read-only mode.
+func (r *RegistryReconciler) setBackupParams(ctx context.Context, params *manifests.Params) error {
+	reg := &params.Registry
+
+	restores := &registryv1alpha1.RegistryRestoreList{}
+	if err := r.List(ctx, restores, client.InNamespace(reg.Namespace)); err != nil {
+		return fmt.Errorf("failed to list RegistryRestores: %w", err)
+	}
+	for _, restore := range restores.Items {
+		if restore.Spec.RegistryRef.Name == reg.Name && !isRestoreFinished(&restore) {
+			params.Restoring = true
+		}
+	}
+
+	if reg.Spec.Backup != nil {
+		jobs := &batchv1.JobList{}
+		if err := r.List(ctx, jobs, client.InNamespace(reg.Namespace),
+			client.MatchingLabels(manifestutils.SelectorLabels(reg.ObjectMeta, registry.ComponentBackup)),
+		); err != nil {
+			return fmt.Errorf("failed to list backup Jobs: %w", err)
+		}
+		for _, job := range jobs.Items {
+			if !isJobFinished(&job) {
+				params.ReadOnly = true
+			}
+		}
+	}
+	// the Registry may also be read-only while its storage is migrated
+	if !params.ReadOnly {
+		return nil
+	}
+
+	dpl := &appsv1.Deployment{}
+	key := client.ObjectKey{Namespace: reg.Namespace, Name: naming.Registry(reg.Name)}
+	if err := r.Get(ctx, key, dpl); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get Deployment: %w", err)
+	}
+	replicas := ptr.Deref(dpl.Spec.Replicas, 1)
+	params.ReadOnlyReady = dpl.Spec.Template.Annotations[registry.ReadOnlyAnnotation] == "true" &&
+		dpl.Status.ObservedGeneration >= dpl.Generation &&
+		dpl.Status.UpdatedReplicas == replicas &&
+		dpl.Status.Replicas == replicas &&
+		dpl.Status.AvailableReplicas == replicas
+	return nil
+}
+
+// isJobFinished reports whether the given Job completed or failed.
+func isJobFinished(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) &&
+			condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// isRestoreFinished reports whether the given RegistryRestore succeeded or failed.
+func isRestoreFinished(restore *registryv1alpha1.RegistryRestore) bool {
+	return restore.Status.Phase == registryv1alpha1.RegistryRestorePhaseSucceeded ||
+		restore.Status.Phase == registryv1alpha1.RegistryRestorePhaseFailed
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSetBackupParams(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	reg := registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry", Namespace: "default"},
+		Spec: registryv1alpha1.RegistrySpec{
+			Backup: &registryv1alpha1.Backup{Schedule: "@daily"},
+		},
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-registry-registry-backup-29000000",
+			Namespace: "default",
+			Labels: map[string]string{
+				"app.kubernetes.io/component":  "backup",
+				"app.kubernetes.io/instance":   "default.my-registry",
+				"app.kubernetes.io/managed-by": "registry-operator",
+				"app.kubernetes.io/part-of":    "registry",
+			},
+		},
+	}
+	dpl := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry-registry", Namespace: "default", Generation: 2},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](1),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{"registry-operator.dev/read-only": "true"},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           1,
+			UpdatedReplicas:    1,
+			AvailableReplicas:  1,
+		},
+	}
+	restore := &registryv1alpha1.RegistryRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "yesterday", Namespace: "default"},
+		Spec: registryv1alpha1.RegistryRestoreSpec{
+			RegistryRef: corev1.LocalObjectReference{Name: reg.Name},
+		},
+		Status: registryv1alpha1.RegistryRestoreStatus{Phase: registryv1alpha1.RegistryRestorePhaseSucceeded},
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&batchv1.Job{}, &appsv1.Deployment{}).
+		WithObjects(job, dpl, restore).
+		Build()
+	r := &RegistryReconciler{Client: cli, Scheme: scheme}
+
+	t.Run("is read-only while a backup Job runs", func(t *testing.T) {
+		params := manifests.Params{Registry: reg}
+		require.NoError(t, r.setBackupParams(t.Context(), &params))
+		assert.True(t, params.ReadOnly)
+		assert.True(t, params.ReadOnlyReady)
+		assert.False(t, params.Restoring)
+	})
+
+	t.Run("is writable once the backup Job finished", func(t *testing.T) {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		require.NoError(t, cli.Status().Update(t.Context(), job))
+
+		params := manifests.Params{Registry: reg}
+		require.NoError(t, r.setBackupParams(t.Context(), &params))
+		assert.False(t, params.ReadOnly)
+		assert.False(t, params.ReadOnlyReady)
+	})
+
+	t.Run("is scaled down while a restore is in progress", func(t *testing.T) {
+		restore.Status.Phase = registryv1alpha1.RegistryRestorePhaseRunning
+		require.NoError(t, cli.Update(t.Context(), restore))
+
+		params := manifests.Params{Registry: reg}
+		require.NoError(t, r.setBackupParams(t.Context(), &params))
+		assert.True(t, params.Restoring)
+	})
+}
@@ -22,12 +22,17 @@ import (
 	"errors"
 	"fmt"
 
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
 	"github.com/registry-operator/registry-operator/internal/manifests"
 	"github.com/registry-operator/registry-operator/internal/manifests/registry"
+	"github.com/registry-operator/registry-operator/internal/registryclient"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
@@ -38,6 +43,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	reasonRegistryNotReady   = "RegistryNotReady"
+	reasonInvalidCredentials = "InvalidCredentials"
+)
+
+var (
+	errRegistryNotReady   = errors.New("registry is not ready")
+	errInvalidCredentials = errors.New("invalid credentials")
+)
+
 // BuildRegistry returns the generation and collected errors of all manifests for a given instance.
 func BuildRegistry(ctx context.Context, params manifests.Params) ([]client.Object, error) {
 	builders := []manifests.Builder[manifests.Params]{
@@ -56,6 +71,12 @@ func BuildRegistry(ctx context.Context, params manifests.Params) ([]client.Objec
 	return resources, nil
 }
 
+// isServed reports whether the API server serves the given kind, e.g. one provided by an optional CRD.
+func isServed(mgr ctrl.Manager, gvk schema.GroupVersionKind) bool {
+	_, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	return err == nil
+}
+
 // getList queries the Kubernetes API to list the requested resource, setting the list l of type T.
 func getList[T client.Object](
 	ctx context.Context,
@@ -170,3 +191,48 @@ func deleteObjects(ctx context.Context, kubeClient client.Client, objects map[ty
 	}
 	return errors.Join(pruneErrs...)
 }
+
+// registryURL returns the base URL of the in-cluster Service of the given Registry.
+func registryURL(ctx context.Context, c client.Client, key client.ObjectKey) (string, error) {
+	registry := &registryv1alpha1.Registry{}
+	if err := c.Get(ctx, key, registry); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("%w: %s does not exist", errRegistryNotReady, key.Name)
+		}
+		return "", fmt.Errorf("failed to get registry: %w", err)
+	}
+
+	for _, endpoint := range registry.Status.Endpoints {
+		if endpoint.Type == registryv1alpha1.EndpointTypeClusterIP {
+			return "http://" + endpoint.Address, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s has no endpoint yet", errRegistryNotReady, key.Name)
+}
+
+// registryCredentials returns the credentials for the given registry held by the referenced Secret, if any.
+func registryCredentials(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	ref *corev1.LocalObjectReference,
+	baseURL string,
+) (*registryclient.Credentials, error) {
+	if ref == nil {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: Secret %s does not exist", errInvalidCredentials, ref.Name)
+		}
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	credentials, err := registryclient.CredentialsFromSecret(secret, baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidCredentials, err)
+	}
+	return credentials, nil
+}
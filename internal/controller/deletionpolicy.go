@@ -0,0 +1,287 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
+	"github.com/registry-operator/registry-operator/internal/manifests/registry"
+	"github.com/registry-operator/registry-operator/internal/naming"
+	"github.com/registry-operator/registry-operator/internal/s3client"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	componentSnapshot = "snapshot"
+
+	reasonDeletionPolicyFailed = "DeletionPolicyFailed"
+	reasonClaimDeleted         = "PersistentVolumeClaimDeleted"
+	reasonSnapshotCreated      = "VolumeSnapshotCreated"
+	reasonStoragePurged        = "StoragePurged"
+	reasonStorageRetained      = "StorageRetained"
+)
+
+var volumeSnapshotGVK = schema.GroupVersionKind{
+	Group:   registry.VolumeSnapshotGroup,
+	Version: "v1",
+	Kind:    registry.VolumeSnapshotKind,
+}
+
+var (
+	// errSnapshotPending is returned while the VolumeSnapshot taken before deleting the storage is not ready.
+	errSnapshotPending = errors.New("waiting for the final VolumeSnapshot to be ready to use")
+
+	errSnapshotsNotServed = errors.New("VolumeSnapshots are not served, install the CSI snapshot CRDs " +
+		"or change the deletion policy")
+)
+
+// applyDeletionPolicy retains or deletes the stored images of the deleted Registry according to its policy.
+// Only the PersistentVolumeClaim created from the persistentVolumeClaimTemplate and the S3 objects under the root
+// directory of the Registry are affected, the volumes provided by the users are left alone. The images are retained
+// when the namespace of the Registry is terminating, neither VolumeSnapshots nor the S3 credentials being reliable
+// in a namespace being emptied.
+func (r *RegistryReconciler) applyDeletionPolicy(ctx context.Context, params manifests.Params) error {
+	reg := &params.Registry
+
+	claim, err := r.registryClaim(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	policy := reg.Spec.DeletionPolicy
+	if policy == registryv1alpha1.DeletionPolicyDelete || policy == registryv1alpha1.DeletionPolicySnapshot {
+		terminating, err := r.namespaceTerminating(ctx, reg.Namespace)
+		if err != nil {
+			return err
+		}
+		if terminating {
+			r.Recorder.Eventf(reg, corev1.EventTypeWarning, reasonStorageRetained,
+				"The namespace is terminating, the stored images are retained instead of applying the %s policy", policy)
+			policy = registryv1alpha1.DeletionPolicyRetain
+		}
+	}
+
+	switch policy {
+	case registryv1alpha1.DeletionPolicyDelete:
+		if err := r.deleteClaim(ctx, reg, claim); err != nil {
+			return err
+		}
+		return r.purgeS3(ctx, params)
+
+	case registryv1alpha1.DeletionPolicySnapshot:
+		if claim != nil {
+			ready, err := r.snapshotClaim(ctx, reg, claim)
+			if err != nil {
+				return err
+			}
+			if !ready {
+				return errSnapshotPending
+			}
+			if err := r.deleteClaim(ctx, reg, claim); err != nil {
+				return err
+			}
+		}
+		if reg.Spec.Storage.S3 != nil {
+			r.Recorder.Event(reg, corev1.EventTypeNormal, reasonStorageRetained,
+				"S3 objects cannot be snapshotted and have been kept")
+		}
+		return nil
+
+	default:
+		if claim == nil {
+			return nil
+		}
+		if err := r.releaseObject(ctx, claim, reg); err != nil {
+			return fmt.Errorf("failed to release PersistentVolumeClaim %s: %w", claim.Name, err)
+		}
+		r.Recorder.Eventf(reg, corev1.EventTypeNormal, reasonClaimRetained,
+			"PersistentVolumeClaim %s has been retained", claim.Name)
+		return nil
+	}
+}
+
+// namespaceTerminating reports whether the given namespace is being deleted.
+func (r *RegistryReconciler) namespaceTerminating(ctx context.Context, name string) (bool, error) {
+	namespace := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get namespace: %w", err)
+	}
+	return namespace.GetDeletionTimestamp() != nil || namespace.Status.Phase == corev1.NamespaceTerminating, nil
+}
+
+// registryClaim returns the PersistentVolumeClaim created from the persistentVolumeClaimTemplate of the Registry,
+// nil when there is none left.
+func (r *RegistryReconciler) registryClaim(
+	ctx context.Context,
+	reg *registryv1alpha1.Registry,
+) (*corev1.PersistentVolumeClaim, error) {
+	claim := &corev1.PersistentVolumeClaim{}
+	key := client.ObjectKey{Namespace: reg.Namespace, Name: naming.PersistentVolumeClaim(reg.Name)}
+	if err := r.Get(ctx, key, claim); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get PersistentVolumeClaim: %w", err)
+	}
+	if !metav1.IsControlledBy(claim, reg) || claim.GetDeletionTimestamp() != nil {
+		return nil, nil
+	}
+	return claim, nil
+}
+
+// deleteClaim deletes the given PersistentVolumeClaim, if any. It is only removed once the Pods of the Registry
+// using it are gone.
+func (r *RegistryReconciler) deleteClaim(
+	ctx context.Context,
+	reg *registryv1alpha1.Registry,
+	claim *corev1.PersistentVolumeClaim,
+) error {
+	if claim == nil {
+		return nil
+	}
+	if err := r.Delete(ctx, claim); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete PersistentVolumeClaim %s: %w", claim.Name, err)
+	}
+	log.FromContext(ctx).Info("Deleted PersistentVolumeClaim", "name", claim.Name)
+	r.Recorder.Eventf(reg, corev1.EventTypeNormal, reasonClaimDeleted,
+		"PersistentVolumeClaim %s has been deleted", claim.Name)
+	return nil
+}
+
+// snapshotClaim takes a VolumeSnapshot of the given PersistentVolumeClaim, not owned by the Registry so that it
+// outlives it, and reports whether it is ready to use.
+func (r *RegistryReconciler) snapshotClaim(
+	ctx context.Context,
+	reg *registryv1alpha1.Registry,
+	claim *corev1.PersistentVolumeClaim,
+) (bool, error) {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	key := client.ObjectKey{Namespace: reg.Namespace, Name: naming.FinalSnapshot(reg.Name)}
+
+	err := r.Get(ctx, key, snapshot)
+	switch {
+	case meta.IsNoMatchError(err):
+		return false, errSnapshotsNotServed
+	case apierrors.IsNotFound(err):
+		snapshot.SetName(key.Name)
+		snapshot.SetNamespace(key.Namespace)
+		snapshot.SetLabels(manifestutils.SelectorLabels(reg.ObjectMeta, componentSnapshot))
+		if err := unstructured.SetNestedField(
+			snapshot.Object, claim.Name, "spec", "source", "persistentVolumeClaimName",
+		); err != nil {
+			return false, err
+		}
+		if err := r.Create(ctx, snapshot); err != nil {
+			if meta.IsNoMatchError(err) {
+				return false, errSnapshotsNotServed
+			}
+			return false, fmt.Errorf("failed to create VolumeSnapshot: %w", err)
+		}
+		log.FromContext(ctx).Info("Created VolumeSnapshot", "name", key.Name)
+		r.Recorder.Eventf(reg, corev1.EventTypeNormal, reasonSnapshotCreated,
+			"VolumeSnapshot %s of PersistentVolumeClaim %s has been created", key.Name, claim.Name)
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("failed to get VolumeSnapshot: %w", err)
+	}
+
+	// a snapshot left by a previous Registry of the same name does not hold the current images
+	source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	created := snapshot.GetCreationTimestamp()
+	if source != claim.Name || created.Before(&reg.CreationTimestamp) {
+		return false, fmt.Errorf("VolumeSnapshot %s was not taken for this Registry, rename or delete it", key.Name)
+	}
+	if message, _, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); message != "" {
+		return false, fmt.Errorf("VolumeSnapshot %s failed: %s", key.Name, message)
+	}
+	ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	return ready, nil
+}
+
+// purgeS3 deletes the objects under the root directory of the Registry in its S3 bucket, which requires static
+// credentials. Only the default root directory of the Registry is purged, any other one possibly being shared
+// with other Registries, e.g. through their RegistryClass.
+func (r *RegistryReconciler) purgeS3(ctx context.Context, params manifests.Params) error {
+	reg := &params.Registry
+	if reg.Spec.Storage.S3 == nil {
+		return nil
+	}
+	root := registry.S3RootDirectory(reg.Spec.Storage.S3)
+	if root != naming.S3RootDirectory(reg.Namespace, reg.Name) {
+		r.Recorder.Eventf(reg, corev1.EventTypeWarning, reasonStorageRetained,
+			"S3 objects have been kept, the root directory %s may be shared with other Registries", root)
+		return nil
+	}
+
+	cfg, err := registry.S3Config(ctx, params)
+	if apierrors.IsNotFound(err) || errors.Is(err, manifests.ErrInvalidConfig) {
+		// the credentials will not come back, retrying would keep the Registry from being deleted
+		r.Recorder.Eventf(reg, corev1.EventTypeWarning, reasonStorageRetained,
+			"S3 objects have been kept, the S3 configuration cannot be read: %v", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the S3 configuration: %w", err)
+	}
+	parameter := func(name string) string {
+		value, _ := cfg[name].(string)
+		return value
+	}
+	if parameter("accesskey") == "" || parameter("secretkey") == "" {
+		r.Recorder.Event(reg, corev1.EventTypeWarning, reasonStorageRetained,
+			"S3 objects have been kept, purging them requires an accessKey and a secretKey")
+		return nil
+	}
+
+	// the regionendpoint read by S3Config is given its scheme by s3client.EndpointURL
+	cli, err := s3client.New(s3client.Config{
+		Bucket:    parameter("bucket"),
+		Region:    parameter("region"),
+		Endpoint:  parameter("regionendpoint"),
+		AccessKey: parameter("accesskey"),
+		SecretKey: parameter("secretkey"),
+	}, r.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	prefix := strings.TrimPrefix(root, "/") + "/"
+	deleted, err := cli.DeletePrefix(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to purge the S3 objects after deleting %d: %w", deleted, err)
+	}
+	log.FromContext(ctx).Info("Purged S3 objects", "bucket", parameter("bucket"), "deleted", deleted)
+	r.Recorder.Eventf(reg, corev1.EventTypeNormal, reasonStoragePurged,
+		"%d S3 objects under s3://%s/%s have been deleted", deleted, parameter("bucket"), prefix)
+	return nil
+}
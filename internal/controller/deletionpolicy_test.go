@@ -0,0 +1,219 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyDeletionPolicy(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	setup := func(t *testing.T, policy registryv1alpha1.DeletionPolicy, objects ...client.Object) (
+		*RegistryReconciler, manifests.Params, *corev1.PersistentVolumeClaim,
+	) {
+		t.Helper()
+
+		reg := &registryv1alpha1.Registry{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-registry", Namespace: "default", UID: "uid"},
+			Spec:       registryv1alpha1.RegistrySpec{DeletionPolicy: policy},
+		}
+		claim := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-registry-registry", Namespace: "default"},
+		}
+		require.NoError(t, ctrl.SetControllerReference(reg, claim, scheme))
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+
+		cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objects, namespace, claim)...).Build()
+		r := &RegistryReconciler{
+			Client:   cli,
+			Scheme:   scheme,
+			Recorder: record.NewFakeRecorder(10),
+		}
+		return r, manifests.Params{Client: cli, Registry: *reg, Scheme: scheme}, claim
+	}
+
+	t.Run("retains the PersistentVolumeClaim", func(t *testing.T) {
+		r, params, claim := setup(t, registryv1alpha1.DeletionPolicyRetain)
+
+		require.NoError(t, r.applyDeletionPolicy(t.Context(), params))
+
+		retained := &corev1.PersistentVolumeClaim{}
+		require.NoError(t, r.Get(t.Context(), client.ObjectKeyFromObject(claim), retained))
+		assert.Empty(t, retained.OwnerReferences)
+	})
+
+	// s3Storage serves a bucket holding an object of the Registry, and returns the Secret and the S3 storage
+	// locating it at the given endpoint, and the keys of the deleted objects.
+	s3Storage := func(
+		t *testing.T,
+		newServer func(http.Handler) *httptest.Server,
+		endpoint func(*httptest.Server) string,
+	) (*httptest.Server, *corev1.Secret, *registryv1alpha1.S3StorageSource, func() []string) {
+		t.Helper()
+
+		var mu sync.Mutex
+		var deleted []string
+		server := newServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch r.Method {
+			case http.MethodGet:
+				fmt.Fprint(w, "<ListBucketResult>")
+				if len(deleted) == 0 && r.URL.Query().Get("prefix") == "registry/default/my-registry/" {
+					fmt.Fprint(w, "<Contents><Key>registry/default/my-registry/docker/registry/v2/blob</Key></Contents>")
+				}
+				fmt.Fprint(w, "</ListBucketResult>")
+			case http.MethodDelete:
+				deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/bucket/"))
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "s3", Namespace: "default"},
+			Data: map[string][]byte{
+				"bucket":    []byte("bucket"),
+				"region":    []byte("us-east-1"),
+				"accessKey": []byte("access"),
+				"secretKey": []byte("secret"),
+				"endpoint":  []byte(endpoint(server)),
+			},
+		}
+		selector := func(key string) *registryv1alpha1.SecretKeySelector {
+			return &registryv1alpha1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "s3"}, Key: key}
+		}
+		storage := &registryv1alpha1.S3StorageSource{
+			BucketName:  *selector("bucket"),
+			Region:      *selector("region"),
+			AccessKey:   selector("accessKey"),
+			SecretKey:   selector("secretKey"),
+			EndpointURL: selector("endpoint"),
+		}
+		return server, secret, storage, func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return slices.Clone(deleted)
+		}
+	}
+
+	t.Run("deletes the PersistentVolumeClaim and purges S3", func(t *testing.T) {
+		_, secret, storage, deleted := s3Storage(t, httptest.NewServer, func(s *httptest.Server) string { return s.URL })
+		r, params, claim := setup(t, registryv1alpha1.DeletionPolicyDelete, secret)
+		params.Registry.Spec.Storage.S3 = storage
+
+		t.Run("keeps the shared root directory", func(t *testing.T) {
+			require.NoError(t, r.purgeS3(t.Context(), params))
+			assert.Empty(t, deleted())
+		})
+
+		params.Registry.Spec.Storage.S3.RootDirectory = "/registry/default/my-registry"
+		require.NoError(t, r.applyDeletionPolicy(t.Context(), params))
+
+		err := r.Get(t.Context(), client.ObjectKeyFromObject(claim), &corev1.PersistentVolumeClaim{})
+		assert.True(t, apierrors.IsNotFound(err))
+		assert.Equal(t, []string{"registry/default/my-registry/docker/registry/v2/blob"}, deleted())
+	})
+
+	t.Run("purges S3 over https when the endpoint has no scheme", func(t *testing.T) {
+		server, secret, storage, deleted := s3Storage(t, httptest.NewTLSServer, func(s *httptest.Server) string {
+			return strings.TrimPrefix(s.URL, "https://")
+		})
+		r, params, _ := setup(t, registryv1alpha1.DeletionPolicyDelete, secret)
+		r.HTTPClient = server.Client()
+		params.Registry.Spec.Storage.S3 = storage
+		params.Registry.Spec.Storage.S3.RootDirectory = "/registry/default/my-registry"
+
+		require.NoError(t, r.purgeS3(t.Context(), params))
+		assert.Equal(t, []string{"registry/default/my-registry/docker/registry/v2/blob"}, deleted())
+	})
+
+	t.Run("retains the S3 objects without credentials", func(t *testing.T) {
+		r, params, _ := setup(t, registryv1alpha1.DeletionPolicyDelete)
+		params.Registry.Spec.Storage.S3 = &registryv1alpha1.S3StorageSource{
+			BucketName: registryv1alpha1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "deleted"},
+				Key:                  "bucket",
+			},
+			RootDirectory: "/registry/default/my-registry",
+		}
+
+		require.NoError(t, r.applyDeletionPolicy(t.Context(), params))
+	})
+
+	t.Run("retains the PersistentVolumeClaim of a terminating namespace", func(t *testing.T) {
+		r, params, claim := setup(t, registryv1alpha1.DeletionPolicySnapshot)
+		namespace := &corev1.Namespace{}
+		require.NoError(t, r.Get(t.Context(), client.ObjectKey{Name: "default"}, namespace))
+		namespace.Status.Phase = corev1.NamespaceTerminating
+		require.NoError(t, r.Status().Update(t.Context(), namespace))
+
+		require.NoError(t, r.applyDeletionPolicy(t.Context(), params))
+
+		retained := &corev1.PersistentVolumeClaim{}
+		require.NoError(t, r.Get(t.Context(), client.ObjectKeyFromObject(claim), retained))
+		assert.Empty(t, retained.OwnerReferences)
+	})
+
+	t.Run("snapshots the PersistentVolumeClaim before deleting it", func(t *testing.T) {
+		r, params, claim := setup(t, registryv1alpha1.DeletionPolicySnapshot)
+
+		assert.ErrorIs(t, r.applyDeletionPolicy(t.Context(), params), errSnapshotPending)
+
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+		key := client.ObjectKey{Namespace: "default", Name: "my-registry-registry-final"}
+		require.NoError(t, r.Get(t.Context(), key, snapshot))
+		source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+		assert.Equal(t, claim.Name, source)
+
+		assert.ErrorIs(t, r.applyDeletionPolicy(t.Context(), params), errSnapshotPending)
+		require.NoError(t, r.Get(t.Context(), client.ObjectKeyFromObject(claim), &corev1.PersistentVolumeClaim{}))
+
+		require.NoError(t, unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse"))
+		require.NoError(t, r.Update(t.Context(), snapshot))
+
+		require.NoError(t, r.applyDeletionPolicy(t.Context(), params))
+		err := r.Get(t.Context(), client.ObjectKeyFromObject(claim), &corev1.PersistentVolumeClaim{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+}
@@ -0,0 +1,424 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/registryclient"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	reasonSynced          = "Synced"
+	reasonSyncing         = "Syncing"
+	reasonSyncFailed      = "SyncFailed"
+	reasonSuspended       = "Suspended"
+	reasonInvalidSchedule = "InvalidSchedule"
+	reasonInvalidFilter   = "InvalidFilter"
+
+	// defaultImageCopiesPerReconcile bounds the images copied by a reconciliation, the next ones copying the
+	// remaining images.
+	defaultImageCopiesPerReconcile = 10
+	// imageSyncResumeDelay is the delay before resuming a synchronization in progress.
+	imageSyncResumeDelay = time.Second
+	// maxImageSyncResults bounds the images listed in the status of an ImageSync.
+	maxImageSyncResults = 100
+)
+
+var (
+	errInvalidSchedule = errors.New("invalid schedule")
+	errInvalidFilter   = errors.New("invalid image filter")
+	errSyncFailed      = errors.New("failed to sync images")
+)
+
+// ImageSyncReconciler reconciles an ImageSync object.
+type ImageSyncReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// HTTPClient is used to talk to the registries, a default client is used if nil.
+	HTTPClient *http.Client
+
+	// CopiesPerReconcile is the number of images copied by a reconciliation, defaultImageCopiesPerReconcile if
+	// zero.
+	CopiesPerReconcile int
+}
+
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=imagesyncs,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=imagesyncs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=registries,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ImageSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&registryv1alpha1.ImageSync{}).
+		Watches(
+			&registryv1alpha1.Registry{},
+			handler.EnqueueRequestsFromMapFunc(r.MapRegistries),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.MapCredentialsSecrets),
+		).
+		Complete(r)
+}
+
+// Reconcile copies the selected images from the source registry into the target Registry,
+// on the schedule of the ImageSync.
+func (r *ImageSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx, "imagesync", klog.KRef(req.Namespace, req.Name))
+
+	var instance registryv1alpha1.ImageSync
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Error(err, "unable to fetch ImageSync")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// the synced images are left in the Registry
+	if instance.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	changed := instance.DeepCopy()
+	changed.Status.ObservedGeneration = changed.Generation
+	condition := metav1.Condition{
+		Type:               registryv1alpha1.ConditionTypeReady,
+		ObservedGeneration: changed.Generation,
+	}
+
+	schedule, syncErr := cron.ParseStandard(instance.Spec.Schedule)
+	now := time.Now()
+	var wait time.Duration
+	switch {
+	case syncErr != nil:
+		syncErr = fmt.Errorf("%w: %w", errInvalidSchedule, syncErr)
+		changed.Status.NextSyncTime = nil
+
+	case instance.Spec.Suspend:
+		changed.Status.NextSyncTime = nil
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonSuspended
+		condition.Message = "Synchronization is suspended"
+
+	default:
+		// a sync which could not start is retried as soon as something changes
+		last := instance.Status.LastSyncTime
+		ready := meta.FindStatusCondition(instance.Status.Conditions, registryv1alpha1.ConditionTypeReady)
+		started := ready != nil && (ready.Reason == reasonSynced || ready.Reason == reasonSyncFailed)
+		observed := instance.Status.ObservedGeneration == instance.Generation
+		if last != nil && started && observed {
+			if next := schedule.Next(last.Time); next.After(now) {
+				return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+			}
+		}
+		resuming := ready != nil && ready.Reason == reasonSyncing && observed && instance.Status.ResumeAfter != ""
+
+		syncErr = r.sync(ctx, changed, resuming)
+		summary := changed.Status.Summary
+		switch {
+		case syncErr != nil && !errors.Is(syncErr, errSyncFailed):
+		case summary.Pending > 0:
+			// the failed images are reported once the synchronization handled all the images
+			syncErr = nil
+			wait = imageSyncResumeDelay
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = reasonSyncing
+			condition.Message = fmt.Sprintf("Syncing images, %d pending", summary.Pending)
+		default:
+			next := schedule.Next(now)
+			changed.Status.LastSyncTime = &metav1.Time{Time: now}
+			changed.Status.NextSyncTime = &metav1.Time{Time: next}
+			wait = next.Sub(now)
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = reasonSynced
+			condition.Message = fmt.Sprintf("Synced %d images", summary.Synced+summary.UpToDate+summary.Failed)
+		}
+	}
+
+	if syncErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Message = syncErr.Error()
+		switch {
+		case errors.Is(syncErr, errInvalidSchedule):
+			condition.Reason = reasonInvalidSchedule
+		case errors.Is(syncErr, errInvalidFilter):
+			condition.Reason = reasonInvalidFilter
+		case errors.Is(syncErr, errInvalidCredentials):
+			condition.Reason = reasonInvalidCredentials
+		case errors.Is(syncErr, errRegistryNotReady):
+			condition.Reason = reasonRegistryNotReady
+		default:
+			condition.Reason = reasonSyncFailed
+		}
+	}
+	meta.SetStatusCondition(&changed.Status.Conditions, condition)
+
+	if err := r.Status().Patch(ctx, changed, client.MergeFrom(&instance)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to apply status changes to the ImageSync CR: %w", err)
+	}
+
+	switch {
+	case errors.Is(syncErr, errInvalidSchedule), errors.Is(syncErr, errInvalidFilter),
+		errors.Is(syncErr, errInvalidCredentials), errors.Is(syncErr, errRegistryNotReady):
+		// retried once the ImageSync, the Registry or the Secrets change
+		r.Recorder.Event(changed, corev1.EventTypeWarning, condition.Reason, syncErr.Error())
+		return ctrl.Result{}, nil
+	case errors.Is(syncErr, errSyncFailed):
+		// the failed images are retried on schedule
+		r.Recorder.Event(changed, corev1.EventTypeWarning, condition.Reason, syncErr.Error())
+		return ctrl.Result{RequeueAfter: wait}, nil
+	case syncErr != nil:
+		r.Recorder.Event(changed, corev1.EventTypeWarning, condition.Reason, syncErr.Error())
+		return ctrl.Result{}, syncErr
+	}
+
+	return ctrl.Result{RequeueAfter: wait}, nil
+}
+
+// imageSyncCandidate is an image selected by an ImageSync, or the failure to list the tags of a repository.
+type imageSyncCandidate struct {
+	result registryv1alpha1.ImageSyncResult
+	source registryclient.Image
+	target registryclient.Image
+}
+
+// sync copies the images selected by the given ImageSync and records the outcome in its status. It copies up
+// to CopiesPerReconcile images, leaving the remaining ones pending. When resuming, the images up to the
+// ResumeAfter of the status are skipped and the outcome is added to the one recorded in the status.
+func (r *ImageSyncReconciler) sync(ctx context.Context, changed *registryv1alpha1.ImageSync, resuming bool) error {
+	filters := make([][]*regexp.Regexp, len(changed.Spec.Images))
+	for i, image := range changed.Spec.Images {
+		for _, expr := range image.Tags {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("%w: %w", errInvalidFilter, err)
+			}
+			filters[i] = append(filters[i], re)
+		}
+	}
+
+	source, err := r.sourceClient(ctx, changed)
+	if err != nil {
+		return err
+	}
+	target, err := r.targetClient(ctx, changed)
+	if err != nil {
+		return err
+	}
+
+	var candidates []imageSyncCandidate
+	for i, image := range changed.Spec.Images {
+		targetRepository := changed.Spec.Target.RepositoryPrefix + image.Repository
+
+		tags, err := source.Tags(ctx, image.Repository)
+		if err != nil {
+			candidates = append(candidates, imageSyncCandidate{result: registryv1alpha1.ImageSyncResult{
+				Image:   targetRepository,
+				Status:  registryv1alpha1.ImageSyncResultFailed,
+				Message: err.Error(),
+			}})
+			continue
+		}
+
+		for _, tag := range tags {
+			if !matchesAny(filters[i], tag) {
+				continue
+			}
+			candidates = append(candidates, imageSyncCandidate{
+				result: registryv1alpha1.ImageSyncResult{
+					Image:  targetRepository + ":" + tag,
+					Status: registryv1alpha1.ImageSyncResultSynced,
+				},
+				source: registryclient.Image{Client: source, Repository: image.Repository, Tag: tag},
+				target: registryclient.Image{Client: target, Repository: targetRepository, Tag: tag},
+			})
+		}
+	}
+
+	// the images are handled in order, the same image may be selected by several filters
+	slices.SortStableFunc(candidates, func(a, b imageSyncCandidate) int {
+		return cmp.Compare(a.result.Image, b.result.Image)
+	})
+	candidates = slices.CompactFunc(candidates, func(a, b imageSyncCandidate) bool {
+		return a.result.Image == b.result.Image
+	})
+
+	summary := registryv1alpha1.ImageSyncSummary{}
+	results := []registryv1alpha1.ImageSyncResult{}
+	resumeAfter := ""
+	if resuming && changed.Status.Summary != nil {
+		summary = *changed.Status.Summary
+		summary.Pending = 0
+		results = append(results, changed.Status.Images...)
+		resumeAfter = changed.Status.ResumeAfter
+	}
+
+	limit := cmp.Or(r.CopiesPerReconcile, defaultImageCopiesPerReconcile)
+	copies, copied := 0, 0
+	for _, candidate := range candidates {
+		if resuming && candidate.result.Image <= changed.Status.ResumeAfter {
+			continue
+		}
+		if copies >= limit {
+			summary.Pending++
+			continue
+		}
+		resumeAfter = candidate.result.Image
+
+		result := candidate.result
+		if result.Status != registryv1alpha1.ImageSyncResultFailed {
+			digest, upToDate, err := registryclient.Copy(ctx, candidate.source, candidate.target)
+			switch {
+			case err != nil:
+				copies++
+				result.Status = registryv1alpha1.ImageSyncResultFailed
+				result.Message = err.Error()
+			case upToDate:
+				result.Status = registryv1alpha1.ImageSyncResultUpToDate
+			default:
+				copies++
+				copied++
+			}
+			result.Digest = digest
+		}
+		switch result.Status {
+		case registryv1alpha1.ImageSyncResultFailed:
+			summary.Failed++
+		case registryv1alpha1.ImageSyncResultUpToDate:
+			summary.UpToDate++
+		default:
+			summary.Synced++
+		}
+		results = append(results, result)
+	}
+
+	changed.Status.Images = capImageSyncResults(results)
+	changed.Status.Summary = &summary
+	changed.Status.ResumeAfter = ""
+	if summary.Pending > 0 {
+		changed.Status.ResumeAfter = resumeAfter
+	}
+
+	if copied > 0 {
+		r.Recorder.Event(changed, corev1.EventTypeNormal, reasonSynced, fmt.Sprintf(
+			"Copied %d images to %s", copied, changed.Spec.Target.RegistryRef.Name,
+		))
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("%w: %d of %d images failed", errSyncFailed, summary.Failed,
+			summary.Synced+summary.UpToDate+summary.Failed)
+	}
+	return nil
+}
+
+// capImageSyncResults returns up to maxImageSyncResults of the given results sorted by image, keeping the
+// failed ones first.
+func capImageSyncResults(results []registryv1alpha1.ImageSyncResult) []registryv1alpha1.ImageSyncResult {
+	if len(results) <= maxImageSyncResults {
+		return results
+	}
+	rank := func(result registryv1alpha1.ImageSyncResult) int {
+		if result.Status == registryv1alpha1.ImageSyncResultFailed {
+			return 0
+		}
+		return 1
+	}
+	slices.SortStableFunc(results, func(a, b registryv1alpha1.ImageSyncResult) int {
+		return cmp.Compare(rank(a), rank(b))
+	})
+	results = results[:maxImageSyncResults]
+	slices.SortFunc(results, func(a, b registryv1alpha1.ImageSyncResult) int {
+		return cmp.Compare(a.Image, b.Image)
+	})
+	return results
+}
+
+// sourceClient returns a client for the source registry of the given ImageSync.
+func (r *ImageSyncReconciler) sourceClient(
+	ctx context.Context,
+	imageSync *registryv1alpha1.ImageSync,
+) (*registryclient.Client, error) {
+	source := imageSync.Spec.Source
+	baseURL := source.URL
+	if source.RegistryRef != nil {
+		var err error
+		key := client.ObjectKey{Namespace: imageSync.Namespace, Name: source.RegistryRef.Name}
+		if baseURL, err = registryURL(ctx, r.Client, key); err != nil {
+			return nil, err
+		}
+	}
+
+	credentials, err := registryCredentials(ctx, r.Client, imageSync.Namespace, source.CredentialsSecretRef, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return registryclient.New(baseURL, r.HTTPClient, credentials)
+}
+
+// targetClient returns a client for the target Registry of the given ImageSync.
+func (r *ImageSyncReconciler) targetClient(
+	ctx context.Context,
+	imageSync *registryv1alpha1.ImageSync,
+) (*registryclient.Client, error) {
+	target := imageSync.Spec.Target
+	key := client.ObjectKey{Namespace: imageSync.Namespace, Name: target.RegistryRef.Name}
+	baseURL, err := registryURL(ctx, r.Client, key)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := registryCredentials(ctx, r.Client, imageSync.Namespace, target.CredentialsSecretRef, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return registryclient.New(baseURL, r.HTTPClient, credentials)
+}
+
+// matchesAny reports whether the tag matches any of the given expressions, or whether there is none.
+func matchesAny(filters []*regexp.Regexp, tag string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(filters, func(re *regexp.Regexp) bool {
+		return re.MatchString(tag)
+	})
+}
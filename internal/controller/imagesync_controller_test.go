@@ -0,0 +1,246 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/registryclient"
+	"github.com/registry-operator/registry-operator/internal/registryclient/registrytest"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestImageSyncReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	sourceServer := registrytest.NewServer(t)
+	targetServer := registrytest.NewServer(t)
+	created := time.Now().Add(-24 * time.Hour)
+	registrytest.Push(t, sourceServer, "library/nginx", "1.27", created)
+	registrytest.Push(t, sourceServer, "library/nginx", "1.27.1", created.Add(time.Hour))
+	registrytest.Push(t, sourceServer, "library/nginx", "latest", created.Add(time.Hour))
+
+	registry := &registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry", Namespace: "default"},
+		Status: registryv1alpha1.RegistryStatus{
+			Endpoints: []registryv1alpha1.Endpoint{
+				{Type: registryv1alpha1.EndpointTypeClusterIP, Address: targetServer.Listener.Addr().String()},
+			},
+		},
+	}
+	imageSync := &registryv1alpha1.ImageSync{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default", Generation: 1},
+		Spec: registryv1alpha1.ImageSyncSpec{
+			Source: registryv1alpha1.ImageSyncSource{URL: sourceServer.URL},
+			Target: registryv1alpha1.ImageSyncTarget{
+				RegistryRef:      corev1.LocalObjectReference{Name: registry.Name},
+				RepositoryPrefix: "mirror/",
+			},
+			Images: []registryv1alpha1.ImageFilter{
+				{Repository: "library/nginx", Tags: []string{`^1\.27`}},
+			},
+			Schedule: "@hourly",
+		},
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&registryv1alpha1.ImageSync{}).
+		WithObjects(registry, imageSync).
+		Build()
+	r := &ImageSyncReconciler{
+		Client:   cli,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(imageSync)}
+
+	targetClient, err := registryclient.New(targetServer.URL, nil, nil)
+	require.NoError(t, err)
+
+	t.Run("should copy the selected tags", func(t *testing.T) {
+		result, err := r.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+		assert.Greater(t, result.RequeueAfter, time.Duration(0))
+		assert.LessOrEqual(t, result.RequeueAfter, time.Hour)
+
+		tags, err := targetClient.Tags(t.Context(), "mirror/library/nginx")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"1.27", "1.27.1"}, tags)
+
+		actual := &registryv1alpha1.ImageSync{}
+		require.NoError(t, cli.Get(t.Context(), req.NamespacedName, actual))
+		require.Len(t, actual.Status.Images, 2)
+		assert.Equal(t, "mirror/library/nginx:1.27", actual.Status.Images[0].Image)
+		assert.Equal(t, registryv1alpha1.ImageSyncResultSynced, actual.Status.Images[0].Status)
+		assert.NotNil(t, actual.Status.LastSyncTime)
+		assert.NotNil(t, actual.Status.NextSyncTime)
+		assert.True(t, meta.IsStatusConditionTrue(actual.Status.Conditions, registryv1alpha1.ConditionTypeReady))
+	})
+
+	t.Run("should wait for the next scheduled sync", func(t *testing.T) {
+		before := &registryv1alpha1.ImageSync{}
+		require.NoError(t, cli.Get(t.Context(), req.NamespacedName, before))
+
+		result, err := r.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+		assert.Greater(t, result.RequeueAfter, time.Duration(0))
+
+		actual := &registryv1alpha1.ImageSync{}
+		require.NoError(t, cli.Get(t.Context(), req.NamespacedName, actual))
+		assert.Equal(t, before.Status, actual.Status)
+	})
+
+	t.Run("should report up to date images", func(t *testing.T) {
+		actual := &registryv1alpha1.ImageSync{}
+		require.NoError(t, cli.Get(t.Context(), req.NamespacedName, actual))
+		patch := client.MergeFrom(actual.DeepCopy())
+		actual.Status.LastSyncTime = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+		require.NoError(t, cli.Status().Patch(t.Context(), actual, patch))
+
+		_, err := r.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+
+		require.NoError(t, cli.Get(t.Context(), req.NamespacedName, actual))
+		require.Len(t, actual.Status.Images, 2)
+		for _, image := range actual.Status.Images {
+			assert.Equal(t, registryv1alpha1.ImageSyncResultUpToDate, image.Status)
+		}
+	})
+
+	t.Run("should copy the images over several reconciliations", func(t *testing.T) {
+		batched := imageSync.DeepCopy()
+		batched.Name = "batched"
+		batched.ResourceVersion = ""
+		batched.Spec.Target.RepositoryPrefix = "batched/"
+		require.NoError(t, cli.Create(t.Context(), batched))
+		r := &ImageSyncReconciler{
+			Client:             cli,
+			Scheme:             scheme,
+			Recorder:           record.NewFakeRecorder(10),
+			CopiesPerReconcile: 1,
+		}
+		key := client.ObjectKeyFromObject(batched)
+
+		result, err := r.Reconcile(t.Context(), ctrl.Request{NamespacedName: key})
+		require.NoError(t, err)
+		assert.Equal(t, imageSyncResumeDelay, result.RequeueAfter)
+
+		actual := &registryv1alpha1.ImageSync{}
+		require.NoError(t, cli.Get(t.Context(), key, actual))
+		assert.Equal(t, &registryv1alpha1.ImageSyncSummary{Synced: 1, Pending: 1}, actual.Status.Summary)
+		assert.Equal(t, "batched/library/nginx:1.27", actual.Status.ResumeAfter)
+		assert.Nil(t, actual.Status.LastSyncTime)
+		condition := meta.FindStatusCondition(actual.Status.Conditions, registryv1alpha1.ConditionTypeReady)
+		require.NotNil(t, condition)
+		assert.Equal(t, reasonSyncing, condition.Reason)
+
+		result, err = r.Reconcile(t.Context(), ctrl.Request{NamespacedName: key})
+		require.NoError(t, err)
+		assert.Greater(t, result.RequeueAfter, imageSyncResumeDelay)
+
+		require.NoError(t, cli.Get(t.Context(), key, actual))
+		assert.Equal(t, &registryv1alpha1.ImageSyncSummary{Synced: 2}, actual.Status.Summary)
+		assert.Empty(t, actual.Status.ResumeAfter)
+		require.Len(t, actual.Status.Images, 2)
+		assert.NotNil(t, actual.Status.LastSyncTime)
+		assert.True(t, meta.IsStatusConditionTrue(actual.Status.Conditions, registryv1alpha1.ConditionTypeReady))
+
+		tags, err := targetClient.Tags(t.Context(), "batched/library/nginx")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"1.27", "1.27.1"}, tags)
+	})
+
+	t.Run("should report an invalid schedule", func(t *testing.T) {
+		invalid := imageSync.DeepCopy()
+		invalid.Name = "invalid"
+		invalid.ResourceVersion = ""
+		invalid.Spec.Schedule = "every hour"
+		require.NoError(t, cli.Create(t.Context(), invalid))
+
+		result, err := r.Reconcile(t.Context(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(invalid)})
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+
+		actual := &registryv1alpha1.ImageSync{}
+		require.NoError(t, cli.Get(t.Context(), client.ObjectKeyFromObject(invalid), actual))
+		condition := meta.FindStatusCondition(actual.Status.Conditions, registryv1alpha1.ConditionTypeReady)
+		require.NotNil(t, condition)
+		assert.Equal(t, reasonInvalidSchedule, condition.Reason)
+	})
+
+	t.Run("should report missing credentials", func(t *testing.T) {
+		unauthenticated := imageSync.DeepCopy()
+		unauthenticated.Name = "unauthenticated"
+		unauthenticated.ResourceVersion = ""
+		unauthenticated.Spec.Source.CredentialsSecretRef = &corev1.LocalObjectReference{Name: "missing"}
+		require.NoError(t, cli.Create(t.Context(), unauthenticated))
+
+		key := client.ObjectKeyFromObject(unauthenticated)
+		_, err := r.Reconcile(t.Context(), ctrl.Request{NamespacedName: key})
+		require.NoError(t, err)
+
+		actual := &registryv1alpha1.ImageSync{}
+		require.NoError(t, cli.Get(t.Context(), key, actual))
+		condition := meta.FindStatusCondition(actual.Status.Conditions, registryv1alpha1.ConditionTypeReady)
+		require.NotNil(t, condition)
+		assert.Equal(t, reasonInvalidCredentials, condition.Reason)
+		assert.Nil(t, actual.Status.LastSyncTime)
+	})
+}
+
+func TestCapImageSyncResults(t *testing.T) {
+	var results []registryv1alpha1.ImageSyncResult
+	for i := range maxImageSyncResults + 10 {
+		result := registryv1alpha1.ImageSyncResult{
+			Image:  fmt.Sprintf("library/nginx:%03d", i),
+			Status: registryv1alpha1.ImageSyncResultSynced,
+		}
+		if i >= maxImageSyncResults {
+			result.Status = registryv1alpha1.ImageSyncResultFailed
+		}
+		results = append(results, result)
+	}
+
+	actual := capImageSyncResults(results)
+	require.Len(t, actual, maxImageSyncResults)
+	assert.True(t, slices.IsSortedFunc(actual, func(a, b registryv1alpha1.ImageSyncResult) int {
+		return strings.Compare(a.Image, b.Image)
+	}))
+	failed := slices.DeleteFunc(slices.Clone(actual), func(result registryv1alpha1.ImageSyncResult) bool {
+		return result.Status != registryv1alpha1.ImageSyncResultFailed
+	})
+	assert.Len(t, failed, 10)
+}
@@ -0,0 +1,256 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/robfig/cron/v3"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/registryclient"
+	registryupgrade "github.com/registry-operator/registry-operator/internal/upgrade/registry"
+	"github.com/registry-operator/registry-operator/internal/version"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	reasonImageUpdated = "ImageUpdated"
+
+	defaultUpdateInterval = time.Hour
+	dockerHubDomain       = "docker.io"
+	dockerHubURL          = "https://registry-1.docker.io"
+)
+
+// updateImage resolves the update channel of the given Registry when its interval elapsed, and rolls out its
+// newest release inside the maintenance window, recording the decision in the status of the Registry, which is
+// updated in place. It returns the time left until the next resolution or the start of the maintenance window.
+func (r *RegistryReconciler) updateImage(
+	ctx context.Context,
+	instance *registryv1alpha1.Registry,
+) (time.Duration, error) {
+	policy := instance.Spec.UpdatePolicy
+	if policy == nil {
+		if instance.Status.ImageUpdate == nil {
+			return 0, nil
+		}
+		changed := instance.DeepCopy()
+		changed.Status.ImageUpdate = nil
+		return 0, r.patchImageUpdate(ctx, instance, changed)
+	}
+
+	interval := defaultUpdateInterval
+	if policy.Interval != nil && policy.Interval.Duration > 0 {
+		interval = policy.Interval.Duration
+	}
+	repository := updateRepository(policy)
+
+	changed := instance.DeepCopy()
+	status := changed.Status.ImageUpdate
+	if status == nil {
+		status = &registryv1alpha1.ImageUpdate{}
+		changed.Status.ImageUpdate = status
+	}
+
+	now := time.Now()
+	next := status.LastCheckTime.Add(interval).Sub(now)
+	if status.Channel != policy.Channel || !strings.HasPrefix(status.LatestImage, repository+":") || next <= 0 {
+		next = interval
+		status.Channel = policy.Channel
+		status.LastCheckTime = metav1.NewTime(now)
+		tag, err := r.resolveChannel(ctx, instance, policy, repository)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to resolve the update channel", "channel", policy.Channel)
+			status.LatestImage = ""
+			setImageUpdate(status, registryv1alpha1.ImageUpdateFailed, err.Error())
+			return next, r.patchImageUpdate(ctx, instance, changed)
+		}
+		status.LatestImage = repository + ":" + tag
+	} else if status.Decision == registryv1alpha1.ImageUpdateFailed {
+		// retried once the interval elapsed
+		return next, nil
+	}
+
+	wait, err := r.rollOutRelease(ctx, changed, policy.MaintenanceWindow, now)
+	if err != nil {
+		setImageUpdate(status, registryv1alpha1.ImageUpdateFailed, err.Error())
+	}
+	if wait > 0 && wait < next {
+		next = wait
+	}
+	return next, r.patchImageUpdate(ctx, instance, changed)
+}
+
+// rollOutRelease rolls out the newest release of the channel recorded in the status of the given Registry when
+// it is not older than the version of the Registry, and the maintenance window is open. The version of the
+// Registry is brought to the release by the upgrade routines. It returns the time left until the maintenance
+// window opens when the release is deferred.
+func (r *RegistryReconciler) rollOutRelease(
+	ctx context.Context,
+	instance *registryv1alpha1.Registry,
+	window *registryv1alpha1.MaintenanceWindow,
+	now time.Time,
+) (time.Duration, error) {
+	status := instance.Status.ImageUpdate
+	latest := status.LatestImage
+	if latest == status.Image {
+		setImageUpdate(status, registryv1alpha1.ImageUpdateUpToDate,
+			fmt.Sprintf("%s is the newest release of channel %s", latest, status.Channel))
+		return 0, nil
+	}
+
+	tag := latest[strings.LastIndex(latest, ":")+1:]
+	if current := instance.Status.Version; current != "" {
+		cmp, err := registryupgrade.CompareVersions(current, tag)
+		if err != nil {
+			return 0, err
+		}
+		if cmp < 0 {
+			setImageUpdate(status, registryv1alpha1.ImageUpdateRejected,
+				fmt.Sprintf("%s is older than the version %s of the Registry", latest, current))
+			return 0, nil
+		}
+	}
+
+	if window != nil {
+		open, start, err := maintenanceWindow(window, now)
+		if err != nil {
+			return 0, err
+		}
+		if !open {
+			setImageUpdate(status, registryv1alpha1.ImageUpdateDeferred, fmt.Sprintf(
+				"%s is rolled out in the maintenance window starting at %s", latest, start.UTC().Format(time.RFC3339),
+			))
+			return start.Sub(now), nil
+		}
+	}
+
+	if instance.Status.Version == "" {
+		// this is a new instance, there is nothing to upgrade
+		instance.Status.Version = tag
+	} else {
+		up := registryupgrade.VersionUpgrade{
+			Client:   r.Client,
+			Recorder: r.Recorder,
+			Version:  version.Version{Registry: tag},
+		}
+		if err := up.Upgrade(ctx, instance); err != nil {
+			return 0, err
+		}
+		// the upgrade updates the instance in place
+		status = instance.Status.ImageUpdate
+		if instance.Status.Version != tag {
+			setImageUpdate(status, registryv1alpha1.ImageUpdateHeld,
+				fmt.Sprintf("%s is held by the upgrade annotations", latest))
+			return 0, nil
+		}
+	}
+
+	status.Image = latest
+	status.LastUpdateTime = &metav1.Time{Time: now}
+	setImageUpdate(status, registryv1alpha1.ImageUpdateUpdated,
+		fmt.Sprintf("Rolled out %s, the newest release of channel %s", latest, status.Channel))
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, reasonImageUpdated, "Updated the image to %s", latest)
+	return 0, nil
+}
+
+// resolveChannel lists the tags of the source repository of the given update policy, and returns the newest
+// release of its channel.
+func (r *RegistryReconciler) resolveChannel(
+	ctx context.Context,
+	instance *registryv1alpha1.Registry,
+	policy *registryv1alpha1.UpdatePolicy,
+	repository string,
+) (string, error) {
+	named, err := reference.ParseNormalizedNamed(repository)
+	if err != nil {
+		return "", fmt.Errorf("invalid source repository: %w", err)
+	}
+
+	baseURL := "https://" + reference.Domain(named)
+	if reference.Domain(named) == dockerHubDomain {
+		baseURL = dockerHubURL
+	}
+	var credentialsRef *corev1.LocalObjectReference
+	if source := policy.Source; source != nil {
+		if source.URL != "" {
+			baseURL = source.URL
+		}
+		credentialsRef = source.CredentialsSecretRef
+	}
+
+	credentials, err := registryCredentials(ctx, r.Client, instance.Namespace, credentialsRef, baseURL)
+	if err != nil {
+		return "", err
+	}
+	c, err := registryclient.New(baseURL, r.HTTPClient, credentials)
+	if err != nil {
+		return "", err
+	}
+	tags, err := c.Tags(ctx, reference.Path(named))
+	if err != nil {
+		return "", fmt.Errorf("failed to list the tags of %s: %w", repository, err)
+	}
+	return registryupgrade.LatestInChannel(policy.Channel, tags)
+}
+
+// patchImageUpdate patches the status of the given Registry into the changed one, and updates it in place.
+func (r *RegistryReconciler) patchImageUpdate(
+	ctx context.Context,
+	instance *registryv1alpha1.Registry,
+	changed *registryv1alpha1.Registry,
+) error {
+	if err := r.Status().Patch(ctx, changed, client.MergeFrom(instance)); err != nil {
+		return fmt.Errorf("failed to record the image update: %w", err)
+	}
+	*instance = *changed
+	return nil
+}
+
+// updateRepository returns the repository the releases of the given update policy are resolved from.
+func updateRepository(policy *registryv1alpha1.UpdatePolicy) string {
+	if source := policy.Source; source != nil && source.Repository != "" {
+		return source.Repository
+	}
+	return version.GetRegistryRepository()
+}
+
+// maintenanceWindow reports whether the given maintenance window is open, and otherwise returns the time it
+// opens next.
+func maintenanceWindow(window *registryv1alpha1.MaintenanceWindow, now time.Time) (bool, time.Time, error) {
+	schedule, err := cron.ParseStandard(window.Schedule)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid maintenance window schedule: %w", err)
+	}
+	// the window is open when it started less than its duration ago
+	if start := schedule.Next(now.Add(-window.Duration.Duration)); !start.After(now) {
+		return true, start, nil
+	}
+	return false, schedule.Next(now), nil
+}
+
+// setImageUpdate records the given decision.
+func setImageUpdate(status *registryv1alpha1.ImageUpdate, decision registryv1alpha1.ImageUpdateDecision, msg string) {
+	status.Decision = decision
+	status.Message = msg
+}
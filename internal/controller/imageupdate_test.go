@@ -0,0 +1,192 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/registryclient/registrytest"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateImage(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	server := registrytest.NewServer(t)
+	created := time.Now().Add(-24 * time.Hour)
+	for _, tag := range []string{"2.8.3", "3.0", "3.0.0", "3.0.1", "3.1.0", "latest"} {
+		registrytest.Push(t, server, "library/registry", tag, created)
+	}
+
+	const repository = "registry.example.com/library/registry"
+	instance := &registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry", Namespace: "default"},
+		Spec: registryv1alpha1.RegistrySpec{
+			UpdatePolicy: &registryv1alpha1.UpdatePolicy{
+				Channel: "3.0.x",
+				Source:  &registryv1alpha1.UpdateSource{Repository: repository, URL: server.URL},
+				// a window opening on New Year's Day only
+				MaintenanceWindow: &registryv1alpha1.MaintenanceWindow{
+					Schedule: "0 0 1 1 *",
+					Duration: metav1.Duration{Duration: time.Minute},
+				},
+			},
+		},
+		Status: registryv1alpha1.RegistryStatus{Version: "3.0.0"},
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&registryv1alpha1.Registry{}).
+		WithObjects(instance).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &RegistryReconciler{Client: cli, Scheme: scheme, Recorder: recorder}
+
+	reg := &registryv1alpha1.Registry{}
+	require.NoError(t, cli.Get(t.Context(), client.ObjectKeyFromObject(instance), reg))
+
+	assertStored := func(t *testing.T) {
+		t.Helper()
+		stored := &registryv1alpha1.Registry{}
+		require.NoError(t, cli.Get(t.Context(), client.ObjectKeyFromObject(instance), stored))
+		assert.Equal(t, reg.Status.ImageUpdate, stored.Status.ImageUpdate)
+		assert.Equal(t, reg.Status.Version, stored.Status.Version)
+	}
+
+	t.Run("should defer the newest release to the maintenance window", func(t *testing.T) {
+		wait, err := r.updateImage(t.Context(), reg)
+		require.NoError(t, err)
+		assert.Positive(t, wait)
+		assert.LessOrEqual(t, wait, time.Hour)
+
+		update := reg.Status.ImageUpdate
+		require.NotNil(t, update)
+		assert.Equal(t, registryv1alpha1.ImageUpdateDeferred, update.Decision)
+		assert.Equal(t, repository+":3.0.1", update.LatestImage)
+		assert.Empty(t, update.Image)
+		assert.Equal(t, "3.0.0", reg.Status.Version)
+		assertStored(t)
+	})
+
+	t.Run("should roll out the newest release inside the maintenance window", func(t *testing.T) {
+		reg.Spec.UpdatePolicy.MaintenanceWindow = &registryv1alpha1.MaintenanceWindow{
+			Schedule: "* * * * *",
+			Duration: metav1.Duration{Duration: 2 * time.Minute},
+		}
+
+		wait, err := r.updateImage(t.Context(), reg)
+		require.NoError(t, err)
+		assert.Positive(t, wait)
+		assert.LessOrEqual(t, wait, time.Hour)
+
+		update := reg.Status.ImageUpdate
+		assert.Equal(t, registryv1alpha1.ImageUpdateUpdated, update.Decision)
+		assert.Equal(t, repository+":3.0.1", update.Image)
+		assert.NotNil(t, update.LastUpdateTime)
+		assert.Equal(t, "3.0.1", reg.Status.Version)
+		require.Len(t, reg.Status.UpgradeHistory, 1)
+		assert.Equal(t, "3.0.0", reg.Status.UpgradeHistory[0].From)
+		assert.Equal(t, "3.0.1", reg.Status.UpgradeHistory[0].To)
+		assert.Contains(t, <-recorder.Events, reasonImageUpdated)
+		assertStored(t)
+
+		params, err := r.GetParams(t.Context(), *reg)
+		require.NoError(t, err)
+		assert.Equal(t, repository+":3.0.1", params.Registry.Spec.Image)
+	})
+
+	t.Run("should report the newest release as up to date", func(t *testing.T) {
+		_, err := r.updateImage(t.Context(), reg)
+		require.NoError(t, err)
+		assert.Equal(t, registryv1alpha1.ImageUpdateUpToDate, reg.Status.ImageUpdate.Decision)
+	})
+
+	t.Run("should reject a release older than the Registry", func(t *testing.T) {
+		reg.Spec.UpdatePolicy.Channel = "2.8.x"
+
+		_, err := r.updateImage(t.Context(), reg)
+		require.NoError(t, err)
+		update := reg.Status.ImageUpdate
+		assert.Equal(t, registryv1alpha1.ImageUpdateRejected, update.Decision)
+		assert.Equal(t, repository+":2.8.3", update.LatestImage)
+		assert.Equal(t, repository+":3.0.1", update.Image)
+		assert.Equal(t, "3.0.1", reg.Status.Version)
+		assertStored(t)
+	})
+
+	t.Run("should record a channel without release", func(t *testing.T) {
+		reg.Spec.UpdatePolicy.Channel = "4.0.x"
+
+		_, err := r.updateImage(t.Context(), reg)
+		require.NoError(t, err)
+		update := reg.Status.ImageUpdate
+		assert.Equal(t, registryv1alpha1.ImageUpdateFailed, update.Decision)
+		assert.Contains(t, update.Message, "no release of channel 4.0.x")
+		assert.Equal(t, repository+":3.0.1", update.Image)
+		assertStored(t)
+	})
+
+	t.Run("should clear the status without update policy", func(t *testing.T) {
+		reg.Spec.UpdatePolicy = nil
+
+		wait, err := r.updateImage(t.Context(), reg)
+		require.NoError(t, err)
+		assert.Zero(t, wait)
+		assert.Nil(t, reg.Status.ImageUpdate)
+		assertStored(t)
+	})
+}
+
+func TestMaintenanceWindow(t *testing.T) {
+	window := &registryv1alpha1.MaintenanceWindow{
+		Schedule: "0 2 * * *",
+		Duration: metav1.Duration{Duration: 2 * time.Hour},
+	}
+	day := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local)
+
+	for _, tt := range []struct {
+		desc  string
+		now   time.Time
+		open  bool
+		start time.Time
+	}{
+		{desc: "before", now: day.Add(time.Hour), start: day.Add(2 * time.Hour)},
+		{desc: "at the start", now: day.Add(2 * time.Hour), open: true, start: day.Add(2 * time.Hour)},
+		{desc: "inside", now: day.Add(3 * time.Hour), open: true, start: day.Add(2 * time.Hour)},
+		{desc: "after", now: day.Add(4 * time.Hour), start: day.Add(26 * time.Hour)},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			open, start, err := maintenanceWindow(window, tt.now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.open, open)
+			assert.True(t, tt.start.Equal(start), "expected %s, got %s", tt.start, start)
+		})
+	}
+}
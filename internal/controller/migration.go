@@ -0,0 +1,138 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/registry"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const reasonStorageMigrationStarted = "StorageMigrationStarted"
+
+// setMigrationParams sets the migration of the given Registry when its storage switched between a
+// PersistentVolumeClaim and S3 with the Migrate strategy.
+// The Registry serves the images from the storage it served so far, in read-only mode while the migration Job
+// runs, and switches to its new storage once the Job succeeded.
+func (r *RegistryReconciler) setMigrationParams(
+	ctx context.Context,
+	params *manifests.Params,
+	served *registryv1alpha1.Storage,
+) error {
+	reg := &params.Registry
+	if reg.Spec.StorageUpdateStrategy != registryv1alpha1.StorageUpdateStrategyMigrate || served == nil ||
+		!registry.IsStorageMigration(*served, reg.Spec.Storage) {
+		return nil
+	}
+
+	m := &manifests.StorageMigration{
+		Source: *served,
+		Target: reg.Spec.Storage,
+		Phase:  manifests.StorageMigrationPending,
+	}
+	params.Migration = m
+	reg.Spec.Storage = m.Source
+
+	hash, err := registry.MigrationHash(m)
+	if err != nil {
+		return err
+	}
+	job := &batchv1.Job{}
+	key := client.ObjectKey{Namespace: reg.Namespace, Name: naming.Migration(reg.Name)}
+	if err := r.Get(ctx, key, job); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to get migration Job: %w", err)
+	} else if apierrors.IsNotFound(err) || job.Annotations[registry.MigrationAnnotation] != hash ||
+		job.GetDeletionTimestamp() != nil {
+		// a Job migrating other storages is replaced
+		params.ReadOnly = true
+		return nil
+	}
+	if !metav1.IsControlledBy(job, reg) {
+		return fmt.Errorf("%w: job %s is not managed by the Registry", manifests.ErrInvalidConfig, job.Name)
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			m.Phase = manifests.StorageMigrationSucceeded
+			reg.Spec.Storage = m.Target
+			return nil
+		case batchv1.JobFailed:
+			m.Phase = manifests.StorageMigrationFailed
+			if condition.Reason == batchv1.JobReasonPodFailurePolicy {
+				m.Phase = manifests.StorageMigrationVerificationFailed
+			}
+			m.Message = fmt.Sprintf("Job %s failed: %s", job.Name, condition.Message)
+			return nil
+		}
+	}
+
+	m.Phase = manifests.StorageMigrationRunning
+	params.ReadOnly = true
+	return nil
+}
+
+// runStorageMigration starts the migration Job of the Registry once every replica serves in read-only mode,
+// after deleting the Job left by a previous migration.
+func (r *RegistryReconciler) runStorageMigration(ctx context.Context, params manifests.Params) error {
+	m := params.Migration
+	if m == nil || m.Phase != manifests.StorageMigrationPending || !params.ReadOnlyReady {
+		return nil
+	}
+	reg := &params.Registry
+
+	job, err := registry.MigrationJob(params)
+	if err != nil {
+		return err
+	}
+	if err := ctrl.SetControllerReference(reg, job, params.Scheme); err != nil {
+		return err
+	}
+
+	existing := &batchv1.Job{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(job), existing); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to get migration Job: %w", err)
+	} else if err == nil {
+		// the Registry is reconciled again once the Job is deleted
+		if existing.GetDeletionTimestamp() != nil || !metav1.IsControlledBy(existing, reg) {
+			return nil
+		}
+		err := r.Delete(ctx, existing, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		return client.IgnoreNotFound(err)
+	}
+
+	if err := r.Create(ctx, job); err != nil {
+		return fmt.Errorf("failed to create migration Job: %w", err)
+	}
+	log.FromContext(ctx).Info("Migrating the storage", "job", job.Name)
+	r.Recorder.Eventf(reg, corev1.EventTypeNormal, reasonStorageMigrationStarted,
+		"Job %s is migrating the images to the new storage", job.Name)
+	return nil
+}
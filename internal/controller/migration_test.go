@@ -0,0 +1,143 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStorageMigration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	selector := func(key string) *registryv1alpha1.SecretKeySelector {
+		return &registryv1alpha1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "s3"},
+			Key:                  key,
+		}
+	}
+	claim := registryv1alpha1.Storage{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "my-claim"},
+	}
+	bucket := registryv1alpha1.Storage{S3: &registryv1alpha1.S3StorageSource{
+		BucketName: *selector("bucket"),
+		Region:     *selector("region"),
+		AccessKey:  selector("accessKey"),
+		SecretKey:  selector("secretKey"),
+	}}
+	reg := registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry", Namespace: "default", UID: "uid"},
+		Spec: registryv1alpha1.RegistrySpec{
+			Storage:               bucket,
+			StorageUpdateStrategy: registryv1alpha1.StorageUpdateStrategyMigrate,
+		},
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&batchv1.Job{}).
+		Build()
+	r := &RegistryReconciler{Client: cli, Scheme: scheme, Recorder: record.NewFakeRecorder(10)}
+
+	t.Run("serves the previous storage in read-only mode before migrating", func(t *testing.T) {
+		params := manifests.Params{Registry: reg}
+		require.NoError(t, r.setMigrationParams(t.Context(), &params, &claim))
+		require.NotNil(t, params.Migration)
+		assert.Equal(t, manifests.StorageMigrationPending, params.Migration.Phase)
+		assert.Equal(t, claim, params.Registry.Spec.Storage)
+		assert.True(t, params.ReadOnly)
+
+		// the Job waits for every replica to be read-only
+		require.NoError(t, r.runStorageMigration(t.Context(), params))
+		assert.Error(t, cli.Get(t.Context(), jobKey(), &batchv1.Job{}))
+	})
+
+	t.Run("runs the migration Job", func(t *testing.T) {
+		params := manifests.Params{Registry: reg, OperatorImage: "operator:dev", Scheme: scheme}
+		require.NoError(t, r.setMigrationParams(t.Context(), &params, &claim))
+		params.ReadOnlyReady = true
+		require.NoError(t, r.runStorageMigration(t.Context(), params))
+
+		job := &batchv1.Job{}
+		require.NoError(t, cli.Get(t.Context(), jobKey(), job))
+		assert.True(t, metav1.IsControlledBy(job, &reg))
+
+		params = manifests.Params{Registry: reg}
+		require.NoError(t, r.setMigrationParams(t.Context(), &params, &claim))
+		assert.Equal(t, manifests.StorageMigrationRunning, params.Migration.Phase)
+		assert.True(t, params.ReadOnly)
+	})
+
+	t.Run("keeps serving the previous storage when the verification failed", func(t *testing.T) {
+		setJobCondition(t, cli, batchv1.JobFailed, batchv1.JobReasonPodFailurePolicy)
+
+		params := manifests.Params{Registry: reg}
+		require.NoError(t, r.setMigrationParams(t.Context(), &params, &claim))
+		assert.Equal(t, manifests.StorageMigrationVerificationFailed, params.Migration.Phase)
+		assert.Equal(t, claim, params.Registry.Spec.Storage)
+		assert.False(t, params.ReadOnly)
+	})
+
+	t.Run("serves the new storage once migrated", func(t *testing.T) {
+		setJobCondition(t, cli, batchv1.JobComplete, "")
+
+		params := manifests.Params{Registry: reg}
+		require.NoError(t, r.setMigrationParams(t.Context(), &params, &claim))
+		assert.Equal(t, manifests.StorageMigrationSucceeded, params.Migration.Phase)
+		assert.Equal(t, bucket, params.Registry.Spec.Storage)
+		assert.False(t, params.ReadOnly)
+
+		params = manifests.Params{Registry: reg}
+		require.NoError(t, r.setMigrationParams(t.Context(), &params, &bucket))
+		assert.Nil(t, params.Migration)
+	})
+
+	t.Run("replaces the storage without the Migrate strategy", func(t *testing.T) {
+		params := manifests.Params{Registry: *reg.DeepCopy()}
+		params.Registry.Spec.StorageUpdateStrategy = registryv1alpha1.StorageUpdateStrategyReplace
+		require.NoError(t, r.setMigrationParams(t.Context(), &params, &claim))
+		assert.Nil(t, params.Migration)
+		assert.Equal(t, bucket, params.Registry.Spec.Storage)
+	})
+}
+
+func jobKey() client.ObjectKey {
+	return client.ObjectKey{Namespace: "default", Name: "my-registry-registry-migration"}
+}
+
+func setJobCondition(t *testing.T, cli client.Client, conditionType batchv1.JobConditionType, reason string) {
+	t.Helper()
+	job := &batchv1.Job{}
+	require.NoError(t, cli.Get(t.Context(), jobKey(), job))
+	job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue, Reason: reason}}
+	require.NoError(t, cli.Status().Update(t.Context(), job))
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// errNodeMirrorRemoving is returned while the Pods of the node mirror DaemonSet clean up the nodes.
+var errNodeMirrorRemoving = errors.New("waiting for the node mirror configuration to be removed from the nodes")
+
+// removeNodeMirror deletes the DaemonSet configuring the nodes to use the Registry as a mirror. Its Pods remove
+// the host configurations they wrote as they are stopped, so the DaemonSet is deleted in the foreground.
+// It reports whether the DaemonSet is gone.
+func (r *RegistryReconciler) removeNodeMirror(ctx context.Context, registry *registryv1alpha1.Registry) (bool, error) {
+	ds := &appsv1.DaemonSet{}
+	key := client.ObjectKey{Namespace: registry.Namespace, Name: naming.NodeMirror(registry.Name)}
+	if err := r.Get(ctx, key, ds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get node mirror DaemonSet: %w", err)
+	}
+	if !metav1.IsControlledBy(ds, registry) {
+		return true, nil
+	}
+	if ds.GetDeletionTimestamp() != nil {
+		return false, nil
+	}
+
+	err := r.Delete(ctx, ds, client.PropagationPolicy(metav1.DeletePropagationForeground))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to delete node mirror DaemonSet: %w", err)
+	}
+	log.FromContext(ctx).V(1).Info("Node mirror DaemonSet has been deleted")
+	return apierrors.IsNotFound(err), nil
+}
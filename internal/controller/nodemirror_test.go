@@ -0,0 +1,79 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRemoveNodeMirror(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	registry := &registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry", Namespace: "default", UID: "uid"},
+	}
+	owned := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry-registry-node-mirror", Namespace: "default"},
+	}
+	require.NoError(t, ctrl.SetControllerReference(registry, owned, scheme))
+
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(owned).Build()
+	r := &RegistryReconciler{
+		Client:   cli,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	params := manifests.Params{Client: cli, Registry: *registry, Scheme: scheme}
+
+	t.Run("waits for the DaemonSet to be deleted", func(t *testing.T) {
+		assert.ErrorIs(t, r.finalizeRegistry(t.Context(), params), errNodeMirrorRemoving)
+
+		err := cli.Get(t.Context(), client.ObjectKeyFromObject(owned), &appsv1.DaemonSet{})
+		assert.True(t, apierrors.IsNotFound(err))
+
+		assert.NoError(t, r.finalizeRegistry(t.Context(), params))
+	})
+
+	t.Run("leaves foreign DaemonSets alone", func(t *testing.T) {
+		foreign := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-registry-registry-node-mirror", Namespace: "default"},
+		}
+		require.NoError(t, cli.Create(t.Context(), foreign))
+
+		gone, err := r.removeNodeMirror(t.Context(), registry)
+		require.NoError(t, err)
+		assert.True(t, gone)
+		assert.NoError(t, cli.Get(t.Context(), client.ObjectKeyFromObject(foreign), &appsv1.DaemonSet{}))
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const reasonClaimRetained = "PersistentVolumeClaimRetained"
+
+// keepConfigSecretHistory removes from the given Secrets of the Registry, to be pruned, the most recent
+// configuration Secrets: the current configuration and the previous ones kept by the revision history limit,
+// which the ReplicaSets of the Deployment may be rolled back to. The Secret of the last known good revision is
+// kept for the automatic rollbacks. The other Secrets are left to be pruned.
+func keepConfigSecretHistory(secrets map[types.UID]client.Object, registry *registryv1alpha1.Registry) {
+	limit := int(ptr.Deref(registry.Spec.RevisionHistoryLimit, registryv1alpha1.DefaultRevisionHistoryLimit))
+
+	configs := maps.Clone(secrets)
+	maps.DeleteFunc(configs, func(_ types.UID, secret client.Object) bool {
+		return !naming.IsSecret(registry.Name, secret.GetName())
+	})
+	newest := slices.SortedFunc(maps.Values(configs), func(a, b client.Object) int {
+		return cmp.Or(
+			b.GetCreationTimestamp().Compare(a.GetCreationTimestamp().Time),
+			cmp.Compare(a.GetName(), b.GetName()),
+		)
+	})
+	for _, secret := range newest[:min(limit+1, len(newest))] {
+		delete(secrets, secret.GetUID())
+	}
+	if good := registry.Status.LastKnownGoodRevision; good != nil {
+		maps.DeleteFunc(secrets, func(_ types.UID, secret client.Object) bool {
+			return secret.GetName() == good.ConfigSecret
+		})
+	}
+}
+
+// releasePersistentVolumeClaims applies the retention policy of the Registry to the PersistentVolumeClaims
+// among the given owned objects once the storage no longer uses them. Retained claims are removed from the
+// objects to prune and released from the Registry, so that they also outlive it.
+func (r *RegistryReconciler) releasePersistentVolumeClaims(
+	ctx context.Context,
+	registry *registryv1alpha1.Registry,
+	owned map[types.UID]client.Object,
+) error {
+	if registry.Spec.Storage.PersistentVolumeClaimTemplate != nil ||
+		registry.Spec.PersistentVolumeClaimRetentionPolicy == registryv1alpha1.ClaimRetentionPolicyDelete {
+		return nil
+	}
+
+	for uid, obj := range owned {
+		if obj.GetObjectKind().GroupVersionKind() != corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim") {
+			continue
+		}
+
+		if err := r.releaseObject(ctx, obj, registry); err != nil {
+			return fmt.Errorf("failed to release PersistentVolumeClaim %s: %w", obj.GetName(), err)
+		}
+		delete(owned, uid)
+
+		log.FromContext(ctx).Info("Released unused PersistentVolumeClaim", "name", obj.GetName())
+		r.Recorder.Eventf(registry, corev1.EventTypeNormal, reasonClaimRetained,
+			"PersistentVolumeClaim %s is no longer used by the storage and has been retained", obj.GetName())
+	}
+
+	return nil
+}
+
+// releaseObject removes the owner reference of the Registry from the given object, so that it is neither pruned
+// nor garbage collected with the Registry.
+func (r *RegistryReconciler) releaseObject(
+	ctx context.Context,
+	obj client.Object,
+	registry *registryv1alpha1.Registry,
+) error {
+	patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	obj.SetOwnerReferences(slices.DeleteFunc(obj.GetOwnerReferences(), func(ref metav1.OwnerReference) bool {
+		return ref.UID == registry.UID
+	}))
+	return r.Patch(ctx, obj, patch)
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
+	"github.com/registry-operator/registry-operator/internal/manifests/registry"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFindRegistryOwnedObjects(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(monitoringv1.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	reg := &registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry", Namespace: "default", UID: "uid"},
+		Spec:       registryv1alpha1.RegistrySpec{RevisionHistoryLimit: ptr.To[int32](1)},
+	}
+	labels := manifestutils.SelectorLabels(reg.ObjectMeta, registry.ComponentRegistry)
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var objects []client.Object
+	for i := range 4 {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:              naming.Secret(reg.Name, strings.Repeat(fmt.Sprint(i), 64)),
+			Namespace:         "default",
+			UID:               types.UID(fmt.Sprintf("secret-%d", i)),
+			Labels:            labels,
+			CreationTimestamp: metav1.NewTime(created.Add(time.Duration(i) * time.Hour)),
+		}}
+		require.NoError(t, ctrl.SetControllerReference(reg, secret, scheme))
+		objects = append(objects, secret)
+	}
+	// a newer Secret which is not a configuration does not count in the revision history
+	other := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:              "my-registry-other",
+		Namespace:         "default",
+		UID:               "other",
+		Labels:            labels,
+		CreationTimestamp: metav1.NewTime(created.Add(10 * time.Hour)),
+	}}
+	require.NoError(t, ctrl.SetControllerReference(reg, other, scheme))
+	claim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Name:      "my-registry-registry",
+		Namespace: "default",
+		UID:       "claim",
+		Labels:    labels,
+	}}
+	require.NoError(t, ctrl.SetControllerReference(reg, claim, scheme))
+	foreign := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:      "my-registry-registry",
+		Namespace: "default",
+		UID:       "foreign",
+		Labels:    labels,
+	}}
+	objects = append(objects, other, claim, foreign)
+
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &RegistryReconciler{
+		Client:   cli,
+		Scheme:   scheme,
+		Recorder: recorder,
+	}
+	params := manifests.Params{Client: cli, Registry: *reg, Scheme: scheme}
+
+	t.Run("keeps the revision history of the configuration Secrets", func(t *testing.T) {
+		owned, err := r.findRegistryOwnedObjects(t.Context(), params)
+		require.NoError(t, err)
+
+		var uids []types.UID
+		for uid := range owned {
+			uids = append(uids, uid)
+		}
+		slices.Sort(uids)
+		assert.Equal(t, []types.UID{"claim", "other", "secret-0", "secret-1"}, uids)
+	})
+
+	t.Run("keeps the configuration Secret of the last known good revision", func(t *testing.T) {
+		params := params
+		params.Registry.Status.LastKnownGoodRevision = &registryv1alpha1.RolloutRevision{
+			ConfigSecret: naming.Secret(reg.Name, strings.Repeat("0", 64)),
+		}
+		owned, err := r.findRegistryOwnedObjects(t.Context(), params)
+		require.NoError(t, err)
+		assert.NotContains(t, owned, types.UID("secret-0"))
+		assert.Contains(t, owned, types.UID("secret-1"))
+	})
+
+	t.Run("retains the unused PersistentVolumeClaims by default", func(t *testing.T) {
+		owned, err := r.findRegistryOwnedObjects(t.Context(), params)
+		require.NoError(t, err)
+
+		require.NoError(t, r.releasePersistentVolumeClaims(t.Context(), &params.Registry, owned))
+		assert.NotContains(t, owned, types.UID("claim"))
+		assert.Len(t, recorder.Events, 1)
+
+		released := &corev1.PersistentVolumeClaim{}
+		require.NoError(t, cli.Get(t.Context(), client.ObjectKeyFromObject(claim), released))
+		assert.Empty(t, released.OwnerReferences)
+	})
+
+	t.Run("prunes the unused PersistentVolumeClaims with the Delete policy", func(t *testing.T) {
+		owned := map[types.UID]client.Object{claim.UID: claim}
+		deleting := params.Registry.DeepCopy()
+		deleting.Spec.PersistentVolumeClaimRetentionPolicy = registryv1alpha1.ClaimRetentionPolicyDelete
+
+		require.NoError(t, r.releasePersistentVolumeClaims(t.Context(), deleting, owned))
+		assert.Contains(t, owned, types.UID("claim"))
+	})
+}
@@ -0,0 +1,223 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
+	"github.com/registry-operator/registry-operator/internal/naming"
+	"github.com/registry-operator/registry-operator/internal/trust"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// componentPullSecret is the component label of the pull Secrets replicated into other namespaces.
+	componentPullSecret = "pull-secret"
+
+	// defaultServiceAccount is the ServiceAccount of a namespace used by the pods not naming any.
+	defaultServiceAccount = "default"
+)
+
+// distributePullSecrets replicates the pull Secret of the Registry into the selected namespaces trusting it,
+// and removes the copies from the namespaces which are no longer selected or trusting.
+func (r *RegistryReconciler) distributePullSecrets(ctx context.Context, params manifests.Params) error {
+	distribution := params.Registry.Spec.PullSecretDistribution
+	if distribution == nil {
+		return r.removePullSecrets(ctx, params.Registry, nil)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&distribution.NamespaceSelector)
+	if err != nil {
+		return fmt.Errorf("%w: invalid namespace selector: %w", manifests.ErrInvalidConfig, err)
+	}
+
+	source := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: params.Registry.Namespace, Name: distribution.SecretRef.Name}
+	if err := r.Get(ctx, key, source); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: pull Secret %s does not exist", manifests.ErrInvalidConfig, key.Name)
+		}
+		return fmt.Errorf("failed to get pull Secret %s: %w", key.Name, err)
+	}
+	if source.Type != corev1.SecretTypeDockerConfigJson {
+		return fmt.Errorf("%w: pull Secret %s has type %s, expected %s",
+			manifests.ErrInvalidConfig, key.Name, source.Type, corev1.SecretTypeDockerConfigJson)
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := r.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	name := naming.PullSecret(params.Registry.Namespace, params.Registry.Name)
+	selected := sets.New[string]()
+	var errs []error
+	for _, ns := range namespaces.Items {
+		if ns.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if !trust.Trusts(&ns, &params.Registry) {
+			log.FromContext(ctx).V(1).Info("Namespace does not trust the Registry", "namespace", ns.Name)
+			continue
+		}
+		selected.Insert(ns.Name)
+
+		if err := r.applyPullSecret(ctx, params.Registry, source, ns.Name, name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if distribution.PatchDefaultServiceAccount {
+			if err := addImagePullSecret(ctx, r.Client, &params.Registry, &ns, name); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if err := r.removePullSecrets(ctx, params.Registry, selected); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// applyPullSecret creates or updates the copy of the pull Secret in the given namespace.
+func (r *RegistryReconciler) applyPullSecret(
+	ctx context.Context,
+	registry registryv1alpha1.Registry,
+	source *corev1.Secret,
+	namespace, name string,
+) error {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if secret.ResourceVersion != "" && !isPullSecretOf(secret, registry) {
+			return fmt.Errorf("secret %s/%s is not managed by the operator", namespace, name)
+		}
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		for k, v := range manifestutils.Labels(registry.ObjectMeta, name, registry.Spec.Image, componentPullSecret, nil) {
+			secret.Labels[k] = v
+		}
+		secret.Type = corev1.SecretTypeDockerConfigJson
+		secret.Data = map[string][]byte{
+			corev1.DockerConfigJsonKey: source.Data[corev1.DockerConfigJsonKey],
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply pull Secret in namespace %s: %w", namespace, err)
+	}
+
+	log.FromContext(ctx).V(1).Info(fmt.Sprintf("Pull Secret has been %s", op), "namespace", namespace)
+	return nil
+}
+
+// removePullSecrets deletes the copies of the pull Secret of the Registry from the namespaces not in keep,
+// and removes them from the default ServiceAccounts. A nil keep removes every copy.
+func (r *RegistryReconciler) removePullSecrets(
+	ctx context.Context,
+	registry registryv1alpha1.Registry,
+	keep sets.Set[string],
+) error {
+	secrets := &corev1.SecretList{}
+	selector := labels.SelectorFromSet(manifestutils.SelectorLabels(registry.ObjectMeta, componentPullSecret))
+	if err := r.List(ctx, secrets, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return fmt.Errorf("failed to list pull Secrets: %w", err)
+	}
+
+	var errs []error
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if keep.Has(secret.Namespace) {
+			continue
+		}
+
+		if err := patchImagePullSecrets(ctx, r.Client, secret.Namespace, secret.Name, false); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete pull Secret in namespace %s: %w", secret.Namespace, err))
+			continue
+		}
+		log.FromContext(ctx).V(1).Info("Pull Secret has been deleted", "namespace", secret.Namespace)
+	}
+	return errors.Join(errs...)
+}
+
+// addImagePullSecret adds the given Secret to the imagePullSecrets of the default ServiceAccount of the given
+// namespace, which must trust the Registry.
+func addImagePullSecret(
+	ctx context.Context,
+	c client.Client,
+	registry *registryv1alpha1.Registry,
+	namespace *corev1.Namespace,
+	name string,
+) error {
+	if !trust.Trusts(namespace, registry) {
+		return fmt.Errorf("namespace %s does not trust the Registry", namespace.Name)
+	}
+	return patchImagePullSecrets(ctx, c, namespace.Name, name, true)
+}
+
+// patchImagePullSecrets adds or removes the given Secret from the imagePullSecrets of the default ServiceAccount
+// of the namespace. A missing ServiceAccount is ignored, the Registry is reconciled again once it is created.
+func patchImagePullSecrets(ctx context.Context, c client.Client, namespace, name string, add bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		sa := &corev1.ServiceAccount{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: defaultServiceAccount}, sa); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		ref := corev1.LocalObjectReference{Name: name}
+		present := slices.Contains(sa.ImagePullSecrets, ref)
+		if present == add {
+			return nil
+		}
+
+		patch := client.MergeFromWithOptions(sa.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		if add {
+			sa.ImagePullSecrets = append(sa.ImagePullSecrets, ref)
+		} else {
+			sa.ImagePullSecrets = slices.DeleteFunc(sa.ImagePullSecrets, func(r corev1.LocalObjectReference) bool {
+				return r == ref
+			})
+		}
+		if err := c.Patch(ctx, sa, patch); err != nil {
+			return fmt.Errorf("failed to patch ServiceAccount %s/%s: %w", namespace, defaultServiceAccount, err)
+		}
+		return nil
+	})
+}
+
+// isPullSecretOf reports whether the given Secret is a pull Secret replicated for the Registry.
+func isPullSecretOf(secret *corev1.Secret, registry registryv1alpha1.Registry) bool {
+	selector := labels.SelectorFromSet(manifestutils.SelectorLabels(registry.ObjectMeta, componentPullSecret))
+	return selector.Matches(labels.Set(secret.Labels))
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDistributePullSecrets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	registry := registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry", Namespace: "registry", UID: "uid"},
+		Spec: registryv1alpha1.RegistrySpec{
+			PullSecretDistribution: &registryv1alpha1.PullSecretDistribution{
+				SecretRef: corev1.LocalObjectReference{Name: "pull"},
+				NamespaceSelector: metav1.LabelSelector{
+					MatchLabels: map[string]string{"registry": "enabled"},
+				},
+				PatchDefaultServiceAccount: true,
+			},
+		},
+	}
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pull", Namespace: "registry"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)},
+	}
+	namespace := func(name string, selected bool) *corev1.Namespace {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{registryv1alpha1.TrustedRegistriesAnnotation: "registry/my-registry"},
+		}}
+		if selected {
+			ns.Labels = map[string]string{"registry": "enabled"}
+		}
+		return ns
+	}
+	serviceAccount := func(namespace string) *corev1.ServiceAccount {
+		return &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: namespace}}
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			source,
+			namespace("team-a", true), serviceAccount("team-a"),
+			namespace("team-b", true),
+			namespace("team-c", false), serviceAccount("team-c"),
+		).
+		Build()
+	r := &RegistryReconciler{
+		Client:   cli,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	params := manifests.Params{Client: cli, Registry: registry, Scheme: scheme}
+	name := "registry-my-registry-registry-pull"
+
+	getCopy := func(t *testing.T, namespace string) (*corev1.Secret, error) {
+		t.Helper()
+		secret := &corev1.Secret{}
+		err := cli.Get(t.Context(), client.ObjectKey{Namespace: namespace, Name: name}, secret)
+		return secret, err
+	}
+	getPullSecrets := func(t *testing.T, namespace string) []corev1.LocalObjectReference {
+		t.Helper()
+		sa := &corev1.ServiceAccount{}
+		require.NoError(t, cli.Get(t.Context(), client.ObjectKey{Namespace: namespace, Name: "default"}, sa))
+		return sa.ImagePullSecrets
+	}
+
+	t.Run("replicates into the selected namespaces", func(t *testing.T) {
+		require.NoError(t, r.distributePullSecrets(t.Context(), params))
+
+		for _, ns := range []string{"team-a", "team-b"} {
+			secret, err := getCopy(t, ns)
+			require.NoError(t, err)
+			assert.Equal(t, corev1.SecretTypeDockerConfigJson, secret.Type)
+			assert.Equal(t, source.Data, secret.Data)
+		}
+		_, err := getCopy(t, "team-c")
+		assert.True(t, apierrors.IsNotFound(err))
+
+		assert.Equal(t, []corev1.LocalObjectReference{{Name: name}}, getPullSecrets(t, "team-a"))
+		assert.Empty(t, getPullSecrets(t, "team-c"))
+	})
+
+	t.Run("is idempotent", func(t *testing.T) {
+		require.NoError(t, r.distributePullSecrets(t.Context(), params))
+		assert.Equal(t, []corev1.LocalObjectReference{{Name: name}}, getPullSecrets(t, "team-a"))
+	})
+
+	t.Run("follows the source Secret", func(t *testing.T) {
+		source.Data[corev1.DockerConfigJsonKey] = []byte(`{"auths":{"example.com":{}}}`)
+		require.NoError(t, cli.Update(t.Context(), source))
+		require.NoError(t, r.distributePullSecrets(t.Context(), params))
+
+		secret, err := getCopy(t, "team-b")
+		require.NoError(t, err)
+		assert.Equal(t, source.Data, secret.Data)
+	})
+
+	t.Run("refuses to overwrite foreign Secrets", func(t *testing.T) {
+		foreign := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-c"}}
+		require.NoError(t, cli.Create(t.Context(), foreign))
+		ns := namespace("team-c", true)
+		require.NoError(t, cli.Update(t.Context(), ns))
+
+		require.Error(t, r.distributePullSecrets(t.Context(), params))
+
+		secret, err := getCopy(t, "team-c")
+		require.NoError(t, err)
+		assert.Empty(t, secret.Data)
+
+		require.NoError(t, cli.Delete(t.Context(), foreign))
+		require.NoError(t, cli.Update(t.Context(), namespace("team-c", false)))
+	})
+
+	t.Run("removes the copies from the namespaces not trusting the Registry", func(t *testing.T) {
+		ns := namespace("team-a", true)
+		ns.Annotations = nil
+		require.NoError(t, cli.Update(t.Context(), ns))
+		require.NoError(t, r.distributePullSecrets(t.Context(), params))
+
+		_, err := getCopy(t, "team-a")
+		assert.True(t, apierrors.IsNotFound(err))
+		assert.Empty(t, getPullSecrets(t, "team-a"))
+
+		require.NoError(t, cli.Update(t.Context(), namespace("team-a", true)))
+		require.NoError(t, r.distributePullSecrets(t.Context(), params))
+		_, err = getCopy(t, "team-a")
+		assert.NoError(t, err)
+	})
+
+	t.Run("removes the copies from deselected namespaces", func(t *testing.T) {
+		require.NoError(t, cli.Update(t.Context(), namespace("team-a", false)))
+		require.NoError(t, r.distributePullSecrets(t.Context(), params))
+
+		_, err := getCopy(t, "team-a")
+		assert.True(t, apierrors.IsNotFound(err))
+		assert.Empty(t, getPullSecrets(t, "team-a"))
+
+		_, err = getCopy(t, "team-b")
+		assert.NoError(t, err)
+	})
+
+	t.Run("fails on a missing source Secret", func(t *testing.T) {
+		missing := params
+		missing.Registry = *registry.DeepCopy()
+		missing.Registry.Spec.PullSecretDistribution.SecretRef.Name = "missing"
+
+		err := r.distributePullSecrets(t.Context(), missing)
+		assert.ErrorIs(t, err, manifests.ErrInvalidConfig)
+	})
+
+	t.Run("removes every copy on finalization", func(t *testing.T) {
+		require.NoError(t, r.finalizeRegistry(t.Context(), params))
+
+		for _, ns := range []string{"team-a", "team-b", "team-c"} {
+			_, err := getCopy(t, ns)
+			assert.True(t, apierrors.IsNotFound(err))
+		}
+		_, err := getCopy(t, "registry")
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+}
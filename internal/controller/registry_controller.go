@@ -19,16 +19,32 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"time"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 
 	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
 	"github.com/registry-operator/registry-operator/internal/manifests"
 	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
 	"github.com/registry-operator/registry-operator/internal/manifests/registry"
+	"github.com/registry-operator/registry-operator/internal/registryclass"
+	"github.com/registry-operator/registry-operator/internal/registryuser"
 	registrystatus "github.com/registry-operator/registry-operator/internal/status/registry"
+	registryupgrade "github.com/registry-operator/registry-operator/internal/upgrade/registry"
+	"github.com/registry-operator/registry-operator/internal/version"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -40,6 +56,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 const (
@@ -51,31 +68,125 @@ type RegistryReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+
+	// OperatorImage is the image of the operator, running the backup Jobs.
+	OperatorImage string
+
+	// OperatorNamespace is the namespace of the operator, whose Pods the NetworkPolicies let reach the Registries.
+	OperatorNamespace string
+
+	// HTTPClient is used to resolve the update channels and to purge the S3 storage, a default client is used
+	// if nil.
+	HTTPClient *http.Client
 }
 
 // +kubebuilder:rbac:groups=registry-operator.dev,resources=registries,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=registry-operator.dev,resources=registries/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=registry-operator.dev,resources=registries/finalizers,verbs=update
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=registryclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=registryusers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=registryrestores,verbs=get;list;watch
+// +kubebuilder:rbac:groups=registry.registry-operator.dev,resources=registries,verbs=get;list;watch;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes;gateways,verbs=get;list;watch
+//nolint:lll // kubebuilder directives
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update;patch;delete
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *RegistryReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&registryv1alpha1.Registry{}).
 		Owns(&corev1.Secret{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.DaemonSet{}).
 		Owns(&corev1.PersistentVolumeClaim{}).
 		Owns(&corev1.Service{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Owns(&batchv1.CronJob{}).
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.ConfigMap{}).
+		Watches(
+			&batchv1.Job{},
+			handler.EnqueueRequestsFromMapFunc(r.MapBackupJobs),
+		).
+		Watches(
+			&registryv1alpha1.RegistryRestore{},
+			handler.EnqueueRequestsFromMapFunc(r.MapRegistryRestores),
+		).
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.MapS3Secrets),
-			builder.WithPredicates(s3SecretPredicate),
+			builder.WithPredicates(secretDataPredicate),
+		).
+		Watches(
+			&networkingv1.Ingress{},
+			handler.EnqueueRequestsFromMapFunc(r.MapIngresses),
 		).
-		Complete(r)
+		Watches(
+			&registryv1alpha1.RegistryClass{},
+			handler.EnqueueRequestsFromMapFunc(r.MapRegistryClasses),
+		).
+		Watches(
+			&registryv1alpha1.RegistryUser{},
+			handler.EnqueueRequestsFromMapFunc(r.MapRegistryUsers),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.MapRegistryUserSecrets),
+			builder.WithPredicates(secretDataPredicate),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.MapPullSecrets),
+			builder.WithPredicates(secretDataPredicate),
+		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.MapNamespaces),
+			// the labels select the namespaces, and the annotations trust the Registries
+			builder.WithPredicates(predicate.Or(predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})),
+		).
+		Watches(
+			&corev1.ServiceAccount{},
+			handler.EnqueueRequestsFromMapFunc(r.MapServiceAccounts),
+		)
+
+	// PrometheusRules can only be watched when the Prometheus Operator CRDs are installed.
+	if isServed(mgr, monitoringv1.SchemeGroupVersion.WithKind(monitoringv1.PrometheusRuleKind)) {
+		b = b.Owns(&monitoringv1.PrometheusRule{})
+	}
+	// HTTPRoutes and Gateways can only be watched when the Gateway API CRDs are installed.
+	if isServed(mgr, registrystatus.HTTPRouteGVK) {
+		route := &unstructured.Unstructured{}
+		route.SetGroupVersionKind(registrystatus.HTTPRouteGVK)
+		b = b.Watches(route, handler.EnqueueRequestsFromMapFunc(r.MapHTTPRoutes))
+	}
+	if isServed(mgr, registrystatus.GatewayGVK) {
+		gateway := &unstructured.Unstructured{}
+		gateway.SetGroupVersionKind(registrystatus.GatewayGVK)
+		b = b.Watches(gateway, handler.EnqueueRequestsFromMapFunc(r.MapGateways))
+	}
+	// VolumeSnapshots can only be watched when the CSI snapshot CRDs are installed.
+	if isServed(mgr, volumeSnapshotGVK) {
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+		b = b.Watches(snapshot, handler.EnqueueRequestsFromMapFunc(r.MapScheduledSnapshots))
+	}
+
+	return b.Complete(r)
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -95,10 +206,31 @@ func (r *RegistryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	params, err := r.GetParams(instance)
-	if err != nil {
-		log.Error(err, "Failed to create manifest.Params")
-		return ctrl.Result{}, err
+	var nextUpdate time.Duration
+	if instance.GetDeletionTimestamp() == nil {
+		// the version of a Registry following an update channel is driven by its channel
+		if instance.Spec.UpdatePolicy == nil {
+			up := registryupgrade.VersionUpgrade{
+				Client:   r.Client,
+				Recorder: r.Recorder,
+				Version:  version.Get(),
+			}
+			if err := up.Upgrade(ctx, &instance); err != nil {
+				// don't fail to allow reconciling the Registry as it is
+				log.V(2).Error(err, "Failed to upgrade the Registry CR")
+			}
+		}
+
+		var err error
+		if nextUpdate, err = r.updateImage(ctx, &instance); err != nil {
+			// don't fail to allow reconciling the Registry with its current image
+			log.Error(err, "Failed to update the Registry image")
+		}
+	}
+
+	params, paramsErr := r.GetParams(ctx, instance)
+	if paramsErr != nil {
+		log.Error(paramsErr, "Failed to create manifest.Params")
 	}
 
 	// We have a deletion, short circuit and let the deletion happen
@@ -106,15 +238,14 @@ func (r *RegistryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		if controllerutil.ContainsFinalizer(&instance, registryFinalizer) {
 			// If the finalization logic fails, don't remove the finalizer so
 			// that we can retry during the next reconciliation.
-			if err = r.finalizeRegistry(ctx, params); err != nil {
+			if err := r.finalizeRegistry(ctx, params); err != nil {
 				return ctrl.Result{}, err
 			}
 
 			// Once all finalizers have been
 			// removed, the object will be deleted.
 			if controllerutil.RemoveFinalizer(&instance, registryFinalizer) {
-				err = r.Update(ctx, &instance)
-				if err != nil {
+				if err := r.Update(ctx, &instance); err != nil {
 					return ctrl.Result{}, err
 				}
 			}
@@ -123,11 +254,14 @@ func (r *RegistryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, nil
 	}
 
+	if paramsErr != nil {
+		return registrystatus.HandleReconcileStatus(ctx, params, instance, paramsErr)
+	}
+
 	// Add finalizer for this CR
 	if !controllerutil.ContainsFinalizer(&instance, registryFinalizer) {
 		if controllerutil.AddFinalizer(&instance, registryFinalizer) {
-			err = r.Update(ctx, &instance)
-			if err != nil {
+			if err := r.Update(ctx, &instance); err != nil {
 				return ctrl.Result{}, err
 			}
 		}
@@ -135,7 +269,7 @@ func (r *RegistryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 	desiredObjects, buildErr := BuildRegistry(ctx, params)
 	if buildErr != nil {
-		return ctrl.Result{}, buildErr
+		return registrystatus.HandleReconcileStatus(ctx, params, instance, buildErr)
 	}
 
 	ownedObjects, err := r.findRegistryOwnedObjects(ctx, params)
@@ -143,11 +277,37 @@ func (r *RegistryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
+	if err := r.releasePersistentVolumeClaims(ctx, &params.Registry, ownedObjects); err != nil {
+		return registrystatus.HandleReconcileStatus(ctx, params, instance, err)
+	}
+
 	err = reconcileDesiredObjects(ctx, r.Client, &instance, params.Scheme, desiredObjects, ownedObjects)
-	return registrystatus.HandleReconcileStatus(ctx, params, instance, err)
+	err = errors.Join(err, r.distributePullSecrets(ctx, params))
+	if params.Registry.Spec.NodeMirror == nil {
+		_, mirrorErr := r.removeNodeMirror(ctx, &params.Registry)
+		err = errors.Join(err, mirrorErr)
+	}
+	err = errors.Join(err, r.runStorageMigration(ctx, params))
+	nextSnapshot, snapshotErr := r.takeSnapshots(ctx, params)
+	err = errors.Join(err, snapshotErr)
+
+	result, err := registrystatus.HandleReconcileStatus(ctx, params, instance, err)
+	if err == nil {
+		for _, next := range []time.Duration{nextSnapshot, nextUpdate} {
+			if next > 0 && (result.RequeueAfter == 0 || next < result.RequeueAfter) {
+				result.RequeueAfter = next
+			}
+		}
+	}
+	return result, err
 }
 
-func (r *RegistryReconciler) GetParams(instance registryv1alpha1.Registry) (manifests.Params, error) {
+// GetParams returns the manifest.Params of the given instance, carrying its effective spec with the
+// defaults of its RegistryClass applied. On error, the params carry the instance as it is.
+func (r *RegistryReconciler) GetParams(
+	ctx context.Context,
+	instance registryv1alpha1.Registry,
+) (manifests.Params, error) {
 	p := manifests.Params{
 		Client:   r.Client,
 		Registry: instance,
@@ -155,35 +315,115 @@ func (r *RegistryReconciler) GetParams(instance registryv1alpha1.Registry) (mani
 		Recorder: r.Recorder,
 	}
 
+	class, err := registryclass.Get(ctx, r.Client, &instance)
+	if errors.Is(err, registryclass.ErrNotFound) {
+		return p, fmt.Errorf("%w: %w", manifests.ErrInvalidConfig, err)
+	} else if err != nil {
+		return p, err
+	}
+
+	effective := registryclass.Apply(instance, class)
+	p.Registry = effective
+	if update := effective.Status.ImageUpdate; update != nil && update.Image != "" &&
+		effective.Spec.UpdatePolicy != nil && effective.Spec.Image == "" {
+		p.Registry.Spec.Image = update.Image
+	}
+	if image := registryupgrade.HeldImage(p.Registry); image != "" {
+		p.Registry.Spec.Image = image
+	}
+
+	htpasswd, err := registryuser.Htpasswd(ctx, r.Client, &p.Registry)
+	if err != nil {
+		return p, err
+	}
+	p.Htpasswd = htpasswd
+
+	p.OperatorImage = r.OperatorImage
+	p.OperatorNamespace = r.OperatorNamespace
+	if err := r.setMigrationParams(ctx, &p, instance.Status.ServedStorage); err != nil {
+		return p, err
+	}
+	if err := r.setBackupParams(ctx, &p); err != nil {
+		return p, err
+	}
+	if err := setRollbackParams(ctx, &p); err != nil {
+		return p, err
+	}
+
 	return p, nil
 }
 
+// findRegistryOwnedObjects returns the objects controlled by the Registry, to be pruned unless still desired.
+// The configuration Secrets kept in the revision history are left out.
 func (r *RegistryReconciler) findRegistryOwnedObjects(
 	ctx context.Context,
 	params manifests.Params,
 ) (map[types.UID]client.Object, error) {
 	ownedObjects := map[types.UID]client.Object{}
-	ownedObjectTypes := []client.Object{}
-
-	listOps := &client.ListOptions{
-		Namespace: params.Registry.Namespace,
-		LabelSelector: labels.SelectorFromSet(
-			manifestutils.SelectorLabels(params.Registry.ObjectMeta, registry.ComponentRegistry),
-		),
-	}
-	for _, objectType := range ownedObjectTypes {
-		objs, err := getList(ctx, r.Client, objectType, listOps)
-		if err != nil {
-			return nil, err
+	ownedObjectTypes := map[string][]client.Object{
+		registry.ComponentRegistry: {
+			&corev1.Secret{},
+			&appsv1.Deployment{},
+			&corev1.Service{},
+			&corev1.PersistentVolumeClaim{},
+			&networkingv1.NetworkPolicy{},
+			&monitoringv1.PrometheusRule{},
+		},
+		registry.ComponentBackup: {
+			&batchv1.CronJob{},
+			&corev1.ConfigMap{},
+		},
+	}
+
+	for component, objectTypes := range ownedObjectTypes {
+		listOps := &client.ListOptions{
+			Namespace: params.Registry.Namespace,
+			LabelSelector: labels.SelectorFromSet(
+				manifestutils.SelectorLabels(params.Registry.ObjectMeta, component),
+			),
 		}
-		for uid, object := range objs {
-			ownedObjects[uid] = object
+		for _, objectType := range objectTypes {
+			objs, err := getList(ctx, r.Client, objectType, listOps)
+			if meta.IsNoMatchError(err) {
+				// PrometheusRules are only served when the Prometheus Operator CRDs are installed
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			maps.DeleteFunc(objs, func(_ types.UID, object client.Object) bool {
+				return !metav1.IsControlledBy(object, &params.Registry)
+			})
+			if _, ok := objectType.(*corev1.Secret); ok {
+				keepConfigSecretHistory(objs, &params.Registry)
+			}
+			maps.Copy(ownedObjects, objs)
 		}
 	}
 
 	return ownedObjects, nil
 }
 
-func (r *RegistryReconciler) finalizeRegistry(_ context.Context, _ manifests.Params) error {
+// finalizeRegistry removes the objects of the Registry which cannot be garbage collected with it.
+func (r *RegistryReconciler) finalizeRegistry(ctx context.Context, params manifests.Params) error {
+	// pull Secrets live in other namespaces, out of reach of the owner references
+	if err := r.removePullSecrets(ctx, params.Registry, nil); err != nil {
+		return err
+	}
+
+	// the nodes are cleaned up by the node mirror Pods as they are stopped
+	gone, err := r.removeNodeMirror(ctx, &params.Registry)
+	if err != nil {
+		return err
+	}
+	if !gone {
+		return errNodeMirrorRemoving
+	}
+
+	if err := r.applyDeletionPolicy(ctx, params); err != nil {
+		if !errors.Is(err, errSnapshotPending) {
+			r.Recorder.Event(&params.Registry, corev1.EventTypeWarning, reasonDeletionPolicyFailed, err.Error())
+		}
+		return err
+	}
 	return nil
 }
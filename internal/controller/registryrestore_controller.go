@@ -0,0 +1,253 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/registry"
+	"github.com/registry-operator/registry-operator/internal/naming"
+	"github.com/registry-operator/registry-operator/internal/registryclass"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	reasonRegistryScalingDown = "RegistryScalingDown"
+	reasonRestoreStarted      = "RestoreStarted"
+	reasonRestoreSucceeded    = "RestoreSucceeded"
+	reasonRestoreFailed       = "RestoreFailed"
+)
+
+// errRestorePending is returned while the Registry is not ready to be restored, retried once it changes.
+var errRestorePending = errors.New("restore is pending")
+
+// RegistryRestoreReconciler reconciles a RegistryRestore object.
+type RegistryRestoreReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// OperatorImage is the image of the operator, running the restore Jobs.
+	OperatorImage string
+}
+
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=registryrestores,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=registryrestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=registries,verbs=get;list;watch
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=registryclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RegistryRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&registryv1alpha1.RegistryRestore{}).
+		Owns(&batchv1.Job{}).
+		Watches(
+			&registryv1alpha1.Registry{},
+			handler.EnqueueRequestsFromMapFunc(r.MapRegistries),
+		).
+		Complete(r)
+}
+
+// Reconcile restores the backup into the Registry once the Registry controller scaled it down, by running
+// a Job replacing the content of its storage, and records the outcome. The Registry is scaled back up once
+// the RegistryRestore succeeded or failed.
+func (r *RegistryRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx, "registryrestore", klog.KRef(req.Namespace, req.Name))
+
+	var instance registryv1alpha1.RegistryRestore
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Error(err, "unable to fetch RegistryRestore")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if instance.GetDeletionTimestamp() != nil || isRestoreFinished(&instance) {
+		return ctrl.Result{}, nil
+	}
+
+	changed := instance.DeepCopy()
+	changed.Status.ObservedGeneration = changed.Generation
+	restoreErr := r.restore(ctx, changed)
+
+	switch {
+	case errors.Is(restoreErr, errRestorePending):
+		changed.Status.Phase = registryv1alpha1.RegistryRestorePhasePending
+		restoreErr = nil
+	case errors.Is(restoreErr, manifests.ErrInvalidConfig):
+		// the Registry is scaled back up rather than waiting for a fix
+		r.finishRestore(changed, registryv1alpha1.RegistryRestorePhaseFailed, metav1.ConditionFalse,
+			reasonRestoreFailed, restoreErr.Error())
+		r.Recorder.Event(changed, corev1.EventTypeWarning, reasonRestoreFailed, restoreErr.Error())
+		restoreErr = nil
+	case restoreErr != nil:
+		r.setRestoreCondition(changed, metav1.ConditionFalse, reasonRestoreFailed, restoreErr.Error())
+	}
+
+	if err := r.Status().Patch(ctx, changed, client.MergeFrom(&instance)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to apply status changes to the RegistryRestore CR: %w", err)
+	}
+
+	if restoreErr != nil {
+		r.Recorder.Event(changed, corev1.EventTypeWarning, reasonRestoreFailed, restoreErr.Error())
+		return ctrl.Result{}, restoreErr
+	}
+	if instance.Status.Phase != changed.Status.Phase {
+		log.Info("RegistryRestore progressed", "phase", changed.Status.Phase)
+	}
+	return ctrl.Result{}, nil
+}
+
+// restore advances the given RegistryRestore, recording its progress in its status.
+func (r *RegistryRestoreReconciler) restore(ctx context.Context, changed *registryv1alpha1.RegistryRestore) error {
+	job := &batchv1.Job{}
+	key := client.ObjectKey{Namespace: changed.Namespace, Name: naming.RestoreJob(changed.Name)}
+	err := r.Get(ctx, key, job)
+	switch {
+	case apierrors.IsNotFound(err):
+		return r.startRestore(ctx, changed)
+	case err != nil:
+		return fmt.Errorf("failed to get restore Job: %w", err)
+	case !metav1.IsControlledBy(job, changed):
+		return fmt.Errorf("job %s is not managed by the RegistryRestore", job.Name)
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			r.finishRestore(changed, registryv1alpha1.RegistryRestorePhaseSucceeded, metav1.ConditionTrue,
+				reasonRestoreSucceeded, fmt.Sprintf("Backup %s has been restored", changed.Spec.BackupName))
+			r.Recorder.Eventf(changed, corev1.EventTypeNormal, reasonRestoreSucceeded,
+				"Backup %s has been restored into Registry %s", changed.Spec.BackupName, changed.Spec.RegistryRef.Name)
+			return nil
+		case batchv1.JobFailed:
+			message := fmt.Sprintf("Job %s failed: %s", job.Name, condition.Message)
+			r.finishRestore(changed, registryv1alpha1.RegistryRestorePhaseFailed, metav1.ConditionFalse,
+				reasonRestoreFailed, message)
+			r.Recorder.Event(changed, corev1.EventTypeWarning, reasonRestoreFailed, message)
+			return nil
+		}
+	}
+
+	changed.Status.Phase = registryv1alpha1.RegistryRestorePhaseRunning
+	return nil
+}
+
+// startRestore creates the Job restoring the backup once the Registry has no Pod left.
+func (r *RegistryRestoreReconciler) startRestore(ctx context.Context, changed *registryv1alpha1.RegistryRestore) error {
+	reg := &registryv1alpha1.Registry{}
+	key := client.ObjectKey{Namespace: changed.Namespace, Name: changed.Spec.RegistryRef.Name}
+	if err := r.Get(ctx, key, reg); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.setRestoreCondition(changed, metav1.ConditionFalse, reasonRegistryNotReady,
+				fmt.Sprintf("Registry %s does not exist", key.Name))
+			return errRestorePending
+		}
+		return fmt.Errorf("failed to get Registry: %w", err)
+	}
+
+	class, err := registryclass.Get(ctx, r.Client, reg)
+	if errors.Is(err, registryclass.ErrNotFound) {
+		return fmt.Errorf("%w: %w", manifests.ErrInvalidConfig, err)
+	} else if err != nil {
+		return err
+	}
+	effective := registryclass.Apply(*reg, class)
+	if storage := effective.Spec.Storage; storage.PersistentVolumeClaim == nil &&
+		storage.PersistentVolumeClaimTemplate == nil {
+		return fmt.Errorf("%w: registry %s is not stored in a PersistentVolumeClaim", manifests.ErrInvalidConfig, reg.Name)
+	}
+
+	dpl := &appsv1.Deployment{}
+	dplKey := client.ObjectKey{Namespace: reg.Namespace, Name: naming.Registry(reg.Name)}
+	if err := r.Get(ctx, dplKey, dpl); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to get Deployment: %w", err)
+	} else if err == nil && (dpl.Spec.Replicas == nil || *dpl.Spec.Replicas != 0 || dpl.Status.Replicas != 0) {
+		r.setRestoreCondition(changed, metav1.ConditionFalse, reasonRegistryScalingDown,
+			fmt.Sprintf("Waiting for Registry %s to scale down", reg.Name))
+		return errRestorePending
+	}
+
+	params := manifests.Params{Registry: effective, OperatorImage: r.OperatorImage}
+	job, err := registry.RestoreJob(params, changed)
+	if err != nil {
+		return err
+	}
+	if err := ctrl.SetControllerReference(changed, job, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, job); err != nil {
+		return fmt.Errorf("failed to create restore Job: %w", err)
+	}
+
+	now := metav1.Now()
+	changed.Status.StartTime = &now
+	changed.Status.Phase = registryv1alpha1.RegistryRestorePhaseRunning
+	r.setRestoreCondition(changed, metav1.ConditionFalse, reasonRestoreStarted,
+		fmt.Sprintf("Job %s is restoring backup %s", job.Name, changed.Spec.BackupName))
+	r.Recorder.Eventf(changed, corev1.EventTypeNormal, reasonRestoreStarted,
+		"Job %s is restoring backup %s", job.Name, changed.Spec.BackupName)
+	return nil
+}
+
+// finishRestore records the outcome of the given RegistryRestore.
+func (r *RegistryRestoreReconciler) finishRestore(
+	changed *registryv1alpha1.RegistryRestore,
+	phase registryv1alpha1.RegistryRestorePhase,
+	status metav1.ConditionStatus,
+	reason, message string,
+) {
+	now := metav1.Now()
+	changed.Status.CompletionTime = &now
+	changed.Status.Phase = phase
+	r.setRestoreCondition(changed, status, reason, message)
+}
+
+func (r *RegistryRestoreReconciler) setRestoreCondition(
+	changed *registryv1alpha1.RegistryRestore,
+	status metav1.ConditionStatus,
+	reason, message string,
+) {
+	meta.SetStatusCondition(&changed.Status.Conditions, metav1.Condition{
+		Type:               registryv1alpha1.ConditionTypeRestored,
+		Status:             status,
+		ObservedGeneration: changed.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRegistryRestoreReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	registry := &registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry", Namespace: "default"},
+		Spec: registryv1alpha1.RegistrySpec{
+			Storage: registryv1alpha1.Storage{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+			},
+		},
+	}
+	restore := &registryv1alpha1.RegistryRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "yesterday", Namespace: "default", Generation: 1},
+		Spec: registryv1alpha1.RegistryRestoreSpec{
+			RegistryRef: corev1.LocalObjectReference{Name: registry.Name},
+			BackupName:  "my-registry-registry-backup-29000000",
+			Source: &registryv1alpha1.BackupDestination{
+				S3StorageSource: registryv1alpha1.S3StorageSource{
+					BucketName: registryv1alpha1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "backup"},
+						Key:                  "bucket",
+					},
+					Region: registryv1alpha1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "backup"},
+						Key:                  "region",
+					},
+				},
+			},
+		},
+	}
+	dpl := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry-registry", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](1)},
+		Status:     appsv1.DeploymentStatus{Replicas: 1},
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&registryv1alpha1.RegistryRestore{}, &appsv1.Deployment{}, &batchv1.Job{}).
+		WithObjects(registry, restore, dpl).
+		Build()
+	r := &RegistryRestoreReconciler{
+		Client:        cli,
+		Scheme:        scheme,
+		Recorder:      record.NewFakeRecorder(10),
+		OperatorImage: "operator:dev",
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(restore)}
+	jobKey := client.ObjectKey{Namespace: "default", Name: "yesterday-restore"}
+
+	getRestore := func(t *testing.T) *registryv1alpha1.RegistryRestore {
+		t.Helper()
+		actual := &registryv1alpha1.RegistryRestore{}
+		require.NoError(t, cli.Get(t.Context(), req.NamespacedName, actual))
+		return actual
+	}
+
+	t.Run("should wait for the Registry to scale down", func(t *testing.T) {
+		_, err := r.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+
+		actual := getRestore(t)
+		assert.Equal(t, registryv1alpha1.RegistryRestorePhasePending, actual.Status.Phase)
+		condition := meta.FindStatusCondition(actual.Status.Conditions, registryv1alpha1.ConditionTypeRestored)
+		require.NotNil(t, condition)
+		assert.Equal(t, reasonRegistryScalingDown, condition.Reason)
+		assert.Error(t, cli.Get(t.Context(), jobKey, &batchv1.Job{}))
+	})
+
+	t.Run("should start the restore Job", func(t *testing.T) {
+		dpl.Spec.Replicas = ptr.To[int32](0)
+		require.NoError(t, cli.Update(t.Context(), dpl))
+		dpl.Status.Replicas = 0
+		require.NoError(t, cli.Status().Update(t.Context(), dpl))
+
+		_, err := r.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+
+		actual := getRestore(t)
+		assert.Equal(t, registryv1alpha1.RegistryRestorePhaseRunning, actual.Status.Phase)
+		assert.NotNil(t, actual.Status.StartTime)
+
+		job := &batchv1.Job{}
+		require.NoError(t, cli.Get(t.Context(), jobKey, job))
+		assert.True(t, metav1.IsControlledBy(job, actual))
+		assert.Equal(t, "data", job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	})
+
+	t.Run("should record the outcome of the Job", func(t *testing.T) {
+		job := &batchv1.Job{}
+		require.NoError(t, cli.Get(t.Context(), jobKey, job))
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		require.NoError(t, cli.Status().Update(t.Context(), job))
+
+		_, err := r.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+
+		actual := getRestore(t)
+		assert.Equal(t, registryv1alpha1.RegistryRestorePhaseSucceeded, actual.Status.Phase)
+		assert.NotNil(t, actual.Status.CompletionTime)
+		assert.True(t, meta.IsStatusConditionTrue(actual.Status.Conditions, registryv1alpha1.ConditionTypeRestored))
+	})
+
+	t.Run("should fail without a persistent storage", func(t *testing.T) {
+		other := &registryv1alpha1.RegistryRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "ephemeral", Namespace: "default", Generation: 1},
+			Spec: registryv1alpha1.RegistryRestoreSpec{
+				RegistryRef: corev1.LocalObjectReference{Name: "ephemeral"},
+				BackupName:  "ephemeral-registry-backup-29000000",
+				Source:      restore.Spec.Source,
+			},
+		}
+		ephemeral := &registryv1alpha1.Registry{
+			ObjectMeta: metav1.ObjectMeta{Name: "ephemeral", Namespace: "default"},
+			Spec: registryv1alpha1.RegistrySpec{
+				Storage: registryv1alpha1.Storage{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			},
+		}
+		require.NoError(t, cli.Create(t.Context(), ephemeral))
+		require.NoError(t, cli.Create(t.Context(), other))
+
+		_, err := r.Reconcile(t.Context(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(other)})
+		require.NoError(t, err)
+
+		actual := &registryv1alpha1.RegistryRestore{}
+		require.NoError(t, cli.Get(t.Context(), client.ObjectKeyFromObject(other), actual))
+		assert.Equal(t, registryv1alpha1.RegistryRestorePhaseFailed, actual.Status.Phase)
+	})
+}
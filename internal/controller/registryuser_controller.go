@@ -0,0 +1,283 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/registryuser"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	reasonCredentialsReady  = "CredentialsReady"
+	reasonCredentialsFailed = "CredentialsFailed"
+	reasonRotated           = "Rotated"
+	reasonExpired           = "Expired"
+	reasonSecretConflict    = "SecretConflict"
+)
+
+// errSecretConflict is returned when the Secret of a RegistryUser already exists and is not controlled by it.
+var errSecretConflict = errors.New("secret is not controlled by the RegistryUser")
+
+// RegistryUserReconciler reconciles a RegistryUser object.
+type RegistryUserReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=registryusers,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=registryusers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=registries,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RegistryUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&registryv1alpha1.RegistryUser{}).
+		Owns(&corev1.Secret{}).
+		Watches(
+			&registryv1alpha1.Registry{},
+			handler.EnqueueRequestsFromMapFunc(r.MapRegistries),
+		).
+		Complete(r)
+}
+
+// Reconcile maintains the kubernetes.io/dockerconfigjson Secret of the RegistryUser, generating a new password
+// when the user is created and on every rotation, and deletes it once the user expired.
+func (r *RegistryUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx, "registryuser", klog.KRef(req.Namespace, req.Name))
+
+	var instance registryv1alpha1.RegistryUser
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Error(err, "unable to fetch RegistryUser")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// the Secret is garbage collected with its owner
+	if instance.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	changed := instance.DeepCopy()
+	changed.Status.ObservedGeneration = changed.Generation
+	condition := metav1.Condition{
+		Type:               registryv1alpha1.ConditionTypeReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: changed.Generation,
+		Reason:             reasonCredentialsReady,
+		Message:            "Credentials are stored in Secret " + registryuser.SecretName(changed),
+	}
+
+	now := time.Now()
+	var (
+		userErr error
+		wait    time.Duration
+	)
+	if registryuser.IsExpired(changed, now) {
+		userErr = r.expire(ctx, changed)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonExpired
+		condition.Message = "User expired at " + changed.Spec.ExpiresAt.UTC().Format(time.RFC3339)
+	} else {
+		userErr = r.reconcileSecret(ctx, changed, now)
+		wait = nextUserChange(changed, now)
+	}
+
+	if userErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Message = userErr.Error()
+		switch {
+		case errors.Is(userErr, errRegistryNotReady):
+			condition.Reason = reasonRegistryNotReady
+		case errors.Is(userErr, errSecretConflict):
+			condition.Reason = reasonSecretConflict
+		default:
+			condition.Reason = reasonCredentialsFailed
+		}
+	}
+	meta.SetStatusCondition(&changed.Status.Conditions, condition)
+
+	if err := r.Status().Patch(ctx, changed, client.MergeFrom(&instance)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to apply status changes to the RegistryUser CR: %w", err)
+	}
+
+	switch {
+	case errors.Is(userErr, errRegistryNotReady), errors.Is(userErr, errSecretConflict):
+		// retried once the Registry or the RegistryUser changes
+		r.Recorder.Event(changed, corev1.EventTypeWarning, condition.Reason, userErr.Error())
+		return ctrl.Result{}, nil
+	case userErr != nil:
+		r.Recorder.Event(changed, corev1.EventTypeWarning, condition.Reason, userErr.Error())
+		return ctrl.Result{}, userErr
+	}
+
+	return ctrl.Result{RequeueAfter: wait}, nil
+}
+
+// reconcileSecret creates or updates the Secret of the given RegistryUser, rotating its password when due,
+// and records it in its status.
+func (r *RegistryUserReconciler) reconcileSecret(
+	ctx context.Context,
+	changed *registryv1alpha1.RegistryUser,
+	now time.Time,
+) error {
+	registry := &registryv1alpha1.Registry{}
+	key := client.ObjectKey{Namespace: changed.Namespace, Name: changed.Spec.RegistryRef.Name}
+	if err := r.Get(ctx, key, registry); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: %s does not exist", errRegistryNotReady, key.Name)
+		}
+		return fmt.Errorf("failed to get registry: %w", err)
+	}
+	if len(registry.Status.Endpoints) == 0 {
+		return fmt.Errorf("%w: %s has no endpoint yet", errRegistryNotReady, key.Name)
+	}
+
+	name := registryuser.SecretName(changed)
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: changed.Namespace}}
+	username := registryuser.Username(changed)
+	rotated := false
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		// an existing Secret of another owner, or of the user, is never taken over
+		if secret.ResourceVersion != "" && !metav1.IsControlledBy(secret, changed) {
+			return fmt.Errorf("%w: %s", errSecretConflict, name)
+		}
+
+		password := string(secret.Data[corev1.BasicAuthPasswordKey])
+		entry := string(secret.Data[registryuser.HtpasswdKey])
+		if password == "" || string(secret.Data[corev1.BasicAuthUsernameKey]) != username ||
+			!strings.HasPrefix(entry, username+":") || rotationDue(changed, now) {
+			password = registryuser.GeneratePassword()
+			var err error
+			if entry, err = registryuser.HtpasswdEntry(username, password); err != nil {
+				return err
+			}
+			rotated = true
+		}
+
+		config, err := registryuser.DockerConfig(registry.Status.Endpoints, username, password)
+		if err != nil {
+			return err
+		}
+
+		secret.Type = corev1.SecretTypeDockerConfigJson
+		secret.Data = map[string][]byte{
+			corev1.DockerConfigJsonKey:  config,
+			corev1.BasicAuthUsernameKey: []byte(username),
+			corev1.BasicAuthPasswordKey: []byte(password),
+			registryuser.HtpasswdKey:    []byte(entry),
+		}
+		return controllerutil.SetControllerReference(changed, secret, r.Scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply Secret %s: %w", name, err)
+	}
+
+	if previous := changed.Status.SecretName; previous != "" && previous != name {
+		if err := r.deleteSecret(ctx, changed, previous); err != nil {
+			return err
+		}
+	}
+	changed.Status.SecretName = name
+	if rotated {
+		changed.Status.LastRotationTime = &metav1.Time{Time: now}
+		r.Recorder.Event(changed, corev1.EventTypeNormal, reasonRotated, "Generated a new password in Secret "+name)
+	}
+	return nil
+}
+
+// expire deletes the Secret of the given expired RegistryUser.
+func (r *RegistryUserReconciler) expire(ctx context.Context, changed *registryv1alpha1.RegistryUser) error {
+	if changed.Status.SecretName == "" {
+		return nil
+	}
+	if err := r.deleteSecret(ctx, changed, changed.Status.SecretName); err != nil {
+		return err
+	}
+
+	r.Recorder.Event(changed, corev1.EventTypeNormal, reasonExpired, "Deleted Secret "+changed.Status.SecretName)
+	changed.Status.SecretName = ""
+	return nil
+}
+
+// deleteSecret deletes the given Secret of the RegistryUser, unless it is already gone or no longer controlled
+// by the RegistryUser.
+func (r *RegistryUserReconciler) deleteSecret(
+	ctx context.Context,
+	user *registryv1alpha1.RegistryUser,
+	name string,
+) error {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: user.Namespace, Name: name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get Secret %s: %w", name, err)
+	}
+	if !metav1.IsControlledBy(secret, user) {
+		return nil
+	}
+
+	err := r.Delete(ctx, secret, client.Preconditions{UID: &secret.UID, ResourceVersion: &secret.ResourceVersion})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete Secret %s: %w", name, err)
+	}
+	return nil
+}
+
+// rotationDue reports whether the password of the given RegistryUser is to be rotated.
+func rotationDue(user *registryv1alpha1.RegistryUser, now time.Time) bool {
+	last := user.Status.LastRotationTime
+	interval := user.Spec.RotationInterval
+	return last != nil && interval != nil && !now.Before(last.Add(interval.Duration))
+}
+
+// nextUserChange returns the time until the next rotation or the expiry of the given RegistryUser,
+// zero if there is none.
+func nextUserChange(user *registryv1alpha1.RegistryUser, now time.Time) time.Duration {
+	var next time.Time
+	if last, interval := user.Status.LastRotationTime, user.Spec.RotationInterval; last != nil && interval != nil {
+		next = last.Add(interval.Duration)
+	}
+	if expires := user.Spec.ExpiresAt; expires != nil && (next.IsZero() || expires.Before(&metav1.Time{Time: next})) {
+		next = expires.Time
+	}
+	if next.IsZero() {
+		return 0
+	}
+	return max(next.Sub(now), time.Second)
+}
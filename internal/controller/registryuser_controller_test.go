@@ -0,0 +1,191 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/registryclient"
+	"github.com/registry-operator/registry-operator/internal/registryuser"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRegistryUserReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	registry := &registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry", Namespace: "default"},
+		Status: registryv1alpha1.RegistryStatus{
+			Endpoints: []registryv1alpha1.Endpoint{
+				{Type: registryv1alpha1.EndpointTypeClusterIP, Address: "my-registry-registry.default.svc:5000"},
+			},
+		},
+	}
+	user := &registryv1alpha1.RegistryUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "ci", Namespace: "default", Generation: 1},
+		Spec: registryv1alpha1.RegistryUserSpec{
+			RegistryRef:      corev1.LocalObjectReference{Name: registry.Name},
+			RotationInterval: &metav1.Duration{Duration: 24 * time.Hour},
+		},
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&registryv1alpha1.RegistryUser{}).
+		WithObjects(registry, user).
+		Build()
+	r := &RegistryUserReconciler{
+		Client:   cli,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(user)}
+	secretKey := client.ObjectKey{Namespace: "default", Name: "ci-registry-credentials"}
+
+	getCredentials := func(t *testing.T) (*corev1.Secret, *registryclient.Credentials) {
+		t.Helper()
+		secret := &corev1.Secret{}
+		require.NoError(t, cli.Get(t.Context(), secretKey, secret))
+		credentials, err := registryclient.CredentialsFromSecret(secret, "http://"+registry.Status.Endpoints[0].Address)
+		require.NoError(t, err)
+		return secret, credentials
+	}
+
+	var password string
+	t.Run("should generate credentials", func(t *testing.T) {
+		result, err := r.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+		assert.Greater(t, result.RequeueAfter, 23*time.Hour)
+
+		secret, credentials := getCredentials(t)
+		assert.Equal(t, corev1.SecretTypeDockerConfigJson, secret.Type)
+		assert.Equal(t, "ci", credentials.Username)
+		assert.NotEmpty(t, credentials.Password)
+		assert.True(t, strings.HasPrefix(string(secret.Data[registryuser.HtpasswdKey]), "ci:$2a$"))
+		require.NotNil(t, metav1.GetControllerOf(secret))
+		password = credentials.Password
+
+		actual := &registryv1alpha1.RegistryUser{}
+		require.NoError(t, cli.Get(t.Context(), req.NamespacedName, actual))
+		assert.Equal(t, secretKey.Name, actual.Status.SecretName)
+		assert.NotNil(t, actual.Status.LastRotationTime)
+		assert.True(t, meta.IsStatusConditionTrue(actual.Status.Conditions, registryv1alpha1.ConditionTypeReady))
+	})
+
+	t.Run("should keep the password until the rotation", func(t *testing.T) {
+		_, err := r.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+
+		_, credentials := getCredentials(t)
+		assert.Equal(t, password, credentials.Password)
+	})
+
+	t.Run("should rotate the password", func(t *testing.T) {
+		actual := &registryv1alpha1.RegistryUser{}
+		require.NoError(t, cli.Get(t.Context(), req.NamespacedName, actual))
+		patch := client.MergeFrom(actual.DeepCopy())
+		actual.Status.LastRotationTime = &metav1.Time{Time: time.Now().Add(-25 * time.Hour)}
+		require.NoError(t, cli.Status().Patch(t.Context(), actual, patch))
+
+		_, err := r.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+
+		_, credentials := getCredentials(t)
+		assert.NotEqual(t, password, credentials.Password)
+	})
+
+	t.Run("should delete the credentials once expired", func(t *testing.T) {
+		actual := &registryv1alpha1.RegistryUser{}
+		require.NoError(t, cli.Get(t.Context(), req.NamespacedName, actual))
+		actual.Spec.ExpiresAt = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+		require.NoError(t, cli.Update(t.Context(), actual))
+
+		result, err := r.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+
+		err = cli.Get(t.Context(), secretKey, &corev1.Secret{})
+		assert.True(t, apierrors.IsNotFound(err))
+
+		require.NoError(t, cli.Get(t.Context(), req.NamespacedName, actual))
+		assert.Empty(t, actual.Status.SecretName)
+		condition := meta.FindStatusCondition(actual.Status.Conditions, registryv1alpha1.ConditionTypeReady)
+		require.NotNil(t, condition)
+		assert.Equal(t, reasonExpired, condition.Reason)
+	})
+
+	t.Run("should not take over an existing Secret", func(t *testing.T) {
+		existing := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "deploy-key", Namespace: "default"},
+			Data:       map[string][]byte{"key": []byte("value")},
+		}
+		require.NoError(t, cli.Create(t.Context(), existing))
+		thief := user.DeepCopy()
+		thief.Name = "thief"
+		thief.ResourceVersion = ""
+		thief.Spec.SecretName = existing.Name
+		require.NoError(t, cli.Create(t.Context(), thief))
+
+		_, err := r.Reconcile(t.Context(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(thief)})
+		require.NoError(t, err)
+
+		actual := &corev1.Secret{}
+		require.NoError(t, cli.Get(t.Context(), client.ObjectKeyFromObject(existing), actual))
+		assert.Equal(t, existing.Data, actual.Data)
+		assert.Nil(t, metav1.GetControllerOf(actual))
+
+		require.NoError(t, cli.Get(t.Context(), client.ObjectKeyFromObject(thief), thief))
+		condition := meta.FindStatusCondition(thief.Status.Conditions, registryv1alpha1.ConditionTypeReady)
+		require.NotNil(t, condition)
+		assert.Equal(t, reasonSecretConflict, condition.Reason)
+		assert.Empty(t, thief.Status.SecretName)
+	})
+
+	t.Run("should report a missing registry", func(t *testing.T) {
+		orphan := user.DeepCopy()
+		orphan.Name = "orphan"
+		orphan.ResourceVersion = ""
+		orphan.Spec.RegistryRef.Name = "missing"
+		require.NoError(t, cli.Create(t.Context(), orphan))
+
+		_, err := r.Reconcile(t.Context(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(orphan)})
+		require.NoError(t, err)
+
+		actual := &registryv1alpha1.RegistryUser{}
+		require.NoError(t, cli.Get(t.Context(), client.ObjectKeyFromObject(orphan), actual))
+		condition := meta.FindStatusCondition(actual.Status.Conditions, registryv1alpha1.ConditionTypeReady)
+		require.NotNil(t, condition)
+		assert.Equal(t, reasonRegistryNotReady, condition.Reason)
+	})
+}
@@ -0,0 +1,284 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/registryclient"
+	"github.com/registry-operator/registry-operator/internal/retention"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	defaultCleanupInterval = time.Hour
+
+	reasonCleanedUp        = "CleanedUp"
+	reasonCleanupFailed    = "CleanupFailed"
+	reasonInvalidRetention = "InvalidRetention"
+)
+
+var (
+	errInvalidRetention = errors.New("invalid retention policy")
+)
+
+// RepositoryReconciler reconciles a Repository object.
+type RepositoryReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// HTTPClient is used to talk to the Registries, a default client is used if nil.
+	HTTPClient *http.Client
+}
+
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=repositories,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=repositories/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=registries,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RepositoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&registryv1alpha1.Repository{}).
+		Watches(
+			&registryv1alpha1.Registry{},
+			handler.EnqueueRequestsFromMapFunc(r.MapRegistries),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.MapCredentialsSecrets),
+		).
+		Complete(r)
+}
+
+// Reconcile lists the tags of the repository and deletes the manifests expired by its retention policy,
+// once per interval.
+func (r *RepositoryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx, "repository", klog.KRef(req.Namespace, req.Name))
+
+	var instance registryv1alpha1.Repository
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Error(err, "unable to fetch Repository")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// the content of the repository is left in the Registry
+	if instance.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	interval := defaultCleanupInterval
+	if instance.Spec.Interval != nil {
+		interval = instance.Spec.Interval.Duration
+	}
+
+	last := instance.Status.LastCleanupTime
+	if last != nil && instance.Status.ObservedGeneration == instance.Generation {
+		if wait := time.Until(last.Add(interval)); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	changed := instance.DeepCopy()
+	cleanupErr := r.cleanup(ctx, changed)
+
+	changed.Status.ObservedGeneration = changed.Generation
+	condition := metav1.Condition{
+		Type:               registryv1alpha1.ConditionTypeReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: changed.Generation,
+		Reason:             reasonCleanedUp,
+		Message:            fmt.Sprintf("Repository has %d tags", changed.Status.TagCount),
+	}
+	if cleanupErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Message = cleanupErr.Error()
+		switch {
+		case errors.Is(cleanupErr, errRegistryNotReady):
+			condition.Reason = reasonRegistryNotReady
+		case errors.Is(cleanupErr, errInvalidRetention):
+			condition.Reason = reasonInvalidRetention
+		default:
+			condition.Reason = reasonCleanupFailed
+		}
+	}
+	meta.SetStatusCondition(&changed.Status.Conditions, condition)
+
+	if err := r.Status().Patch(ctx, changed, client.MergeFrom(&instance)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to apply status changes to the Repository CR: %w", err)
+	}
+
+	switch {
+	case errors.Is(cleanupErr, errRegistryNotReady), errors.Is(cleanupErr, errInvalidRetention),
+		errors.Is(cleanupErr, errInvalidCredentials):
+		// retried once the Registry, the Repository or the Secrets change
+		r.Recorder.Event(changed, corev1.EventTypeWarning, condition.Reason, cleanupErr.Error())
+		return ctrl.Result{}, nil
+	case cleanupErr != nil:
+		r.Recorder.Event(changed, corev1.EventTypeWarning, condition.Reason, cleanupErr.Error())
+		return ctrl.Result{}, cleanupErr
+	}
+
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// cleanup applies the retention policy of the given Repository and records the outcome in its status.
+func (r *RepositoryReconciler) cleanup(ctx context.Context, changed *registryv1alpha1.Repository) error {
+	cli, err := r.registryClient(ctx, changed)
+	if err != nil {
+		return err
+	}
+
+	name := repositoryName(changed)
+	tags, err := cli.Tags(ctx, name)
+	if err != nil && !errors.Is(err, registryclient.ErrNotFound) {
+		return err
+	}
+
+	now := metav1.Now()
+	policy := changed.Spec.Retention
+	if policy == nil {
+		changed.Status.TagCount = int32(len(tags)) //nolint:gosec // bounded by the registry
+		changed.Status.DeletedManifests = 0
+		changed.Status.LastCleanupTime = &now
+		changed.Status.TaggedDigests = nil
+		changed.Status.UntaggedManifests = nil
+		return nil
+	}
+
+	resolved := make([]retention.Tag, 0, len(tags))
+	for _, tag := range tags {
+		m, err := cli.Manifest(ctx, name, tag)
+		if errors.Is(err, registryclient.ErrNotFound) {
+			// removed meanwhile
+			continue
+		} else if err != nil {
+			return err
+		}
+		resolved = append(resolved, retention.Tag{Name: tag, Digest: m.Digest, Created: m.Created})
+	}
+
+	expired, err := retention.ExpiredTags(*policy, resolved)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errInvalidRetention, err)
+	}
+
+	deleted := sets.New[string]()
+	if err := deleteManifests(ctx, cli, name, expired, deleted); err != nil {
+		return err
+	}
+
+	tagged := sets.New[string]()
+	tagCount := 0
+	for _, tag := range resolved {
+		if !deleted.Has(tag.Digest) {
+			tagged.Insert(tag.Digest)
+			tagCount++
+		}
+	}
+
+	previous, untagged := changed.Status.TaggedDigests, changed.Status.UntaggedManifests
+	changed.Status.TaggedDigests = nil
+	changed.Status.UntaggedManifests = nil
+	if policy.UntaggedOlderThan != nil {
+		tracked := retention.TrackUntagged(untagged, previous, tagged, deleted, now.Time)
+		expired := retention.ExpiredUntagged(tracked, policy.UntaggedOlderThan.Duration, now.Time)
+		if err := deleteManifests(ctx, cli, name, expired, deleted); err != nil {
+			return err
+		}
+		changed.Status.TaggedDigests = sets.List(tagged)
+		changed.Status.UntaggedManifests = retention.TrackUntagged(tracked, nil, tagged, deleted, now.Time)
+	}
+
+	changed.Status.TagCount = int32(tagCount) //nolint:gosec // bounded by the registry
+	changed.Status.DeletedManifests = int32(deleted.Len())
+	changed.Status.LastCleanupTime = &now
+
+	if deleted.Len() > 0 {
+		r.Recorder.Event(changed, corev1.EventTypeNormal, reasonCleanedUp, fmt.Sprintf(
+			"Deleted %d manifests from %s", deleted.Len(), name,
+		))
+	}
+	return nil
+}
+
+// registryClient returns a client for the Registry referenced by the given Repository,
+// reached through its in-cluster Service.
+func (r *RepositoryReconciler) registryClient(
+	ctx context.Context,
+	repository *registryv1alpha1.Repository,
+) (*registryclient.Client, error) {
+	key := client.ObjectKey{Namespace: repository.Namespace, Name: repository.Spec.RegistryRef.Name}
+	baseURL, err := registryURL(ctx, r.Client, key)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := repository.Spec.CredentialsSecretRef
+	credentials, err := registryCredentials(ctx, r.Client, repository.Namespace, ref, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return registryclient.New(baseURL, r.HTTPClient, credentials)
+}
+
+// deleteManifests deletes the manifests with the given digests, recording them as deleted.
+func deleteManifests(
+	ctx context.Context,
+	cli *registryclient.Client,
+	repository string,
+	digests []string,
+	deleted sets.Set[string],
+) error {
+	for _, digest := range digests {
+		if deleted.Has(digest) {
+			continue
+		}
+		if err := cli.DeleteManifest(ctx, repository, digest); err != nil && !errors.Is(err, registryclient.ErrNotFound) {
+			return err
+		}
+		deleted.Insert(digest)
+	}
+	return nil
+}
+
+// repositoryName returns the name of the repository in the Registry.
+func repositoryName(repository *registryv1alpha1.Repository) string {
+	if repository.Spec.Name != "" {
+		return repository.Spec.Name
+	}
+	return repository.Name
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/registryclient"
+	"github.com/registry-operator/registry-operator/internal/registryclient/registrytest"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRepositoryReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	server := registrytest.NewServer(t)
+	created := time.Now().Add(-24 * time.Hour)
+	registrytest.Push(t, server, "team/app", "v1.0.0", created)
+	for i := range 4 {
+		registrytest.Push(t, server, "team/app", fmt.Sprintf("build-%d", i), created.Add(time.Duration(i+1)*time.Hour))
+	}
+	registrytest.Push(t, server, "team/app", "latest", created.Add(4*time.Hour))
+
+	registry := &registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry", Namespace: "default"},
+		Status: registryv1alpha1.RegistryStatus{
+			Endpoints: []registryv1alpha1.Endpoint{
+				{Type: registryv1alpha1.EndpointTypeClusterIP, Address: server.Listener.Addr().String()},
+			},
+		},
+	}
+	repository := &registryv1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: registryv1alpha1.RepositorySpec{
+			RegistryRef: corev1.LocalObjectReference{Name: registry.Name},
+			Name:        "team/app",
+			Retention: &registryv1alpha1.RetentionPolicy{
+				KeepLast:          ptr.To[int32](3),
+				KeepMatching:      []string{`^v\d+\.\d+\.\d+$`},
+				UntaggedOlderThan: &metav1.Duration{},
+			},
+		},
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&registryv1alpha1.Repository{}).
+		WithObjects(registry, repository).
+		Build()
+	r := &RepositoryReconciler{
+		Client:   cli,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(repository)}
+
+	registryClient, err := registryclient.New(server.URL, nil, nil)
+	require.NoError(t, err)
+
+	t.Run("should delete expired tags", func(t *testing.T) {
+		result, err := r.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+		assert.Equal(t, defaultCleanupInterval, result.RequeueAfter)
+
+		tags, err := registryClient.Tags(t.Context(), "team/app")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"v1.0.0", "build-2", "build-3", "latest"}, tags)
+
+		actual := &registryv1alpha1.Repository{}
+		require.NoError(t, cli.Get(t.Context(), req.NamespacedName, actual))
+		assert.EqualValues(t, 4, actual.Status.TagCount)
+		assert.EqualValues(t, 2, actual.Status.DeletedManifests)
+		assert.NotNil(t, actual.Status.LastCleanupTime)
+		assert.Len(t, actual.Status.TaggedDigests, 3)
+		assert.True(t, meta.IsStatusConditionTrue(actual.Status.Conditions, registryv1alpha1.ConditionTypeReady))
+	})
+
+	t.Run("should wait for the next interval", func(t *testing.T) {
+		result, err := r.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+		assert.Greater(t, result.RequeueAfter, time.Duration(0))
+		assert.LessOrEqual(t, result.RequeueAfter, defaultCleanupInterval)
+	})
+
+	t.Run("should delete manifests which lost their tag", func(t *testing.T) {
+		previous, err := registryClient.Manifest(t.Context(), "team/app", "build-2")
+		require.NoError(t, err)
+		registrytest.Push(t, server, "team/app", "build-2", created.Add(5*time.Hour))
+
+		actual := &registryv1alpha1.Repository{}
+		require.NoError(t, cli.Get(t.Context(), req.NamespacedName, actual))
+		patch := client.MergeFrom(actual.DeepCopy())
+		actual.Status.LastCleanupTime = nil
+		require.NoError(t, cli.Status().Patch(t.Context(), actual, patch))
+
+		_, err = r.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+
+		_, err = registryClient.Manifest(t.Context(), "team/app", previous.Digest)
+		assert.ErrorIs(t, err, registryclient.ErrNotFound)
+
+		require.NoError(t, cli.Get(t.Context(), req.NamespacedName, actual))
+		assert.EqualValues(t, 1, actual.Status.DeletedManifests)
+		assert.Empty(t, actual.Status.UntaggedManifests)
+	})
+
+	t.Run("should report a missing registry", func(t *testing.T) {
+		orphan := repository.DeepCopy()
+		orphan.Name = "orphan"
+		orphan.ResourceVersion = ""
+		orphan.Spec.RegistryRef.Name = "missing"
+		require.NoError(t, cli.Create(t.Context(), orphan))
+
+		_, err := r.Reconcile(t.Context(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(orphan)})
+		require.NoError(t, err)
+
+		actual := &registryv1alpha1.Repository{}
+		require.NoError(t, cli.Get(t.Context(), client.ObjectKeyFromObject(orphan), actual))
+		condition := meta.FindStatusCondition(actual.Status.Conditions, registryv1alpha1.ConditionTypeReady)
+		require.NotNil(t, condition)
+		assert.Equal(t, reasonRegistryNotReady, condition.Reason)
+	})
+}
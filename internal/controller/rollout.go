@@ -0,0 +1,42 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/registry"
+)
+
+// setRollbackParams rolls the Registry back to its last known good revision when the rollout of its current
+// revision failed and its rollout policy enables the automatic rollbacks. The Registry rolls forward once its
+// image or configuration changes.
+func setRollbackParams(ctx context.Context, params *manifests.Params) error {
+	reg := &params.Registry
+	good, failed := reg.Status.LastKnownGoodRevision, reg.Status.FailedRevision
+	if rp := reg.Spec.RolloutPolicy; rp == nil || !rp.AutoRollback || good == nil || failed == nil || *good == *failed {
+		return nil
+	}
+
+	revision, err := registry.Revision(ctx, *params)
+	if err != nil {
+		return err
+	}
+	if registry.SameRevision(revision, *failed) {
+		params.Rollback = good
+	}
+	return nil
+}
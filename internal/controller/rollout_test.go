@@ -0,0 +1,63 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/registry"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetRollbackParams(t *testing.T) {
+	params := manifests.Params{Registry: registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry", Namespace: "default"},
+		Spec: registryv1alpha1.RegistrySpec{
+			Image:         "registry:3.0.1",
+			RolloutPolicy: &registryv1alpha1.RolloutPolicy{AutoRollback: true},
+		},
+	}}
+	failed, err := registry.Revision(t.Context(), params)
+	require.NoError(t, err)
+	good := registryv1alpha1.RolloutRevision{Image: "registry:3.0.0", ConfigSecret: failed.ConfigSecret}
+	params.Registry.Status.LastKnownGoodRevision = &good
+	params.Registry.Status.FailedRevision = &failed
+
+	t.Run("rolls back the failed revision", func(t *testing.T) {
+		params := params
+		require.NoError(t, setRollbackParams(t.Context(), &params))
+		assert.Equal(t, &good, params.Rollback)
+	})
+
+	t.Run("rolls forward once the image changed", func(t *testing.T) {
+		params := params
+		params.Registry.Spec.Image = "registry:3.0.2"
+		require.NoError(t, setRollbackParams(t.Context(), &params))
+		assert.Nil(t, params.Rollback)
+	})
+
+	t.Run("does not roll back without auto rollback", func(t *testing.T) {
+		params := params
+		params.Registry.Spec.RolloutPolicy = nil
+		require.NoError(t, setRollbackParams(t.Context(), &params))
+		assert.Nil(t, params.Rollback)
+	})
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
+	"github.com/registry-operator/registry-operator/internal/manifests/registry"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	reasonSnapshotDeleted = "VolumeSnapshotDeleted"
+
+	snapshotTimestampFormat = "20060102-150405"
+)
+
+// takeSnapshots takes the scheduled VolumeSnapshots of the PersistentVolumeClaim of the Registry, and deletes
+// the ones beyond the retention. It returns the time left until the next VolumeSnapshot.
+func (r *RegistryReconciler) takeSnapshots(ctx context.Context, params manifests.Params) (time.Duration, error) {
+	reg := &params.Registry
+	spec := reg.Spec.Snapshots
+	if spec == nil || spec.Schedule == "" {
+		return 0, nil
+	}
+	if reg.Spec.Storage.PersistentVolumeClaimTemplate == nil {
+		return 0, fmt.Errorf("%w: snapshots require a persistentVolumeClaimTemplate storage", manifests.ErrInvalidConfig)
+	}
+	schedule, err := cron.ParseStandard(spec.Schedule)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid snapshot schedule: %w", manifests.ErrInvalidConfig, err)
+	}
+
+	claim, err := r.registryClaim(ctx, reg)
+	if err != nil || claim == nil {
+		// the first VolumeSnapshot is scheduled once the claim exists
+		return 0, err
+	}
+
+	snapshots, err := r.listScheduledSnapshots(ctx, reg, claim)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	last := claim.CreationTimestamp.Time
+	if len(snapshots) > 0 {
+		last = snapshots[len(snapshots)-1].GetCreationTimestamp().Time
+	}
+	if next := schedule.Next(last); next.After(now) {
+		return next.Sub(now), r.pruneSnapshots(ctx, reg, snapshots, int(spec.Retention))
+	}
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	snapshot.SetName(naming.Snapshot(reg.Name, now.UTC().Format(snapshotTimestampFormat)))
+	snapshot.SetNamespace(reg.Namespace)
+	snapshot.SetLabels(manifestutils.SelectorLabels(reg.ObjectMeta, registry.ComponentScheduledSnapshot))
+	if err := unstructured.SetNestedField(
+		snapshot.Object, claim.Name, "spec", "source", "persistentVolumeClaimName",
+	); err != nil {
+		return 0, err
+	}
+	if class := spec.VolumeSnapshotClassName; class != nil {
+		if err := unstructured.SetNestedField(snapshot.Object, *class, "spec", "volumeSnapshotClassName"); err != nil {
+			return 0, err
+		}
+	}
+	if err := r.Create(ctx, snapshot); apierrors.IsAlreadyExists(err) {
+		// taken by a previous reconciliation not yet in the cache
+		return schedule.Next(now).Sub(now), nil
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to create VolumeSnapshot: %w", err)
+	}
+	log.FromContext(ctx).Info("Created VolumeSnapshot", "name", snapshot.GetName())
+	r.Recorder.Eventf(reg, corev1.EventTypeNormal, reasonSnapshotCreated,
+		"VolumeSnapshot %s of PersistentVolumeClaim %s has been created", snapshot.GetName(), claim.Name)
+
+	return schedule.Next(now).Sub(now), r.pruneSnapshots(ctx, reg, append(snapshots, snapshot), int(spec.Retention))
+}
+
+// listScheduledSnapshots returns the scheduled VolumeSnapshots of the given PersistentVolumeClaim, oldest first.
+// The VolumeSnapshots left by a previous Registry of the same name are left out.
+func (r *RegistryReconciler) listScheduledSnapshots(
+	ctx context.Context,
+	reg *registryv1alpha1.Registry,
+	claim *corev1.PersistentVolumeClaim,
+) ([]*unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(volumeSnapshotGVK.GroupVersion().WithKind(volumeSnapshotGVK.Kind + "List"))
+	err := r.List(ctx, list, client.InNamespace(reg.Namespace),
+		client.MatchingLabels(manifestutils.SelectorLabels(reg.ObjectMeta, registry.ComponentScheduledSnapshot)),
+	)
+	if meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("%w: %w", manifests.ErrInvalidConfig, errSnapshotsNotServed)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to list VolumeSnapshots: %w", err)
+	}
+
+	var snapshots []*unstructured.Unstructured
+	for i := range list.Items {
+		snapshot := &list.Items[i]
+		source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+		created := snapshot.GetCreationTimestamp()
+		if source != claim.Name || created.Before(&reg.CreationTimestamp) || snapshot.GetDeletionTimestamp() != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	slices.SortFunc(snapshots, func(a, b *unstructured.Unstructured) int {
+		return cmp.Or(
+			a.GetCreationTimestamp().Compare(b.GetCreationTimestamp().Time),
+			cmp.Compare(a.GetName(), b.GetName()),
+		)
+	})
+	return snapshots, nil
+}
+
+// pruneSnapshots deletes the VolumeSnapshots older than the retained ones, given oldest first. Only the
+// VolumeSnapshots ready to use are retained, the ones still in progress are left alone.
+func (r *RegistryReconciler) pruneSnapshots(
+	ctx context.Context,
+	reg *registryv1alpha1.Registry,
+	snapshots []*unstructured.Unstructured,
+	retention int,
+) error {
+	oldest := len(snapshots)
+	for i := len(snapshots) - 1; i >= 0 && retention > 0; i-- {
+		if ready, _, _ := unstructured.NestedBool(snapshots[i].Object, "status", "readyToUse"); ready {
+			oldest = i
+			retention--
+		}
+	}
+	if retention > 0 {
+		return nil
+	}
+
+	for _, snapshot := range snapshots[:oldest] {
+		if err := r.Delete(ctx, snapshot); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete VolumeSnapshot %s: %w", snapshot.GetName(), err)
+		}
+		log.FromContext(ctx).Info("Deleted VolumeSnapshot", "name", snapshot.GetName())
+		r.Recorder.Eventf(reg, corev1.EventTypeNormal, reasonSnapshotDeleted,
+			"VolumeSnapshot %s has been deleted", snapshot.GetName())
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTakeSnapshots(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	now := time.Now()
+	reg := &registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "my-registry",
+			Namespace:         "default",
+			UID:               "uid",
+			CreationTimestamp: metav1.NewTime(now.Add(-72 * time.Hour)),
+		},
+		Spec: registryv1alpha1.RegistrySpec{
+			Storage: registryv1alpha1.Storage{PersistentVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{}},
+			Snapshots: &registryv1alpha1.Snapshots{
+				Schedule:                "0 2 * * *",
+				Retention:               1,
+				VolumeSnapshotClassName: ptr.To("csi-snapclass"),
+			},
+		},
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "my-registry-registry",
+			Namespace:         "default",
+			CreationTimestamp: reg.CreationTimestamp,
+		},
+	}
+	require.NoError(t, ctrl.SetControllerReference(reg, claim, scheme))
+
+	snapshot := func(name, component, source string, age time.Duration, ready bool) *unstructured.Unstructured {
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+		snapshot.SetName(name)
+		snapshot.SetNamespace("default")
+		snapshot.SetCreationTimestamp(metav1.NewTime(now.Add(-age)))
+		snapshot.SetLabels(map[string]string{
+			"app.kubernetes.io/component":  component,
+			"app.kubernetes.io/instance":   "default.my-registry",
+			"app.kubernetes.io/managed-by": "registry-operator",
+			"app.kubernetes.io/part-of":    "registry",
+		})
+		utilruntime.Must(unstructured.SetNestedField(snapshot.Object, source, "spec", "source", "persistentVolumeClaimName"))
+		utilruntime.Must(unstructured.SetNestedField(snapshot.Object, ready, "status", "readyToUse"))
+		return snapshot
+	}
+
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		claim,
+		snapshot("oldest", "scheduled-snapshot", claim.Name, 60*time.Hour, true),
+		snapshot("ready", "scheduled-snapshot", claim.Name, 48*time.Hour, true),
+		snapshot("in-progress", "scheduled-snapshot", claim.Name, 36*time.Hour, false),
+		snapshot("previous-registry", "scheduled-snapshot", claim.Name, 96*time.Hour, true),
+		snapshot("final", "snapshot", claim.Name, 96*time.Hour, true),
+	).Build()
+	r := &RegistryReconciler{Client: cli, Scheme: scheme, Recorder: record.NewFakeRecorder(10)}
+	params := manifests.Params{Client: cli, Registry: *reg, Scheme: scheme}
+
+	exists := func(t *testing.T, name string) bool {
+		t.Helper()
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+		err := cli.Get(t.Context(), client.ObjectKey{Namespace: "default", Name: name}, snapshot)
+		require.NoError(t, client.IgnoreNotFound(err))
+		return err == nil
+	}
+
+	t.Run("takes the VolumeSnapshot due and prunes the older ones", func(t *testing.T) {
+		wait, err := r.takeSnapshots(t.Context(), params)
+		require.NoError(t, err)
+		assert.Positive(t, wait)
+		assert.LessOrEqual(t, wait, 24*time.Hour)
+
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(volumeSnapshotGVK.GroupVersion().WithKind("VolumeSnapshotList"))
+		require.NoError(t, cli.List(t.Context(), list,
+			client.MatchingLabels{"app.kubernetes.io/component": "scheduled-snapshot"}))
+		var taken *unstructured.Unstructured
+		for i, item := range list.Items {
+			if strings.HasPrefix(item.GetName(), "my-registry-registry-") {
+				taken = &list.Items[i]
+			}
+		}
+		require.NotNil(t, taken)
+		class, _, _ := unstructured.NestedString(taken.Object, "spec", "volumeSnapshotClassName")
+		assert.Equal(t, "csi-snapclass", class)
+
+		// set by the API server
+		taken.SetCreationTimestamp(metav1.NewTime(now))
+		require.NoError(t, cli.Update(t.Context(), taken))
+
+		assert.False(t, exists(t, "oldest"))
+		assert.True(t, exists(t, "ready"))
+		assert.True(t, exists(t, "in-progress"))
+		assert.True(t, exists(t, "previous-registry"))
+		assert.True(t, exists(t, "final"))
+	})
+
+	t.Run("waits for the next scheduled VolumeSnapshot", func(t *testing.T) {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(volumeSnapshotGVK.GroupVersion().WithKind("VolumeSnapshotList"))
+		require.NoError(t, cli.List(t.Context(), list))
+		count := len(list.Items)
+
+		wait, err := r.takeSnapshots(t.Context(), params)
+		require.NoError(t, err)
+		assert.Positive(t, wait)
+
+		require.NoError(t, cli.List(t.Context(), list))
+		assert.Len(t, list.Items, count)
+	})
+
+	t.Run("requires a persistentVolumeClaimTemplate", func(t *testing.T) {
+		params := params
+		params.Registry.Spec.Storage = registryv1alpha1.Storage{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+		}
+
+		_, err := r.takeSnapshots(t.Context(), params)
+		assert.ErrorIs(t, err, manifests.ErrInvalidConfig)
+	})
+}
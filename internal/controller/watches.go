@@ -15,13 +15,24 @@
 package controller
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"reflect"
 
 	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
+	"github.com/registry-operator/registry-operator/internal/manifests/registry"
+	"github.com/registry-operator/registry-operator/internal/naming"
+	registrystatus "github.com/registry-operator/registry-operator/internal/status/registry"
 
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -34,6 +45,8 @@ var watchLogger = ctrl.Log.WithName("watch")
 
 var errInvalidType = errors.New("invalid type")
 
+// MapS3Secrets enqueues the Registries referencing the given Secret in their S3 storage or proxy
+// configuration, both rendered into the configuration of the Registry.
 func (r *RegistryReconciler) MapS3Secrets(ctx context.Context, obj client.Object) []reconcile.Request {
 	if _, ok := obj.(*corev1.Secret); !ok {
 		t := reflect.TypeOf(obj).String()
@@ -50,25 +63,29 @@ func (r *RegistryReconciler) MapS3Secrets(ctx context.Context, obj client.Object
 	objects := map[types.UID]types.NamespacedName{}
 
 	for _, reg := range list.Items {
-		if s3 := reg.Spec.Storage.S3; s3 != nil {
-			name := obj.GetName()
+		name := obj.GetName()
 
-			refs := []*registryv1alpha1.SecretKeySelector{
+		var refs []*registryv1alpha1.SecretKeySelector
+		if s3 := reg.Spec.Storage.S3; s3 != nil {
+			refs = append(refs,
 				&s3.BucketName,
 				&s3.Region,
 				s3.AccessKey,
 				s3.SecretKey,
 				s3.EndpointURL,
-			}
+			)
+		}
+		if proxy := reg.Spec.Proxy; proxy != nil {
+			refs = append(refs, proxy.Username, proxy.Password)
+		}
 
-			for _, ref := range refs {
-				if ref != nil && ref.Name == name {
-					objects[reg.GetUID()] = types.NamespacedName{
-						Name:      reg.GetName(),
-						Namespace: reg.GetNamespace(),
-					}
-					break // only need one match
+		for _, ref := range refs {
+			if ref != nil && ref.Name == name {
+				objects[reg.GetUID()] = types.NamespacedName{
+					Name:      reg.GetName(),
+					Namespace: reg.GetNamespace(),
 				}
+				break // only need one match
 			}
 		}
 	}
@@ -82,7 +99,7 @@ func (r *RegistryReconciler) MapS3Secrets(ctx context.Context, obj client.Object
 }
 
 var (
-	s3SecretPredicate predicate.Funcs = predicate.Funcs{
+	secretDataPredicate predicate.Funcs = predicate.Funcs{
 		CreateFunc: func(_ event.TypedCreateEvent[client.Object]) bool { return true },
 		DeleteFunc: func(_ event.TypedDeleteEvent[client.Object]) bool { return true },
 		UpdateFunc: func(e event.TypedUpdateEvent[client.Object]) bool {
@@ -106,3 +123,547 @@ var (
 		},
 	}
 )
+
+// MapIngresses enqueues the Registries whose Service is a backend of the given Ingress,
+// so that their published endpoints are refreshed.
+func (r *RegistryReconciler) MapIngresses(ctx context.Context, obj client.Object) []reconcile.Request {
+	ing, ok := obj.(*networkingv1.Ingress)
+	if !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected Ingress", "type", t)
+		return nil
+	}
+
+	services := map[string]struct{}{}
+	if b := ing.Spec.DefaultBackend; b != nil && b.Service != nil {
+		services[b.Service.Name] = struct{}{}
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				services[path.Backend.Service.Name] = struct{}{}
+			}
+		}
+	}
+	if len(services) == 0 {
+		return nil
+	}
+
+	list := &registryv1alpha1.RegistryList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+		watchLogger.Error(err, "Failed to list Registries", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, reg := range list.Items {
+		if _, ok := services[naming.Service(reg.Name)]; ok {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      reg.GetName(),
+				Namespace: reg.GetNamespace(),
+			}})
+		}
+	}
+
+	return reqs
+}
+
+// MapHTTPRoutes enqueues the Registries whose Service is a backend of the given HTTPRoute,
+// so that their published endpoints are refreshed.
+func (r *RegistryReconciler) MapHTTPRoutes(ctx context.Context, obj client.Object) []reconcile.Request {
+	route, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected HTTPRoute", "type", t)
+		return nil
+	}
+
+	services := map[types.NamespacedName]struct{}{}
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	for _, rule := range rules {
+		rule, ok := rule.(map[string]any)
+		if !ok {
+			continue
+		}
+		refs, _, _ := unstructured.NestedSlice(rule, "backendRefs")
+		for _, ref := range refs {
+			ref, ok := ref.(map[string]any)
+			if !ok {
+				continue
+			}
+			if kind, _, _ := unstructured.NestedString(ref, "kind"); kind != "" && kind != "Service" {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(ref, "name")
+			namespace, _, _ := unstructured.NestedString(ref, "namespace")
+			services[types.NamespacedName{Namespace: cmp.Or(namespace, route.GetNamespace()), Name: name}] = struct{}{}
+		}
+	}
+
+	namespaces := map[string]struct{}{}
+	for svc := range services {
+		namespaces[svc.Namespace] = struct{}{}
+	}
+
+	reqs := []reconcile.Request{}
+	for namespace := range namespaces {
+		list := &registryv1alpha1.RegistryList{}
+		if err := r.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			watchLogger.Error(err, "Failed to list Registries", "namespace", namespace)
+			continue
+		}
+		for _, reg := range list.Items {
+			svc := types.NamespacedName{Namespace: reg.Namespace, Name: naming.Service(reg.Name)}
+			if _, ok := services[svc]; ok {
+				reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+					Name:      reg.GetName(),
+					Namespace: reg.GetNamespace(),
+				}})
+			}
+		}
+	}
+
+	return reqs
+}
+
+// MapGateways enqueues the Registries published by the HTTPRoutes attached to the given Gateway,
+// whose listeners tell whether the endpoints use TLS.
+func (r *RegistryReconciler) MapGateways(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*unstructured.Unstructured); !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected Gateway", "type", t)
+		return nil
+	}
+
+	routes := &unstructured.UnstructuredList{}
+	routes.SetGroupVersionKind(registrystatus.HTTPRouteGVK.GroupVersion().WithKind(
+		registrystatus.HTTPRouteGVK.Kind + "List"))
+	if err := r.List(ctx, routes); err != nil {
+		watchLogger.Error(err, "Failed to list HTTPRoutes")
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, route := range routes.Items {
+		parents, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+		for _, parent := range parents {
+			ref, ok := parent.(map[string]any)
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(ref, "name")
+			kind, _, _ := unstructured.NestedString(ref, "kind")
+			namespace, _, _ := unstructured.NestedString(ref, "namespace")
+			if name == obj.GetName() && cmp.Or(namespace, route.GetNamespace()) == obj.GetNamespace() &&
+				(kind == "" || kind == registrystatus.GatewayGVK.Kind) {
+				reqs = append(reqs, r.MapHTTPRoutes(ctx, &route)...)
+				break
+			}
+		}
+	}
+
+	return reqs
+}
+
+// MapRegistryClasses enqueues the Registries naming the given RegistryClass.
+func (r *RegistryReconciler) MapRegistryClasses(ctx context.Context, obj client.Object) []reconcile.Request {
+	class, ok := obj.(*registryv1alpha1.RegistryClass)
+	if !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected RegistryClass", "type", t)
+		return nil
+	}
+
+	list := &registryv1alpha1.RegistryList{}
+	if err := r.List(ctx, list); err != nil {
+		watchLogger.Error(err, "Failed to list Registries")
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, reg := range list.Items {
+		if reg.Spec.RegistryClassName == class.Name {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      reg.GetName(),
+				Namespace: reg.GetNamespace(),
+			}})
+		}
+	}
+
+	return reqs
+}
+
+// MapRegistryUsers enqueues the Registry of the given RegistryUser, so that its htpasswd file is updated.
+func (r *RegistryReconciler) MapRegistryUsers(_ context.Context, obj client.Object) []reconcile.Request {
+	user, ok := obj.(*registryv1alpha1.RegistryUser)
+	if !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected RegistryUser", "type", t)
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name:      user.Spec.RegistryRef.Name,
+		Namespace: user.GetNamespace(),
+	}}}
+}
+
+// MapRegistryUserSecrets enqueues the Registry of the RegistryUser owning the given Secret,
+// so that its htpasswd file follows the rotation of the passwords.
+func (r *RegistryReconciler) MapRegistryUserSecrets(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*corev1.Secret); !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected Secret", "type", t)
+		return nil
+	}
+
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.Kind != registryv1alpha1.RegistryUserKind ||
+		owner.APIVersion != registryv1alpha1.GroupVersion.String() {
+		return nil
+	}
+
+	user := &registryv1alpha1.RegistryUser{}
+	key := types.NamespacedName{Name: owner.Name, Namespace: obj.GetNamespace()}
+	if err := r.Get(ctx, key, user); err != nil {
+		if !apierrors.IsNotFound(err) {
+			watchLogger.Error(err, "Failed to get RegistryUser", "registryuser", key)
+		}
+		return nil
+	}
+
+	return r.MapRegistryUsers(ctx, user)
+}
+
+// MapPullSecrets enqueues the Registries distributing the given Secret, or owning the given copy of their
+// pull Secret, so that the copies are kept in sync with their source.
+func (r *RegistryReconciler) MapPullSecrets(ctx context.Context, obj client.Object) []reconcile.Request {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected Secret", "type", t)
+		return nil
+	}
+
+	list := &registryv1alpha1.RegistryList{}
+	if err := r.List(ctx, list); err != nil {
+		watchLogger.Error(err, "Failed to list Registries")
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, reg := range list.Items {
+		d := reg.Spec.PullSecretDistribution
+		isSource := d != nil && reg.Namespace == secret.Namespace && d.SecretRef.Name == secret.Name
+		if isSource || isPullSecretOf(secret, reg) {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      reg.GetName(),
+				Namespace: reg.GetNamespace(),
+			}})
+		}
+	}
+
+	return reqs
+}
+
+// MapNamespaces enqueues the Registries distributing their pull Secret, so that it follows the namespaces
+// entering and leaving their selector.
+func (r *RegistryReconciler) MapNamespaces(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*corev1.Namespace); !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected Namespace", "type", t)
+		return nil
+	}
+
+	return r.mapPullSecretDistributions(ctx, false)
+}
+
+// MapServiceAccounts enqueues the Registries patching the default ServiceAccounts, so that their pull Secret
+// is added to the ServiceAccounts created after it was distributed.
+func (r *RegistryReconciler) MapServiceAccounts(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*corev1.ServiceAccount); !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected ServiceAccount", "type", t)
+		return nil
+	}
+	if obj.GetName() != defaultServiceAccount {
+		return nil
+	}
+
+	return r.mapPullSecretDistributions(ctx, true)
+}
+
+// mapPullSecretDistributions enqueues the Registries distributing their pull Secret, only the ones patching
+// the default ServiceAccounts if patchingOnly is set.
+func (r *RegistryReconciler) mapPullSecretDistributions(ctx context.Context, patchingOnly bool) []reconcile.Request {
+	list := &registryv1alpha1.RegistryList{}
+	if err := r.List(ctx, list); err != nil {
+		watchLogger.Error(err, "Failed to list Registries")
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, reg := range list.Items {
+		d := reg.Spec.PullSecretDistribution
+		if d == nil || (patchingOnly && !d.PatchDefaultServiceAccount) {
+			continue
+		}
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+			Name:      reg.GetName(),
+			Namespace: reg.GetNamespace(),
+		}})
+	}
+
+	return reqs
+}
+
+// MapBackupJobs enqueues the Registry backed up by the given Job, so that it is switched to read-only mode
+// while the Job runs and its last backup is recorded.
+func (r *RegistryReconciler) MapBackupJobs(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*batchv1.Job); !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected Job", "type", t)
+		return nil
+	}
+	if obj.GetLabels()["app.kubernetes.io/component"] != registry.ComponentBackup {
+		return nil
+	}
+
+	list := &registryv1alpha1.RegistryList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+		watchLogger.Error(err, "Failed to list Registries", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, reg := range list.Items {
+		selector := labels.SelectorFromSet(manifestutils.SelectorLabels(reg.ObjectMeta, registry.ComponentBackup))
+		if selector.Matches(labels.Set(obj.GetLabels())) {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      reg.GetName(),
+				Namespace: reg.GetNamespace(),
+			}})
+		}
+	}
+
+	return reqs
+}
+
+// MapScheduledSnapshots enqueues the Registry of the given scheduled VolumeSnapshot, so that the older ones are
+// pruned and its last VolumeSnapshot is recorded once it is ready to use.
+func (r *RegistryReconciler) MapScheduledSnapshots(ctx context.Context, obj client.Object) []reconcile.Request {
+	if obj.GetLabels()["app.kubernetes.io/component"] != registry.ComponentScheduledSnapshot {
+		return nil
+	}
+
+	list := &registryv1alpha1.RegistryList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+		watchLogger.Error(err, "Failed to list Registries", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, reg := range list.Items {
+		selector := labels.SelectorFromSet(manifestutils.SelectorLabels(reg.ObjectMeta, registry.ComponentScheduledSnapshot))
+		if selector.Matches(labels.Set(obj.GetLabels())) {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      reg.GetName(),
+				Namespace: reg.GetNamespace(),
+			}})
+		}
+	}
+
+	return reqs
+}
+
+// MapRegistryRestores enqueues the Registry of the given RegistryRestore, so that it is scaled down
+// while its storage is restored.
+func (r *RegistryReconciler) MapRegistryRestores(_ context.Context, obj client.Object) []reconcile.Request {
+	restore, ok := obj.(*registryv1alpha1.RegistryRestore)
+	if !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected RegistryRestore", "type", t)
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name:      restore.Spec.RegistryRef.Name,
+		Namespace: restore.GetNamespace(),
+	}}}
+}
+
+// MapRegistries enqueues the Repositories of the given Registry, so that they are cleaned up
+// as soon as the Registry can be reached.
+func (r *RepositoryReconciler) MapRegistries(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*registryv1alpha1.Registry); !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected Registry", "type", t)
+		return nil
+	}
+
+	list := &registryv1alpha1.RepositoryList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+		watchLogger.Error(err, "Failed to list Repositories", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, repo := range list.Items {
+		if repo.Spec.RegistryRef.Name == obj.GetName() {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      repo.GetName(),
+				Namespace: repo.GetNamespace(),
+			}})
+		}
+	}
+
+	return reqs
+}
+
+// MapCredentialsSecrets enqueues the Repositories using the given Secret as credentials.
+func (r *RepositoryReconciler) MapCredentialsSecrets(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*corev1.Secret); !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected Secret", "type", t)
+		return nil
+	}
+
+	list := &registryv1alpha1.RepositoryList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+		watchLogger.Error(err, "Failed to list Repositories", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, repo := range list.Items {
+		if ref := repo.Spec.CredentialsSecretRef; ref != nil && ref.Name == obj.GetName() {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      repo.GetName(),
+				Namespace: repo.GetNamespace(),
+			}})
+		}
+	}
+
+	return reqs
+}
+
+// MapRegistries enqueues the ImageSyncs from or to the given Registry, so that they are synced
+// as soon as the Registry can be reached.
+func (r *ImageSyncReconciler) MapRegistries(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*registryv1alpha1.Registry); !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected Registry", "type", t)
+		return nil
+	}
+
+	list := &registryv1alpha1.ImageSyncList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+		watchLogger.Error(err, "Failed to list ImageSyncs", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, imageSync := range list.Items {
+		source := imageSync.Spec.Source.RegistryRef
+		if imageSync.Spec.Target.RegistryRef.Name == obj.GetName() || (source != nil && source.Name == obj.GetName()) {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      imageSync.GetName(),
+				Namespace: imageSync.GetNamespace(),
+			}})
+		}
+	}
+
+	return reqs
+}
+
+// MapCredentialsSecrets enqueues the ImageSyncs using the given Secret as credentials.
+func (r *ImageSyncReconciler) MapCredentialsSecrets(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*corev1.Secret); !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected Secret", "type", t)
+		return nil
+	}
+
+	list := &registryv1alpha1.ImageSyncList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+		watchLogger.Error(err, "Failed to list ImageSyncs", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, imageSync := range list.Items {
+		for _, ref := range []*corev1.LocalObjectReference{
+			imageSync.Spec.Source.CredentialsSecretRef,
+			imageSync.Spec.Target.CredentialsSecretRef,
+		} {
+			if ref != nil && ref.Name == obj.GetName() {
+				reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+					Name:      imageSync.GetName(),
+					Namespace: imageSync.GetNamespace(),
+				}})
+				break
+			}
+		}
+	}
+
+	return reqs
+}
+
+// MapRegistries enqueues the RegistryUsers of the given Registry, so that their Secrets follow
+// the endpoints of the Registry.
+func (r *RegistryUserReconciler) MapRegistries(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*registryv1alpha1.Registry); !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected Registry", "type", t)
+		return nil
+	}
+
+	list := &registryv1alpha1.RegistryUserList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+		watchLogger.Error(err, "Failed to list RegistryUsers", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, user := range list.Items {
+		if user.Spec.RegistryRef.Name == obj.GetName() {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      user.GetName(),
+				Namespace: user.GetNamespace(),
+			}})
+		}
+	}
+
+	return reqs
+}
+
+// MapRegistries enqueues the RegistryRestores of the given Registry, so that they are started once it is
+// scaled down.
+func (r *RegistryRestoreReconciler) MapRegistries(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*registryv1alpha1.Registry); !ok {
+		t := reflect.TypeOf(obj).String()
+		watchLogger.Error(errInvalidType, "Invalid type of Object, expected Registry", "type", t)
+		return nil
+	}
+
+	list := &registryv1alpha1.RegistryRestoreList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+		watchLogger.Error(err, "Failed to list RegistryRestores", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, restore := range list.Items {
+		if restore.Spec.RegistryRef.Name == obj.GetName() {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      restore.GetName(),
+				Namespace: restore.GetNamespace(),
+			}})
+		}
+	}
+
+	return reqs
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	registrystatus "github.com/registry-operator/registry-operator/internal/status/registry"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestMapGatewayAPI(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	registry := &registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-instance", Namespace: "my-namespace"},
+	}
+	route := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata":   map[string]any{"name": "registry", "namespace": "my-namespace"},
+		"spec": map[string]any{
+			"parentRefs": []any{map[string]any{"name": "gateway", "namespace": "gateways"}},
+			"rules": []any{map[string]any{
+				"backendRefs": []any{map[string]any{"name": "my-instance-registry", "port": int64(5000)}},
+			}},
+		},
+	}}
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(registrystatus.GatewayGVK)
+	gateway.SetNamespace("gateways")
+	gateway.SetName("gateway")
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(registrystatus.HTTPRouteGVK, meta.RESTScopeNamespace)
+	mapper.Add(registrystatus.GatewayGVK, meta.RESTScopeNamespace)
+	for gvk := range scheme.AllKnownTypes() {
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+	r := &RegistryReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(registry, route).Build(),
+	}
+	expected := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "my-namespace", Name: "my-instance"}},
+	}
+
+	t.Run("should enqueue the Registries backing the HTTPRoute", func(t *testing.T) {
+		assert.Equal(t, expected, r.MapHTTPRoutes(t.Context(), route))
+	})
+
+	t.Run("should enqueue the Registries routed through the Gateway", func(t *testing.T) {
+		assert.Equal(t, expected, r.MapGateways(t.Context(), gateway))
+
+		other := gateway.DeepCopy()
+		other.SetNamespace("my-namespace")
+		assert.Empty(t, r.MapGateways(t.Context(), other))
+	})
+}
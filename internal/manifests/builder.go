@@ -19,11 +19,15 @@ package manifests
 
 import (
 	"context"
+	"errors"
 	"reflect"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// ErrInvalidConfig marks the errors returned while building the manifests from the instance spec.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
 type Builder[Params any] func(ctx context.Context, params Params) ([]client.Object, error)
 
 type ManifestFactory[T client.Object, Params any] func(ctx context.Context, params Params) (T, error)
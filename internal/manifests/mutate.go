@@ -22,9 +22,12 @@ import (
 	"reflect"
 
 	"dario.cat/mergo"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -49,6 +52,10 @@ var (
 // - Secret
 // - Service
 // - PersistentVolumeClaim
+// - PrometheusRule
+// - NetworkPolicy
+// - CronJob
+// - ConfigMap
 // In order for the operator to reconcile other types, they must be added here.
 // The function returned takes no arguments but instead uses the existing and desired inputs here. Existing is expected
 // to be set by the controller-runtime package through a client get call.
@@ -80,6 +87,21 @@ func MutateFuncFor(existing, desired client.Object) controllerutil.MutateFn {
 			wantDpl := desired.(*appsv1.Deployment)
 			return mutateDeployment(dpl, wantDpl)
 
+		case *appsv1.DaemonSet:
+			ds := existing.(*appsv1.DaemonSet)
+			wantDs := desired.(*appsv1.DaemonSet)
+			return mutateDaemonSet(ds, wantDs)
+
+		case *batchv1.CronJob:
+			cj := existing.(*batchv1.CronJob)
+			wantCj := desired.(*batchv1.CronJob)
+			return mutateCronJob(cj, wantCj)
+
+		case *corev1.ConfigMap:
+			cm := existing.(*corev1.ConfigMap)
+			wantCm := desired.(*corev1.ConfigMap)
+			mutateConfigMap(cm, wantCm)
+
 		case *corev1.Secret:
 			sec := existing.(*corev1.Secret)
 			wantSec := desired.(*corev1.Secret)
@@ -95,6 +117,16 @@ func MutateFuncFor(existing, desired client.Object) controllerutil.MutateFn {
 			wantSvc := desired.(*corev1.Service)
 			mutateService(svc, wantSvc)
 
+		case *monitoringv1.PrometheusRule:
+			rule := existing.(*monitoringv1.PrometheusRule)
+			wantRule := desired.(*monitoringv1.PrometheusRule)
+			mutatePrometheusRule(rule, wantRule)
+
+		case *networkingv1.NetworkPolicy:
+			np := existing.(*networkingv1.NetworkPolicy)
+			wantNp := desired.(*networkingv1.NetworkPolicy)
+			mutateNetworkPolicy(np, wantNp)
+
 		default:
 			t := reflect.TypeOf(existing).String()
 			return fmt.Errorf("missing mutate implementation for resource type: %s", t)
@@ -116,6 +148,19 @@ func mutateService(existing, desired *corev1.Service) {
 	existing.Spec.Selector = desired.Spec.Selector
 }
 
+func mutatePrometheusRule(existing, desired *monitoringv1.PrometheusRule) {
+	existing.Spec = desired.Spec
+}
+
+func mutateNetworkPolicy(existing, desired *networkingv1.NetworkPolicy) {
+	existing.Spec = desired.Spec
+}
+
+func mutateConfigMap(existing, desired *corev1.ConfigMap) {
+	existing.Data = desired.Data
+	existing.BinaryData = desired.BinaryData
+}
+
 func mutateSecret(existing, desired *corev1.Secret) {
 	existing.Data = desired.Data
 	existing.StringData = desired.StringData
@@ -168,3 +213,39 @@ func mutateDeployment(existing, desired *appsv1.Deployment) error {
 
 	return nil
 }
+
+func mutateDaemonSet(existing, desired *appsv1.DaemonSet) error {
+	if !existing.CreationTimestamp.IsZero() {
+		if !apiequality.Semantic.DeepEqual(desired.Spec.Selector, existing.Spec.Selector) {
+			return &ImmutableFieldChangeErr{Field: "Spec.Selector"}
+		}
+		if err := hasImmutableLabelChange(existing.Spec.Selector.MatchLabels, desired.Spec.Template.Labels); err != nil {
+			return err
+		}
+	}
+
+	existing.Spec.MinReadySeconds = desired.Spec.MinReadySeconds
+	existing.Spec.RevisionHistoryLimit = desired.Spec.RevisionHistoryLimit
+	existing.Spec.UpdateStrategy = desired.Spec.UpdateStrategy
+
+	if err := mutatePodTemplate(&existing.Spec.Template, &desired.Spec.Template); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func mutateCronJob(existing, desired *batchv1.CronJob) error {
+	existing.Spec.Schedule = desired.Spec.Schedule
+	existing.Spec.TimeZone = desired.Spec.TimeZone
+	existing.Spec.ConcurrencyPolicy = desired.Spec.ConcurrencyPolicy
+	existing.Spec.Suspend = desired.Spec.Suspend
+
+	job, wantJob := &existing.Spec.JobTemplate, &desired.Spec.JobTemplate
+	if err := mergeWithOverride(&job.Labels, wantJob.Labels); err != nil {
+		return err
+	}
+	job.Spec.BackoffLimit = wantJob.Spec.BackoffLimit
+
+	return mutatePodTemplate(&job.Spec.Template, &wantJob.Spec.Template)
+}
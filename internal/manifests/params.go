@@ -31,4 +31,59 @@ type Params struct {
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
 	Registry registryv1alpha1.Registry
+
+	// Htpasswd is the content of the htpasswd file authenticating the RegistryUsers of the Registry,
+	// empty when the Registry has no user or does not enable authentication.
+	Htpasswd string
+
+	// OperatorImage is the image of the operator, running the backup, restore and migration Jobs.
+	OperatorImage string
+
+	// OperatorNamespace is the namespace of the operator Pods, allowed to reach the Registry.
+	OperatorNamespace string
+
+	// ReadOnly switches the Registry to read-only mode while a backup Job is running or its storage is migrated.
+	ReadOnly bool
+
+	// ReadOnlyReady is set once every replica of the Registry serves in read-only mode.
+	ReadOnlyReady bool
+
+	// Restoring scales the Registry down while a RegistryRestore replaces its storage.
+	Restoring bool
+
+	// Migration is set while the images are migrated to the storage of the Registry, which serves them from
+	// the Source of the Migration until it succeeded.
+	Migration *StorageMigration
+
+	// Rollback is the revision the Registry Deployment is rolled back to, set when the rollout of the current
+	// revision failed.
+	Rollback *registryv1alpha1.RolloutRevision
+}
+
+// StorageMigrationPhase is the progress of a StorageMigration.
+type StorageMigrationPhase string
+
+const (
+	// StorageMigrationPending waits for the Registry to serve in read-only mode before starting the Job.
+	StorageMigrationPending StorageMigrationPhase = "Pending"
+	// StorageMigrationRunning copies the images and verifies their digests.
+	StorageMigrationRunning StorageMigrationPhase = "Running"
+	// StorageMigrationSucceeded switches the Registry to the Target of the migration.
+	StorageMigrationSucceeded StorageMigrationPhase = "Succeeded"
+	// StorageMigrationVerificationFailed keeps the Registry on the Source, the copy not matching it.
+	StorageMigrationVerificationFailed StorageMigrationPhase = "VerificationFailed"
+	// StorageMigrationFailed keeps the Registry on the Source, the copy having failed.
+	StorageMigrationFailed StorageMigrationPhase = "Failed"
+)
+
+// StorageMigration describes the migration of the images of a Registry to a new storage.
+type StorageMigration struct {
+	// Source is the storage the images are migrated from.
+	Source registryv1alpha1.Storage
+	// Target is the storage the images are migrated to.
+	Target registryv1alpha1.Storage
+	// Phase is the progress of the migration.
+	Phase StorageMigrationPhase
+	// Message describes the failure of the migration.
+	Message string
 }
@@ -0,0 +1,276 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/backup"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// ReadOnlyAnnotation is set on the pod template of the Registry while it serves in read-only mode.
+const ReadOnlyAnnotation = "registry-operator.dev/read-only"
+
+// envReadOnly overrides the read-only maintenance mode of the storage, set on the container rather than in the
+// configuration so that the Secret holding it, and the revision of the Registry, do not change with the mode.
+const envReadOnly = "REGISTRY_STORAGE_MAINTENANCE_READONLY"
+
+const (
+	backupContainer      = "backup"
+	backupStateVolume    = "backup-state"
+	backupStateMountPath = "/etc/registry-backup"
+	backupBackoffLimit   = 2
+	restoreContainer     = "restore"
+)
+
+var (
+	errNoOperatorImage = fmt.Errorf("%w: the image of the operator is unknown, set OPERATOR_IMAGE",
+		manifests.ErrInvalidConfig)
+	errBackupStorage = fmt.Errorf("%w: backups require a persistentVolumeClaim or persistentVolumeClaimTemplate storage",
+		manifests.ErrInvalidConfig)
+)
+
+// BackupCronJob builds the CronJob backing up the storage of the Registry. Its Jobs wait for the Registry
+// to be read-only before archiving the storage, mounted read-only next to the Registry Pods.
+func BackupCronJob(_ context.Context, params manifests.Params) (*batchv1.CronJob, error) {
+	spec := params.Registry.Spec.Backup
+	if spec == nil {
+		return nil, nil
+	}
+	if params.OperatorImage == "" {
+		return nil, errNoOperatorImage
+	}
+	if storage := params.Registry.Spec.Storage; storage.PersistentVolumeClaim == nil &&
+		storage.PersistentVolumeClaimTemplate == nil {
+		return nil, errBackupStorage
+	}
+
+	name := naming.Backup(params.Registry.Name)
+	labels := manifestutils.Labels(params.Registry.ObjectMeta, name, params.OperatorImage, ComponentBackup, nil)
+	annotations, err := manifestutils.Annotations(params.Registry, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	env := append(destinationEnv(spec.Destination),
+		corev1.EnvVar{
+			Name: backup.EnvName,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.labels['batch.kubernetes.io/job-name']"},
+			},
+		},
+		corev1.EnvVar{Name: backup.EnvSeries, Value: name},
+		corev1.EnvVar{Name: backup.EnvRetention, Value: strconv.Itoa(int(spec.Retention))},
+		corev1.EnvVar{Name: backup.EnvStorageRoot, Value: storageMountPath},
+		corev1.EnvVar{Name: backup.EnvReadOnlyFile, Value: path.Join(backupStateMountPath, backup.ReadOnlyKey)},
+	)
+
+	podSpec := jobPodSpec(params, backupContainer, backup.CommandBackup, env, true)
+	podSpec.Volumes = append(podSpec.Volumes,
+		corev1.Volume{
+			Name: backupStateVolume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: naming.BackupState(params.Registry.Name)},
+				},
+			},
+		},
+	)
+	podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts,
+		corev1.VolumeMount{Name: backupStateVolume, MountPath: backupStateMountPath, ReadOnly: true},
+	)
+	// the Pods share the volume of the Registry, which may only be attached to its node
+	podSpec.Affinity = registryPodAffinity(params)
+
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   params.Registry.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          spec.Schedule,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: ptr.To[int32](backupBackoffLimit),
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec:       podSpec,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// BackupState builds the ConfigMap telling the backup Jobs whether every replica of the Registry serves
+// in read-only mode.
+func BackupState(_ context.Context, params manifests.Params) (*corev1.ConfigMap, error) {
+	if params.Registry.Spec.Backup == nil {
+		return nil, nil
+	}
+
+	name := naming.BackupState(params.Registry.Name)
+	labels := manifestutils.Labels(params.Registry.ObjectMeta, name, params.OperatorImage, ComponentBackup, nil)
+	annotations, err := manifestutils.Annotations(params.Registry, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   params.Registry.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Data: map[string]string{
+			backup.ReadOnlyKey: strconv.FormatBool(params.ReadOnlyReady),
+		},
+	}, nil
+}
+
+// RestoreJob builds the Job replacing the storage of the Registry with the backup of the given RegistryRestore,
+// read from its source or else from the backup destination of the Registry.
+func RestoreJob(params manifests.Params, restore *registryv1alpha1.RegistryRestore) (*batchv1.Job, error) {
+	if params.OperatorImage == "" {
+		return nil, errNoOperatorImage
+	}
+	var source registryv1alpha1.BackupDestination
+	switch {
+	case restore.Spec.Source != nil:
+		source = *restore.Spec.Source
+	case params.Registry.Spec.Backup != nil:
+		source = params.Registry.Spec.Backup.Destination
+	default:
+		return nil, fmt.Errorf("%w: the source of the backup is not set and the Registry has no backup destination",
+			manifests.ErrInvalidConfig)
+	}
+
+	name := naming.RestoreJob(restore.Name)
+	labels := manifestutils.Labels(params.Registry.ObjectMeta, name, params.OperatorImage, ComponentRestore, nil)
+
+	env := append(destinationEnv(source),
+		corev1.EnvVar{Name: backup.EnvName, Value: restore.Spec.BackupName},
+		corev1.EnvVar{Name: backup.EnvStorageRoot, Value: storageMountPath},
+	)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: restore.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To[int32](backupBackoffLimit),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       jobPodSpec(params, restoreContainer, backup.CommandRestore, env, false),
+			},
+		},
+	}, nil
+}
+
+// jobPodSpec returns the spec of the Pods running the given command of the operator image on the storage
+// of the Registry, streaming the archive from or to the bucket. They run as root, like the Registry owning
+// the stored files.
+func jobPodSpec(
+	params manifests.Params,
+	container, command string,
+	env []corev1.EnvVar,
+	readOnly bool,
+) corev1.PodSpec {
+	return corev1.PodSpec{
+		RestartPolicy:                corev1.RestartPolicyNever,
+		AutomountServiceAccountToken: ptr.To(false),
+		SecurityContext: &corev1.PodSecurityContext{
+			RunAsUser:    ptr.To[int64](0),
+			RunAsNonRoot: ptr.To(false),
+		},
+		Containers: []corev1.Container{{
+			Name:            container,
+			Image:           params.OperatorImage,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/manager", command},
+			Env:             env,
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: naming.StorageVolume(), MountPath: storageMountPath, ReadOnly: readOnly},
+			},
+		}},
+		Volumes: []corev1.Volume{
+			generateStorageVolume(params.Registry),
+		},
+	}
+}
+
+// registryPodAffinity returns the affinity scheduling Pods onto the nodes of the Registry Pods.
+func registryPodAffinity(params manifests.Params) *corev1.Affinity {
+	return &corev1.Affinity{
+		PodAffinity: &corev1.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: manifestutils.SelectorLabels(params.Registry.ObjectMeta, ComponentRegistry),
+				},
+				TopologyKey: corev1.LabelHostname,
+			}},
+		},
+	}
+}
+
+// destinationEnv returns the environment variables locating the bucket of the given destination.
+func destinationEnv(destination registryv1alpha1.BackupDestination) []corev1.EnvVar {
+	env := []corev1.EnvVar{
+		secretEnv(backup.EnvBucket, &destination.BucketName),
+		secretEnv(backup.EnvRegion, &destination.Region),
+		{Name: backup.EnvPrefix, Value: destination.Prefix},
+	}
+	if ref := destination.AccessKey; ref != nil {
+		env = append(env, secretEnv(backup.EnvAccessKey, ref))
+	}
+	if ref := destination.SecretKey; ref != nil {
+		env = append(env, secretEnv(backup.EnvSecretKey, ref))
+	}
+	if ref := destination.EndpointURL; ref != nil {
+		env = append(env, secretEnv(backup.EnvEndpoint, ref))
+	}
+	return env
+}
+
+func secretEnv(name string, ref *registryv1alpha1.SecretKeySelector) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: ref.LocalObjectReference,
+				Key:                  ref.Key,
+			},
+		},
+	}
+}
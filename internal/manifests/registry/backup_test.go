@@ -0,0 +1,218 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/distribution/distribution/v3/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/backup"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBackupCronJob(t *testing.T) {
+	registry := backupRegistry()
+
+	t.Run("should not return CronJob when disabled", func(t *testing.T) {
+		params := manifests.Params{
+			Registry:      registryv1alpha1.Registry{ObjectMeta: registry.ObjectMeta},
+			OperatorImage: "operator:dev",
+		}
+
+		actual, err := BackupCronJob(t.Context(), params)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	t.Run("should fail without the operator image", func(t *testing.T) {
+		_, err := BackupCronJob(t.Context(), manifests.Params{Registry: registry})
+		assert.ErrorIs(t, err, errNoOperatorImage)
+	})
+
+	t.Run("should fail without a persistent storage", func(t *testing.T) {
+		registry := *registry.DeepCopy()
+		registry.Spec.Storage = registryv1alpha1.Storage{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+
+		_, err := BackupCronJob(t.Context(), manifests.Params{Registry: registry, OperatorImage: "operator:dev"})
+		assert.ErrorIs(t, err, errBackupStorage)
+	})
+
+	t.Run("should return CronJob", func(t *testing.T) {
+		params := manifests.Params{Registry: registry, OperatorImage: "operator:dev"}
+
+		actual, err := BackupCronJob(t.Context(), params)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, "my-instance-registry-backup", actual.Name)
+		assert.Equal(t, "0 3 * * *", actual.Spec.Schedule)
+		assert.Equal(t, "backup", actual.Spec.JobTemplate.Labels["app.kubernetes.io/component"])
+
+		pod := actual.Spec.JobTemplate.Spec.Template.Spec
+		require.Len(t, pod.Containers, 1)
+		assert.Equal(t, "operator:dev", pod.Containers[0].Image)
+		assert.Equal(t, []string{"/manager", backup.CommandBackup}, pod.Containers[0].Command)
+		assert.True(t, pod.Containers[0].VolumeMounts[0].ReadOnly)
+		assert.Equal(t, "my-claim", pod.Volumes[0].PersistentVolumeClaim.ClaimName)
+		assert.Equal(t, "my-instance-registry-backup", pod.Volumes[1].ConfigMap.Name)
+
+		env := map[string]corev1.EnvVar{}
+		for _, e := range pod.Containers[0].Env {
+			env[e.Name] = e
+		}
+		assert.Equal(t, "bucket", env[backup.EnvBucket].ValueFrom.SecretKeyRef.Key)
+		assert.Equal(t, "registries/my-instance", env[backup.EnvPrefix].Value)
+		assert.Equal(t, "5", env[backup.EnvRetention].Value)
+		assert.Equal(t, "/etc/registry-backup/readOnly", env[backup.EnvReadOnlyFile].Value)
+		assert.NotContains(t, env, backup.EnvEndpoint)
+
+		affinity := pod.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		require.Len(t, affinity, 1)
+		assert.Equal(t, "registry", affinity[0].LabelSelector.MatchLabels["app.kubernetes.io/component"])
+	})
+}
+
+func TestBackupState(t *testing.T) {
+	params := manifests.Params{Registry: backupRegistry(), ReadOnlyReady: true}
+
+	actual, err := BackupState(t.Context(), params)
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+	assert.Equal(t, map[string]string{backup.ReadOnlyKey: "true"}, actual.Data)
+
+	params.Registry.Spec.Backup = nil
+	actual, err = BackupState(t.Context(), params)
+	require.NoError(t, err)
+	assert.Nil(t, actual)
+}
+
+func TestRestoreJob(t *testing.T) {
+	registry := backupRegistry()
+	restore := &registryv1alpha1.RegistryRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-restore", Namespace: "my-namespace"},
+		Spec: registryv1alpha1.RegistryRestoreSpec{
+			RegistryRef: corev1.LocalObjectReference{Name: registry.Name},
+			BackupName:  "my-instance-registry-backup-29000000",
+		},
+	}
+	env := func(job []corev1.EnvVar) map[string]corev1.EnvVar {
+		env := map[string]corev1.EnvVar{}
+		for _, e := range job {
+			env[e.Name] = e
+		}
+		return env
+	}
+
+	t.Run("should read from the backup destination of the Registry", func(t *testing.T) {
+		actual, err := RestoreJob(manifests.Params{Registry: registry, OperatorImage: "operator:dev"}, restore)
+		require.NoError(t, err)
+		assert.Equal(t, "my-restore-restore", actual.Name)
+
+		container := actual.Spec.Template.Spec.Containers[0]
+		assert.Equal(t, []string{"/manager", backup.CommandRestore}, container.Command)
+		require.Len(t, container.VolumeMounts, 1, "the archive is not staged")
+		assert.False(t, container.VolumeMounts[0].ReadOnly)
+		assert.Len(t, actual.Spec.Template.Spec.Volumes, 1)
+		assert.Equal(t, "registries/my-instance", env(container.Env)[backup.EnvPrefix].Value)
+		assert.Equal(t, restore.Spec.BackupName, env(container.Env)[backup.EnvName].Value)
+	})
+
+	t.Run("should read from the source of the RegistryRestore", func(t *testing.T) {
+		restore := restore.DeepCopy()
+		restore.Spec.Source = registry.Spec.Backup.Destination.DeepCopy()
+		restore.Spec.Source.Prefix = "elsewhere"
+
+		actual, err := RestoreJob(manifests.Params{Registry: registry, OperatorImage: "operator:dev"}, restore)
+		require.NoError(t, err)
+		assert.Equal(t, "elsewhere", env(actual.Spec.Template.Spec.Containers[0].Env)[backup.EnvPrefix].Value)
+	})
+
+	t.Run("should fail without a source", func(t *testing.T) {
+		registry := *registry.DeepCopy()
+		registry.Spec.Backup = nil
+
+		_, err := RestoreJob(manifests.Params{Registry: registry, OperatorImage: "operator:dev"}, restore)
+		assert.Error(t, err)
+	})
+}
+
+func TestDeploymentBackup(t *testing.T) {
+	registry := backupRegistry()
+	registry.Spec.Replicas = 1
+
+	readOnly, err := Deployment(t.Context(), manifests.Params{Registry: registry, ReadOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, "true", readOnly.Spec.Template.Annotations[ReadOnlyAnnotation])
+	assert.Equal(t, int32(1), *readOnly.Spec.Replicas)
+
+	d, err := Deployment(t.Context(), manifests.Params{Registry: registry, Restoring: true})
+	require.NoError(t, err)
+	assert.Equal(t, "false", d.Spec.Template.Annotations[ReadOnlyAnnotation])
+	assert.Equal(t, int32(0), *d.Spec.Replicas)
+	assert.Empty(t, d.Spec.Template.Spec.Containers[0].Env)
+
+	// the configuration does not change with the mode, which the environment of the container sets
+	assert.Equal(t, DeploymentRevision(d).ConfigSecret, DeploymentRevision(readOnly).ConfigSecret)
+
+	secret, err := Secret(t.Context(), manifests.Params{Registry: registry, ReadOnly: true})
+	require.NoError(t, err)
+	for _, env := range readOnly.Spec.Template.Spec.Containers[0].Env {
+		t.Setenv(env.Name, env.Value)
+	}
+	cfg, err := configuration.Parse(strings.NewReader(secret.StringData[naming.DistributionConfig()]))
+	require.NoError(t, err)
+	assert.Equal(t, map[any]any{"enabled": true}, cfg.Storage["maintenance"]["readonly"])
+}
+
+func backupRegistry() registryv1alpha1.Registry {
+	selector := func(key string) *registryv1alpha1.SecretKeySelector {
+		return &registryv1alpha1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "backup"},
+			Key:                  key,
+		}
+	}
+	return registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "my-namespace",
+		},
+		Spec: registryv1alpha1.RegistrySpec{
+			Storage: registryv1alpha1.Storage{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "my-claim"},
+			},
+			Backup: &registryv1alpha1.Backup{
+				Schedule:  "0 3 * * *",
+				Retention: 5,
+				Destination: registryv1alpha1.BackupDestination{
+					S3StorageSource: registryv1alpha1.S3StorageSource{
+						BucketName: *selector("bucket"),
+						Region:     *selector("region"),
+						AccessKey:  selector("accessKey"),
+						SecretKey:  selector("secretKey"),
+					},
+					Prefix: "registries/my-instance",
+				},
+			},
+		},
+	}
+}
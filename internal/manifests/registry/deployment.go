@@ -19,11 +19,13 @@ package registry
 
 import (
 	"context"
+	"strconv"
 
 	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
 	"github.com/registry-operator/registry-operator/internal/manifests"
 	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
 	"github.com/registry-operator/registry-operator/internal/naming"
+	"github.com/registry-operator/registry-operator/internal/registryuser"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -31,10 +33,18 @@ import (
 	"k8s.io/utils/ptr"
 )
 
-func generateConfigVolume(registry, hash string) corev1.Volume {
-	config := corev1.KeyToPath{
-		Key:  naming.DistributionConfig(),
-		Path: naming.DistributionConfig(),
+func generateConfigVolume(registry, hash string, htpasswd bool) corev1.Volume {
+	items := []corev1.KeyToPath{
+		{
+			Key:  naming.DistributionConfig(),
+			Path: naming.DistributionConfig(),
+		},
+	}
+	if htpasswd {
+		items = append(items, corev1.KeyToPath{
+			Key:  naming.Htpasswd(),
+			Path: naming.Htpasswd(),
+		})
 	}
 
 	return corev1.Volume{
@@ -42,9 +52,7 @@ func generateConfigVolume(registry, hash string) corev1.Volume {
 		VolumeSource: corev1.VolumeSource{
 			Secret: &corev1.SecretVolumeSource{
 				SecretName: naming.Secret(registry, hash),
-				Items: []corev1.KeyToPath{
-					config,
-				},
+				Items:      items,
 			},
 		},
 	}
@@ -106,17 +114,30 @@ func Deployment(ctx context.Context, params manifests.Params) (*appsv1.Deploymen
 	if err != nil {
 		return nil, err
 	}
+	if params.Registry.Spec.Backup != nil || params.Migration != nil {
+		// rolls the Pods out when switching to read-only mode, and tells when they are all read-only
+		podAnnotations[ReadOnlyAnnotation] = strconv.FormatBool(params.ReadOnly)
+	}
+	container := Container(params.Registry)
+	if params.ReadOnly {
+		container.Env = append(container.Env, corev1.EnvVar{Name: envReadOnly, Value: "{enabled: true}"})
+	}
+
+	replicas := params.Registry.Spec.Replicas
+	if params.Restoring {
+		replicas = 0
+	}
 	cfg, err := generateConfig(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
-	hash, err := manifestutils.CalculateHash(cfg)
+	hash, err := configHash(cfg, params.Htpasswd)
 	if err != nil {
 		return nil, err
 	}
 
-	return &appsv1.Deployment{
+	dpl := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        name,
 			Namespace:   params.Registry.Namespace,
@@ -124,7 +145,11 @@ func Deployment(ctx context.Context, params manifests.Params) (*appsv1.Deploymen
 			Annotations: annotations,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: ptr.To(params.Registry.Spec.Replicas),
+			Replicas: ptr.To(replicas),
+			RevisionHistoryLimit: ptr.To(ptr.Deref(
+				params.Registry.Spec.RevisionHistoryLimit,
+				registryv1alpha1.DefaultRevisionHistoryLimit,
+			)),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: manifestutils.SelectorLabels(params.Registry.ObjectMeta, ComponentRegistry),
 			},
@@ -136,14 +161,25 @@ func Deployment(ctx context.Context, params manifests.Params) (*appsv1.Deploymen
 				Spec: corev1.PodSpec{
 					Affinity: manifestutils.Affinity(params.Registry),
 					Containers: []corev1.Container{
-						Container(params.Registry),
+						container,
 					},
 					Volumes: []corev1.Volume{
-						generateConfigVolume(params.Registry.Name, hash),
+						generateConfigVolume(
+							params.Registry.Name,
+							hash,
+							registryuser.AuthenticationEnabled(&params.Registry),
+						),
 						generateStorageVolume(params.Registry),
 					},
 				},
 			},
 		},
-	}, nil
+	}
+	if rp := params.Registry.Spec.RolloutPolicy; rp != nil {
+		dpl.Spec.ProgressDeadlineSeconds = rp.ProgressDeadlineSeconds
+	}
+	if params.Rollback != nil {
+		rollBack(dpl, params.Rollback)
+	}
+	return dpl, nil
 }
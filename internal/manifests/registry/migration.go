@@ -0,0 +1,202 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
+	"github.com/registry-operator/registry-operator/internal/migration"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// MigrationAnnotation is set on the migration Job to the hash of the migrated storages, telling whether
+// the Job migrates the current storages.
+const MigrationAnnotation = "registry-operator.dev/storage-migration"
+
+const migrationContainer = "migrate"
+
+var errMigrationStorage = fmt.Errorf("%w: the storage can only be migrated between a persistentVolumeClaim or "+
+	"persistentVolumeClaimTemplate storage and an s3 storage with an accessKey and a secretKey",
+	manifests.ErrInvalidConfig)
+
+// migrationSide names the environment variables locating one side of the migration, and the volume
+// mounting it when it is a filesystem storage.
+type migrationSide struct {
+	root, bucket, region, endpoint, accessKey, secretKey, prefix string
+
+	volume, mountPath string
+	readOnly          bool
+}
+
+var (
+	migrationSource = migrationSide{
+		root:      migration.EnvSourceRoot,
+		bucket:    migration.EnvSourceBucket,
+		region:    migration.EnvSourceRegion,
+		endpoint:  migration.EnvSourceEndpoint,
+		accessKey: migration.EnvSourceAccessKey,
+		secretKey: migration.EnvSourceSecretKey,
+		prefix:    migration.EnvSourcePrefix,
+		volume:    "source",
+		mountPath: "/mnt/source",
+		readOnly:  true,
+	}
+	migrationTarget = migrationSide{
+		root:      migration.EnvTargetRoot,
+		bucket:    migration.EnvTargetBucket,
+		region:    migration.EnvTargetRegion,
+		endpoint:  migration.EnvTargetEndpoint,
+		accessKey: migration.EnvTargetAccessKey,
+		secretKey: migration.EnvTargetSecretKey,
+		prefix:    migration.EnvTargetPrefix,
+		volume:    "target",
+		mountPath: "/mnt/target",
+	}
+)
+
+// MigrationHash returns the hash of the storages of the given migration, set in the MigrationAnnotation.
+func MigrationHash(m *manifests.StorageMigration) (string, error) {
+	data, err := json.Marshal([]registryv1alpha1.Storage{m.Source, m.Target})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:16], nil
+}
+
+// MigrationJob builds the Job copying the images of the Registry from the source to the target of its migration,
+// and verifying their digests. The Job fails without retrying when the verification fails.
+func MigrationJob(params manifests.Params) (*batchv1.Job, error) {
+	m := params.Migration
+	if m == nil {
+		return nil, errors.New("the storage of the Registry is not migrated")
+	}
+	if params.OperatorImage == "" {
+		return nil, errNoOperatorImage
+	}
+	if !IsStorageMigration(m.Source, m.Target) || !isMigratable(m.Source) || !isMigratable(m.Target) {
+		return nil, errMigrationStorage
+	}
+	hash, err := MigrationHash(m)
+	if err != nil {
+		return nil, err
+	}
+
+	name := naming.Migration(params.Registry.Name)
+	labels := manifestutils.Labels(params.Registry.ObjectMeta, name, params.OperatorImage, ComponentMigration, nil)
+
+	podSpec := jobPodSpec(params, migrationContainer, migration.CommandMigrate, nil, true)
+	// the storage volume of the Registry is replaced by the filesystem side of the migration
+	podSpec.Volumes = nil
+	podSpec.Containers[0].VolumeMounts = nil
+	podSpec.Containers[0].Env = append(
+		migrationLocationEnv(params.Registry, m.Source, migrationSource, &podSpec),
+		migrationLocationEnv(params.Registry, m.Target, migrationTarget, &podSpec)...,
+	)
+	if m.Source.S3 == nil {
+		// the Pods share the volume of the Registry, which may only be attached to its node
+		podSpec.Affinity = registryPodAffinity(params)
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   params.Registry.Namespace,
+			Labels:      labels,
+			Annotations: map[string]string{MigrationAnnotation: hash},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To[int32](backupBackoffLimit),
+			PodFailurePolicy: &batchv1.PodFailurePolicy{
+				Rules: []batchv1.PodFailurePolicyRule{{
+					Action: batchv1.PodFailurePolicyActionFailJob,
+					OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+						ContainerName: ptr.To(migrationContainer),
+						Operator:      batchv1.PodFailurePolicyOnExitCodesOpIn,
+						Values:        []int32{migration.ExitCodeVerificationFailed},
+					},
+				}},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       podSpec,
+			},
+		},
+	}, nil
+}
+
+// IsStorageMigration reports whether switching the storage of a Registry from source to target migrates its
+// images, that is switching between a PersistentVolumeClaim and S3.
+func IsStorageMigration(source, target registryv1alpha1.Storage) bool {
+	isVolume := func(storage registryv1alpha1.Storage) bool {
+		return storage.PersistentVolumeClaim != nil || storage.PersistentVolumeClaimTemplate != nil
+	}
+	return (isVolume(source) && target.S3 != nil) || (source.S3 != nil && isVolume(target))
+}
+
+// isMigratable reports whether the images of the given storage can be migrated.
+func isMigratable(storage registryv1alpha1.Storage) bool {
+	switch {
+	case storage.S3 != nil:
+		return storage.S3.AccessKey != nil && storage.S3.SecretKey != nil
+	default:
+		return storage.PersistentVolumeClaim != nil || storage.PersistentVolumeClaimTemplate != nil
+	}
+}
+
+// migrationLocationEnv returns the environment variables locating the given side of the migration, mounting it
+// into the given Pod when it is a filesystem storage.
+func migrationLocationEnv(
+	registry registryv1alpha1.Registry,
+	storage registryv1alpha1.Storage,
+	side migrationSide,
+	podSpec *corev1.PodSpec,
+) []corev1.EnvVar {
+	if s3 := storage.S3; s3 != nil {
+		env := []corev1.EnvVar{
+			secretEnv(side.bucket, &s3.BucketName),
+			secretEnv(side.region, &s3.Region),
+			secretEnv(side.accessKey, s3.AccessKey),
+			secretEnv(side.secretKey, s3.SecretKey),
+			{Name: side.prefix, Value: strings.TrimPrefix(S3RootDirectory(s3), "/")},
+		}
+		if ref := s3.EndpointURL; ref != nil {
+			env = append(env, secretEnv(side.endpoint, ref))
+		}
+		return env
+	}
+
+	registry.Spec.Storage = storage
+	volume := generateStorageVolume(registry)
+	volume.Name = side.volume
+	podSpec.Volumes = append(podSpec.Volumes, volume)
+	podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      side.volume,
+		MountPath: side.mountPath,
+		ReadOnly:  side.readOnly,
+	})
+	return []corev1.EnvVar{{Name: side.root, Value: side.mountPath}}
+}
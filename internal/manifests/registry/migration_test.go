@@ -0,0 +1,141 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/migration"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func s3Storage() registryv1alpha1.Storage {
+	selector := func(key string) *registryv1alpha1.SecretKeySelector {
+		return &registryv1alpha1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "s3"},
+			Key:                  key,
+		}
+	}
+	return registryv1alpha1.Storage{S3: &registryv1alpha1.S3StorageSource{
+		BucketName: *selector("bucket"),
+		Region:     *selector("region"),
+		AccessKey:  selector("accessKey"),
+		SecretKey:  selector("secretKey"),
+	}}
+}
+
+func TestMigrationJob(t *testing.T) {
+	registry := backupRegistry()
+	registry.Spec.Backup = nil
+	claim := registry.Spec.Storage
+	env := func(job *batchv1.Job) map[string]corev1.EnvVar {
+		env := map[string]corev1.EnvVar{}
+		for _, e := range job.Spec.Template.Spec.Containers[0].Env {
+			env[e.Name] = e
+		}
+		return env
+	}
+
+	t.Run("should fail without a migration", func(t *testing.T) {
+		_, err := MigrationJob(manifests.Params{Registry: registry, OperatorImage: "operator:dev"})
+		assert.Error(t, err)
+	})
+
+	t.Run("should fail without static credentials", func(t *testing.T) {
+		target := s3Storage()
+		target.S3.AccessKey = nil
+		params := manifests.Params{
+			Registry:      registry,
+			OperatorImage: "operator:dev",
+			Migration:     &manifests.StorageMigration{Source: claim, Target: target},
+		}
+
+		_, err := MigrationJob(params)
+		assert.ErrorIs(t, err, errMigrationStorage)
+	})
+
+	t.Run("should return Job migrating a claim to S3", func(t *testing.T) {
+		params := manifests.Params{
+			Registry:      registry,
+			OperatorImage: "operator:dev",
+			Migration:     &manifests.StorageMigration{Source: claim, Target: s3Storage()},
+		}
+
+		actual, err := MigrationJob(params)
+		require.NoError(t, err)
+		assert.Equal(t, "my-instance-registry-migration", actual.Name)
+		assert.Equal(t, "migration", actual.Labels["app.kubernetes.io/component"])
+		hash, err := MigrationHash(params.Migration)
+		require.NoError(t, err)
+		assert.Equal(t, hash, actual.Annotations[MigrationAnnotation])
+		assert.Equal(t, []int32{migration.ExitCodeVerificationFailed},
+			actual.Spec.PodFailurePolicy.Rules[0].OnExitCodes.Values)
+
+		pod := actual.Spec.Template.Spec
+		assert.Equal(t, []string{"/manager", migration.CommandMigrate}, pod.Containers[0].Command)
+		require.Len(t, pod.Volumes, 1)
+		assert.Equal(t, "my-claim", pod.Volumes[0].PersistentVolumeClaim.ClaimName)
+		assert.True(t, pod.Containers[0].VolumeMounts[0].ReadOnly)
+		assert.NotNil(t, pod.Affinity.PodAffinity)
+
+		env := env(actual)
+		assert.Equal(t, "/mnt/source", env[migration.EnvSourceRoot].Value)
+		assert.Equal(t, "bucket", env[migration.EnvTargetBucket].ValueFrom.SecretKeyRef.Key)
+		assert.Equal(t, "registry", env[migration.EnvTargetPrefix].Value)
+		assert.NotContains(t, env, migration.EnvTargetEndpoint)
+	})
+
+	t.Run("should return Job migrating S3 to a claim", func(t *testing.T) {
+		params := manifests.Params{
+			Registry:      registry,
+			OperatorImage: "operator:dev",
+			Migration:     &manifests.StorageMigration{Source: s3Storage(), Target: claim},
+		}
+
+		actual, err := MigrationJob(params)
+		require.NoError(t, err)
+
+		pod := actual.Spec.Template.Spec
+		require.Len(t, pod.Volumes, 1)
+		assert.Equal(t, "target", pod.Volumes[0].Name)
+		assert.False(t, pod.Containers[0].VolumeMounts[0].ReadOnly)
+		assert.Nil(t, pod.Affinity)
+
+		env := env(actual)
+		assert.Equal(t, "accessKey", env[migration.EnvSourceAccessKey].ValueFrom.SecretKeyRef.Key)
+		assert.Equal(t, "/mnt/target", env[migration.EnvTargetRoot].Value)
+	})
+}
+
+func TestIsStorageMigration(t *testing.T) {
+	claim := backupRegistry().Spec.Storage
+	template := registryv1alpha1.Storage{
+		PersistentVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{},
+	}
+	emptyDir := registryv1alpha1.Storage{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+
+	assert.True(t, IsStorageMigration(claim, s3Storage()))
+	assert.True(t, IsStorageMigration(s3Storage(), template))
+	assert.False(t, IsStorageMigration(claim, template))
+	assert.False(t, IsStorageMigration(s3Storage(), s3Storage()))
+	assert.False(t, IsStorageMigration(emptyDir, s3Storage()))
+}
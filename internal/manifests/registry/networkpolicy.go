@@ -0,0 +1,199 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	dnsPort   = 53
+	httpPort  = 80
+	httpsPort = 443
+)
+
+// operatorPodLabels select the Pods of the operator, calling the Registries to manage their repositories,
+// synchronize images and resolve update channels.
+var operatorPodLabels = map[string]string{"control-plane": "controller-manager"}
+
+// NetworkPolicy builds the NetworkPolicy restricting the traffic of the given instance.
+func NetworkPolicy(ctx context.Context, params manifests.Params) (*networkingv1.NetworkPolicy, error) {
+	policy := params.Registry.Spec.NetworkPolicy
+	if policy == nil || !policy.Enabled {
+		return nil, nil
+	}
+
+	name := naming.NetworkPolicy(params.Registry.Name)
+	labels := manifestutils.Labels(
+		params.Registry.ObjectMeta,
+		name,
+		params.Registry.Spec.Image,
+		ComponentRegistry,
+		nil,
+	)
+	annotations, err := manifestutils.Annotations(params.Registry, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ingress := []networkingv1.NetworkPolicyIngressRule{
+		{
+			From:  policy.From,
+			Ports: []networkingv1.NetworkPolicyPort{tcpPort(intstr.FromString(naming.RegistryDistributionPort()))},
+		},
+	}
+	if params.OperatorNamespace != "" {
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			From: []networkingv1.NetworkPolicyPeer{
+				{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{corev1.LabelMetadataName: params.OperatorNamespace},
+					},
+					PodSelector: &metav1.LabelSelector{MatchLabels: operatorPodLabels},
+				},
+			},
+			Ports: []networkingv1.NetworkPolicyPort{tcpPort(intstr.FromString(naming.RegistryDistributionPort()))},
+		})
+	}
+	if policy.MonitoringNamespaceSelector != nil {
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			From: []networkingv1.NetworkPolicyPeer{
+				{NamespaceSelector: policy.MonitoringNamespaceSelector},
+			},
+			Ports: []networkingv1.NetworkPolicyPort{tcpPort(intstr.FromString(naming.RegistryMetricsPort()))},
+		})
+	}
+
+	egress := []networkingv1.NetworkPolicyEgressRule{
+		{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: ptr.To(corev1.ProtocolUDP), Port: ptr.To(intstr.FromInt32(dnsPort))},
+				tcpPort(intstr.FromInt32(dnsPort)),
+			},
+		},
+	}
+	storageEgress, err := generateStorageEgressRule(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if storageEgress != nil {
+		egress = append(egress, *storageEgress)
+	}
+	if proxy := params.Registry.Spec.Proxy; proxy != nil {
+		proxyEgress, err := urlEgressRule(proxy.RemoteURL, policy.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		egress = append(egress, *proxyEgress)
+	}
+	egress = append(egress, policy.Notifications...)
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   params.Registry.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: manifestutils.SelectorLabels(params.Registry.ObjectMeta, ComponentRegistry),
+			},
+			PolicyTypes: []networkingv1.PolicyType{
+				networkingv1.PolicyTypeIngress,
+				networkingv1.PolicyTypeEgress,
+			},
+			Ingress: ingress,
+			Egress:  egress,
+		},
+	}, nil
+}
+
+// generateStorageEgressRule returns the rule allowing traffic to the S3 endpoint, or nil for filesystem storage.
+func generateStorageEgressRule(
+	ctx context.Context,
+	params manifests.Params,
+) (*networkingv1.NetworkPolicyEgressRule, error) {
+	s3, err := S3Config(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(s3) == 0 {
+		return nil, nil
+	}
+
+	endpoint, _ := s3["regionendpoint"].(string)
+	return urlEgressRule(endpoint, params.Registry.Spec.NetworkPolicy.Storage)
+}
+
+// urlEgressRule returns the rule allowing traffic to the port of the given URL, HTTPS when it is empty. When no
+// peer is given, the peer is derived from the URL when its host is an IP address.
+func urlEgressRule(
+	endpoint string,
+	peers []networkingv1.NetworkPolicyPeer,
+) (*networkingv1.NetworkPolicyEgressRule, error) {
+	port := int32(httpsPort)
+	if endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid endpoint URL: %w", manifests.ErrInvalidConfig, err)
+		}
+		if u.Scheme == "http" {
+			port = httpPort
+		}
+		if p := u.Port(); p != "" {
+			parsed, err := strconv.ParseInt(p, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid endpoint URL port: %w", manifests.ErrInvalidConfig, err)
+			}
+			port = int32(parsed)
+		}
+		if ip := net.ParseIP(u.Hostname()); ip != nil && len(peers) == 0 {
+			cidr := ip.String() + "/32"
+			if ip.To4() == nil {
+				cidr = ip.String() + "/128"
+			}
+			peers = []networkingv1.NetworkPolicyPeer{
+				{IPBlock: &networkingv1.IPBlock{CIDR: cidr}},
+			}
+		}
+	}
+
+	return &networkingv1.NetworkPolicyEgressRule{
+		To:    peers,
+		Ports: []networkingv1.NetworkPolicyPort{tcpPort(intstr.FromInt32(port))},
+	}, nil
+}
+
+func tcpPort(port intstr.IntOrString) networkingv1.NetworkPolicyPort {
+	return networkingv1.NetworkPolicyPort{
+		Protocol: ptr.To(corev1.ProtocolTCP),
+		Port:     &port,
+	}
+}
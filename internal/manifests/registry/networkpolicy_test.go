@@ -0,0 +1,167 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDesiredNetworkPolicy(t *testing.T) {
+	meta := metav1.ObjectMeta{
+		Name:      "my-instance",
+		Namespace: "my-namespace",
+	}
+
+	t.Run("should not return policy when disabled", func(t *testing.T) {
+		params := manifests.Params{
+			Registry: registryv1alpha1.Registry{ObjectMeta: meta},
+		}
+
+		actual, err := NetworkPolicy(t.Context(), params)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	t.Run("should return policy for filesystem storage", func(t *testing.T) {
+		from := []networkingv1.NetworkPolicyPeer{
+			{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8"}},
+		}
+		monitoring := &metav1.LabelSelector{
+			MatchLabels: map[string]string{"kubernetes.io/metadata.name": "monitoring"},
+		}
+		params := manifests.Params{
+			Registry: registryv1alpha1.Registry{
+				ObjectMeta: meta,
+				Spec: registryv1alpha1.RegistrySpec{
+					NetworkPolicy: &registryv1alpha1.NetworkPolicy{
+						Enabled:                     true,
+						From:                        from,
+						MonitoringNamespaceSelector: monitoring,
+					},
+				},
+			},
+		}
+
+		actual, err := NetworkPolicy(t.Context(), params)
+		require.NoError(t, err)
+		assert.Equal(t, "my-instance-registry", actual.Name)
+		assert.Equal(t, "my-namespace.my-instance", actual.Spec.PodSelector.MatchLabels["app.kubernetes.io/instance"])
+
+		require.Len(t, actual.Spec.Ingress, 2)
+		assert.Equal(t, from, actual.Spec.Ingress[0].From)
+		assert.Equal(t, "distribution", actual.Spec.Ingress[0].Ports[0].Port.String())
+		assert.Equal(t, monitoring, actual.Spec.Ingress[1].From[0].NamespaceSelector)
+		assert.Equal(t, "metrics", actual.Spec.Ingress[1].Ports[0].Port.String())
+
+		// only DNS is allowed
+		require.Len(t, actual.Spec.Egress, 1)
+		assert.Len(t, actual.Spec.Egress[0].Ports, 2)
+	})
+
+	t.Run("should allow egress to S3 endpoint", func(t *testing.T) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "s3", Namespace: meta.Namespace},
+			Data: map[string][]byte{
+				"bucket":   []byte("registry"),
+				"region":   []byte("us-east-1"),
+				"endpoint": []byte("http://192.168.1.10:9000"),
+			},
+		}
+		ref := func(key string) registryv1alpha1.SecretKeySelector {
+			return registryv1alpha1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+				Key:                  key,
+			}
+		}
+		endpoint := ref("endpoint")
+		notification := networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{
+				{IPBlock: &networkingv1.IPBlock{CIDR: "10.1.0.0/16"}},
+			},
+		}
+		params := manifests.Params{
+			Client: fake.NewClientBuilder().WithObjects(secret).Build(),
+			Registry: registryv1alpha1.Registry{
+				ObjectMeta: meta,
+				Spec: registryv1alpha1.RegistrySpec{
+					Storage: registryv1alpha1.Storage{
+						S3: &registryv1alpha1.S3StorageSource{
+							BucketName:  ref("bucket"),
+							Region:      ref("region"),
+							EndpointURL: &endpoint,
+						},
+					},
+					NetworkPolicy: &registryv1alpha1.NetworkPolicy{
+						Enabled:       true,
+						Notifications: []networkingv1.NetworkPolicyEgressRule{notification},
+					},
+				},
+			},
+		}
+
+		actual, err := NetworkPolicy(t.Context(), params)
+		require.NoError(t, err)
+
+		require.Len(t, actual.Spec.Egress, 3)
+		storage := actual.Spec.Egress[1]
+		assert.Equal(t, "192.168.1.10/32", storage.To[0].IPBlock.CIDR)
+		assert.Equal(t, int32(9000), storage.Ports[0].Port.IntVal)
+		assert.Equal(t, notification, actual.Spec.Egress[2])
+	})
+
+	t.Run("should allow ingress from the operator and egress to the proxy remote", func(t *testing.T) {
+		params := manifests.Params{
+			OperatorNamespace: "registry-operator-system",
+			Registry: registryv1alpha1.Registry{
+				ObjectMeta: meta,
+				Spec: registryv1alpha1.RegistrySpec{
+					Proxy: &registryv1alpha1.Proxy{RemoteURL: "http://10.2.0.5:5000"},
+					NetworkPolicy: &registryv1alpha1.NetworkPolicy{
+						Enabled: true,
+						From: []networkingv1.NetworkPolicyPeer{
+							{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8"}},
+						},
+					},
+				},
+			},
+		}
+
+		actual, err := NetworkPolicy(t.Context(), params)
+		require.NoError(t, err)
+
+		require.Len(t, actual.Spec.Ingress, 2)
+		operator := actual.Spec.Ingress[1]
+		assert.Equal(t, "registry-operator-system",
+			operator.From[0].NamespaceSelector.MatchLabels["kubernetes.io/metadata.name"])
+		assert.Equal(t, "controller-manager", operator.From[0].PodSelector.MatchLabels["control-plane"])
+		assert.Equal(t, "distribution", operator.Ports[0].Port.String())
+
+		require.Len(t, actual.Spec.Egress, 2)
+		proxy := actual.Spec.Egress[1]
+		assert.Equal(t, "10.2.0.5/32", proxy.To[0].IPBlock.CIDR)
+		assert.Equal(t, int32(5000), proxy.Ports[0].Port.IntVal)
+	})
+}
@@ -0,0 +1,192 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
+	"github.com/registry-operator/registry-operator/internal/naming"
+	"github.com/registry-operator/registry-operator/internal/version"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	nodeMirrorVolume    = "certs"
+	nodeMirrorMountPath = "/host/certs.d"
+	dockerHubHost       = "docker.io"
+	dockerHubServer     = "https://registry-1.docker.io"
+)
+
+// NodeMirror builds the DaemonSet writing the containerd host configurations of the mirrored registries
+// onto the nodes. It is nil until the Registry Service has been assigned a cluster IP.
+func NodeMirror(ctx context.Context, params manifests.Params) (*appsv1.DaemonSet, error) {
+	mirror := params.Registry.Spec.NodeMirror
+	if mirror == nil {
+		return nil, nil
+	}
+
+	svc := &corev1.Service{}
+	key := client.ObjectKey{Namespace: params.Registry.Namespace, Name: naming.Service(params.Registry.Name)}
+	if err := params.Client.Get(ctx, key, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	if svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == corev1.ClusterIPNone {
+		return nil, nil
+	}
+	address := net.JoinHostPort(svc.Spec.ClusterIP, strconv.Itoa(distributionPortDefault))
+
+	name := naming.NodeMirror(params.Registry.Name)
+	image := params.Registry.Spec.Image
+	if len(image) == 0 {
+		image = version.GetRegistryImage()
+	}
+	labels := manifestutils.Labels(
+		params.Registry.ObjectMeta,
+		name,
+		image,
+		ComponentNodeMirror,
+		nil,
+	)
+	annotations, err := manifestutils.Annotations(params.Registry, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	configPath := mirror.ConfigPath
+	if configPath == "" {
+		configPath = "/etc/containerd/certs.d"
+	}
+	hosts := slices.Sorted(slices.Values(mirror.Hosts))
+	marker := fmt.Sprintf("# managed by registry-operator for %s/%s", params.Registry.Namespace, params.Registry.Name)
+
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   params.Registry.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: manifestutils.SelectorLabels(params.Registry.ObjectMeta, ComponentNodeMirror),
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					NodeSelector: mirror.NodeSelector,
+					Tolerations:  mirror.Tolerations,
+					Containers: []corev1.Container{
+						{
+							Name:            "node-mirror",
+							Image:           image,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"/bin/sh", "-c", nodeMirrorScript(hosts, address, marker)},
+							Lifecycle: &corev1.Lifecycle{
+								PreStop: &corev1.LifecycleHandler{
+									Exec: &corev1.ExecAction{
+										Command: []string{"/bin/sh", "-c", nodeMirrorCleanupScript(hosts, marker)},
+									},
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      nodeMirrorVolume,
+									MountPath: nodeMirrorMountPath,
+								},
+							},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("1m"),
+									corev1.ResourceMemory: resource.MustParse("8Mi"),
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: nodeMirrorVolume,
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: configPath,
+									Type: ptr.To(corev1.HostPathDirectoryOrCreate),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// hostsTOML returns the containerd host configuration of the given upstream host, pulling through the mirror
+// at the given address first.
+func hostsTOML(host, address, marker string) string {
+	server := "https://" + host
+	if host == dockerHubHost {
+		server = dockerHubServer
+	}
+	return fmt.Sprintf("%s\nserver = %q\n\n[host.%q]\n  capabilities = [\"pull\", \"resolve\"]\n",
+		marker, server, "http://"+address)
+}
+
+// nodeMirrorScript returns the script writing the host configurations onto the node, then waiting to be stopped.
+func nodeMirrorScript(hosts []string, address, marker string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "set -eu\n")
+	for _, host := range hosts {
+		dir := path.Join(nodeMirrorMountPath, host)
+		fmt.Fprintf(&b, "mkdir -p '%s'\n", dir)
+		fmt.Fprintf(&b, "printf '%%s' '%s' > '%s/hosts.toml.tmp'\n", hostsTOML(host, address, marker), dir)
+		fmt.Fprintf(&b, "mv '%s/hosts.toml.tmp' '%s/hosts.toml'\n", dir, dir)
+	}
+	fmt.Fprintf(&b, "trap 'exit 0' TERM\n")
+	fmt.Fprintf(&b, "while true; do sleep 3600 & wait $!; done\n")
+	return b.String()
+}
+
+// nodeMirrorCleanupScript returns the script removing the host configurations written for the Registry.
+// The files written by another Registry or by the administrators of the nodes are left in place.
+func nodeMirrorCleanupScript(hosts []string, marker string) string {
+	var b strings.Builder
+	for _, host := range hosts {
+		dir := path.Join(nodeMirrorMountPath, host)
+		fmt.Fprintf(&b, "if grep -qxF '%s' '%s/hosts.toml' 2>/dev/null; then\n", marker, dir)
+		fmt.Fprintf(&b, "  rm -f '%s/hosts.toml'\n", dir)
+		fmt.Fprintf(&b, "  rmdir '%s' 2>/dev/null || true\n", dir)
+		fmt.Fprintf(&b, "fi\n")
+	}
+	return b.String()
+}
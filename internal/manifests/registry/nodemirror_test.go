@@ -0,0 +1,120 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDesiredNodeMirror(t *testing.T) {
+	registry := registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "my-namespace",
+		},
+		Spec: registryv1alpha1.RegistrySpec{
+			NodeMirror: &registryv1alpha1.NodeMirror{
+				Hosts:        []string{"ghcr.io", "docker.io"},
+				NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+			},
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-instance-registry", Namespace: "my-namespace"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.10"},
+	}
+
+	t.Run("should not return DaemonSet when disabled", func(t *testing.T) {
+		params := manifests.Params{
+			Registry: registryv1alpha1.Registry{ObjectMeta: registry.ObjectMeta},
+		}
+
+		actual, err := NodeMirror(t.Context(), params)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	t.Run("should not return DaemonSet before the Service", func(t *testing.T) {
+		params := manifests.Params{Client: fake.NewClientBuilder().Build(), Registry: registry}
+
+		actual, err := NodeMirror(t.Context(), params)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	t.Run("should return DaemonSet", func(t *testing.T) {
+		params := manifests.Params{Client: fake.NewClientBuilder().WithObjects(svc).Build(), Registry: registry}
+
+		actual, err := NodeMirror(t.Context(), params)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, "my-instance-registry-node-mirror", actual.Name)
+		assert.Equal(t, "node-mirror", actual.Spec.Selector.MatchLabels["app.kubernetes.io/component"])
+		assert.Equal(t, registry.Spec.NodeMirror.NodeSelector, actual.Spec.Template.Spec.NodeSelector)
+		assert.Equal(t, "/etc/containerd/certs.d", actual.Spec.Template.Spec.Volumes[0].HostPath.Path)
+	})
+
+	t.Run("scripts should write and remove the host configurations", func(t *testing.T) {
+		dir := t.TempDir()
+		hosts := []string{"docker.io", "registry.example.com:5000"}
+		marker := "# managed by registry-operator for my-namespace/my-instance"
+		run := func(t *testing.T, script string) {
+			t.Helper()
+			script = strings.ReplaceAll(script, nodeMirrorMountPath, dir)
+			out, err := exec.CommandContext(t.Context(), "/bin/sh", "-c", script).CombinedOutput()
+			require.NoError(t, err, string(out))
+		}
+
+		// the written configurations
+		write, _, _ := strings.Cut(nodeMirrorScript(hosts, "10.96.0.10:5000", marker), "trap")
+		run(t, write)
+
+		content, err := os.ReadFile(filepath.Join(dir, "docker.io", "hosts.toml"))
+		require.NoError(t, err)
+		assert.Equal(t, marker+`
+server = "https://registry-1.docker.io"
+
+[host."http://10.96.0.10:5000"]
+  capabilities = ["pull", "resolve"]
+`, string(content))
+		_, err = os.Stat(filepath.Join(dir, "registry.example.com:5000", "hosts.toml"))
+		require.NoError(t, err)
+
+		// a configuration written by the administrators
+		foreign := filepath.Join(dir, "registry.example.com:5000", "hosts.toml")
+		require.NoError(t, os.WriteFile(foreign, []byte("server = \"https://example.com\"\n"), 0o600))
+
+		run(t, nodeMirrorCleanupScript(hosts, marker))
+
+		_, err = os.Stat(filepath.Join(dir, "docker.io"))
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(foreign)
+		assert.NoError(t, err)
+	})
+}
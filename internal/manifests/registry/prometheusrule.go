@@ -0,0 +1,241 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	severityWarning  = "warning"
+	severityCritical = "critical"
+)
+
+// alertDefaults holds the values used for an alert when they are not overridden in the Registry.
+type alertDefaults struct {
+	name      string
+	threshold int32
+	duration  time.Duration
+	severity  string
+	summary   string
+	// expr renders the alert expression for the given threshold.
+	expr func(threshold int32) string
+}
+
+// PrometheusRule builds the PrometheusRule with the alerts for the given instance.
+func PrometheusRule(_ context.Context, params manifests.Params) (*monitoringv1.PrometheusRule, error) {
+	if params.Registry.Spec.Monitoring == nil {
+		return nil, nil
+	}
+	alerts := params.Registry.Spec.Monitoring.Alerts
+	if alerts == nil || !alerts.Enabled {
+		return nil, nil
+	}
+
+	name := naming.PrometheusRule(params.Registry.Name)
+	labels := manifestutils.Labels(
+		params.Registry.ObjectMeta,
+		name,
+		params.Registry.Spec.Image,
+		ComponentRegistry,
+		nil,
+	)
+	annotations, err := manifestutils.Annotations(params.Registry, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []monitoringv1.Rule
+	for _, a := range alertRules(params.Registry) {
+		if override := a.override; override != nil && override.Enabled != nil && !*override.Enabled {
+			continue
+		}
+		rules = append(rules, generateAlertRule(params.Registry, alerts.Labels, a.alertDefaults, a.override))
+	}
+
+	return &monitoringv1.PrometheusRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   params.Registry.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: monitoringv1.PrometheusRuleSpec{
+			Groups: []monitoringv1.RuleGroup{
+				{
+					Name:  fmt.Sprintf("registry.%s.%s", params.Registry.Namespace, params.Registry.Name),
+					Rules: rules,
+				},
+			},
+		},
+	}, nil
+}
+
+type alertRule struct {
+	alertDefaults
+	override *registryv1alpha1.AlertRule
+}
+
+func alertRules(registry registryv1alpha1.Registry) []alertRule {
+	alerts := registry.Spec.Monitoring.Alerts
+	ns := registry.Namespace
+	deployment := naming.Registry(registry.Name)
+	// the metrics of the Registry are selected by the service label of the targets of the ServiceMonitors
+	// scraping its Service, which only selects its own Pods
+	targets := fmt.Sprintf(`namespace=%q,service=%q`, ns, naming.Service(registry.Name))
+	instance := registry.Namespace + "/" + registry.Name
+
+	rules := []alertRule{
+		{
+			override: alerts.PodsNotReady,
+			alertDefaults: alertDefaults{
+				name:      "RegistryPodsNotReady",
+				threshold: 0,
+				duration:  15 * time.Minute,
+				severity:  severityWarning,
+				summary:   "Registry " + instance + " has unavailable replicas.",
+				expr: func(threshold int32) string {
+					return fmt.Sprintf(
+						`kube_deployment_spec_replicas{namespace=%q,deployment=%q}`+
+							` - kube_deployment_status_replicas_available{namespace=%q,deployment=%q} > %d`,
+						ns, deployment, ns, deployment, threshold,
+					)
+				},
+			},
+		},
+		{
+			override: alerts.StorageUnhealthy,
+			alertDefaults: alertDefaults{
+				name:      "RegistryStorageUnhealthy",
+				threshold: 0,
+				duration:  5 * time.Minute,
+				severity:  severityCritical,
+				summary:   "Registry " + instance + " storage driver is unhealthy.",
+				expr: func(threshold int32) string {
+					return fmt.Sprintf(
+						`sum(rate(registry_http_requests_total{%s,code="503"}[5m])) > %d`,
+						targets, threshold,
+					)
+				},
+			},
+		},
+		{
+			override: alerts.HighErrorRate,
+			alertDefaults: alertDefaults{
+				name:      "RegistryHighErrorRate",
+				threshold: 5,
+				duration:  10 * time.Minute,
+				severity:  severityWarning,
+				summary:   "Registry " + instance + " answers with many 5xx errors.",
+				expr: func(threshold int32) string {
+					return fmt.Sprintf(
+						`sum(rate(registry_http_requests_total{%s,code=~"5.."}[5m]))`+
+							` / sum(rate(registry_http_requests_total{%s}[5m])) * 100 > %d`,
+						targets, targets, threshold,
+					)
+				},
+			},
+		},
+	}
+
+	if claim := storageClaimName(registry); claim != "" {
+		rules = append(rules, alertRule{
+			override: alerts.PersistentVolumeFillingUp,
+			alertDefaults: alertDefaults{
+				name:      "RegistryPersistentVolumeFillingUp",
+				threshold: 85,
+				duration:  5 * time.Minute,
+				severity:  severityWarning,
+				summary:   "Storage of Registry " + instance + " is almost full.",
+				expr: func(threshold int32) string {
+					return fmt.Sprintf(
+						`(1 - kubelet_volume_stats_available_bytes{namespace=%q,persistentvolumeclaim=%q}`+
+							` / kubelet_volume_stats_capacity_bytes{namespace=%q,persistentvolumeclaim=%q}) * 100 > %d`,
+						ns, claim, ns, claim, threshold,
+					)
+				},
+			},
+		})
+	}
+
+	return rules
+}
+
+func generateAlertRule(
+	registry registryv1alpha1.Registry,
+	extraLabels map[string]string,
+	defaults alertDefaults,
+	override *registryv1alpha1.AlertRule,
+) monitoringv1.Rule {
+	threshold := defaults.threshold
+	duration := defaults.duration
+	severity := defaults.severity
+	if override != nil {
+		if override.Threshold != nil {
+			threshold = *override.Threshold
+		}
+		if override.For != nil {
+			duration = override.For.Duration
+		}
+		if override.Severity != "" {
+			severity = override.Severity
+		}
+	}
+
+	labels := map[string]string{}
+	maps.Copy(labels, extraLabels)
+	labels["namespace"] = registry.Namespace
+	labels["registry"] = registry.Name
+	labels["severity"] = severity
+
+	rule := monitoringv1.Rule{
+		Alert:  defaults.name,
+		Expr:   intstr.FromString(defaults.expr(threshold)),
+		Labels: labels,
+		Annotations: map[string]string{
+			"summary": defaults.summary,
+		},
+	}
+	if duration > 0 {
+		d := monitoringv1.Duration(duration.String())
+		rule.For = &d
+	}
+	return rule
+}
+
+// storageClaimName returns the name of the PersistentVolumeClaim used as the Registry storage, if any.
+func storageClaimName(registry registryv1alpha1.Registry) string {
+	switch storage := registry.Spec.Storage; {
+	case storage.PersistentVolumeClaim != nil:
+		return storage.PersistentVolumeClaim.ClaimName
+	case storage.PersistentVolumeClaimTemplate != nil:
+		return naming.PersistentVolumeClaim(registry.Name)
+	default:
+		return ""
+	}
+}
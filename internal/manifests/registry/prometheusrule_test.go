@@ -0,0 +1,129 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestDesiredPrometheusRule(t *testing.T) {
+	meta := metav1.ObjectMeta{
+		Name:      "my-instance",
+		Namespace: "my-namespace",
+	}
+
+	t.Run("should not return rule when alerts are disabled", func(t *testing.T) {
+		params := manifests.Params{
+			Registry: registryv1alpha1.Registry{
+				ObjectMeta: meta,
+				Spec: registryv1alpha1.RegistrySpec{
+					Monitoring: &registryv1alpha1.Monitoring{
+						Alerts: &registryv1alpha1.Alerts{Enabled: false},
+					},
+				},
+			},
+		}
+
+		actual, err := PrometheusRule(t.Context(), params)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	t.Run("should return default alerts", func(t *testing.T) {
+		params := manifests.Params{
+			Registry: registryv1alpha1.Registry{
+				ObjectMeta: meta,
+				Spec: registryv1alpha1.RegistrySpec{
+					Monitoring: &registryv1alpha1.Monitoring{
+						Alerts: &registryv1alpha1.Alerts{Enabled: true},
+					},
+				},
+			},
+		}
+
+		actual, err := PrometheusRule(t.Context(), params)
+		require.NoError(t, err)
+		assert.Equal(t, "my-instance-registry", actual.Name)
+		assert.Equal(t, "my-namespace.my-instance", actual.Labels["app.kubernetes.io/instance"])
+		require.Len(t, actual.Spec.Groups, 1)
+
+		rules := actual.Spec.Groups[0].Rules
+		assert.Len(t, rules, 3, "no volume alert without PersistentVolumeClaim storage")
+		for _, rule := range rules {
+			assert.Equal(t, "my-namespace", rule.Labels["namespace"])
+			assert.Equal(t, "my-instance", rule.Labels["registry"])
+			assert.Contains(t, rule.Expr.String(), `namespace="my-namespace"`)
+			assert.Contains(t, rule.Annotations["summary"], "my-namespace/my-instance")
+		}
+		// the Registry metrics are selected by the Service scraped, not to match the Pods of another Registry
+		assert.Contains(t, rules[1].Expr.String(), `namespace="my-namespace",service="my-instance-registry"`)
+		assert.NotContains(t, rules[1].Expr.String(), "pod=~")
+	})
+
+	t.Run("should apply overrides", func(t *testing.T) {
+		params := manifests.Params{
+			Registry: registryv1alpha1.Registry{
+				ObjectMeta: meta,
+				Spec: registryv1alpha1.RegistrySpec{
+					Storage: registryv1alpha1.Storage{
+						PersistentVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{},
+					},
+					Monitoring: &registryv1alpha1.Monitoring{
+						Alerts: &registryv1alpha1.Alerts{
+							Enabled: true,
+							Labels:  map[string]string{"team": "platform"},
+							PodsNotReady: &registryv1alpha1.AlertRule{
+								Enabled: ptr.To(false),
+							},
+							PersistentVolumeFillingUp: &registryv1alpha1.AlertRule{
+								Threshold: ptr.To[int32](90),
+								For:       &metav1.Duration{Duration: time.Hour},
+								Severity:  "critical",
+							},
+						},
+					},
+				},
+			},
+		}
+
+		actual, err := PrometheusRule(t.Context(), params)
+		require.NoError(t, err)
+
+		rules := actual.Spec.Groups[0].Rules
+		require.Len(t, rules, 3)
+		for _, rule := range rules {
+			assert.NotEqual(t, "RegistryPodsNotReady", rule.Alert)
+			assert.Equal(t, "platform", rule.Labels["team"])
+		}
+
+		volume := rules[len(rules)-1]
+		assert.Equal(t, "RegistryPersistentVolumeFillingUp", volume.Alert)
+		assert.Contains(t, volume.Expr.String(), `persistentvolumeclaim="my-instance-registry"`)
+		assert.Contains(t, volume.Expr.String(), "> 90")
+		assert.Equal(t, "1h0m0s", string(*volume.For))
+		assert.Equal(t, "critical", volume.Labels["severity"])
+	})
+}
@@ -26,7 +26,13 @@ import (
 )
 
 const (
-	ComponentRegistry = "registry"
+	ComponentRegistry   = "registry"
+	ComponentNodeMirror = "node-mirror"
+	ComponentBackup     = "backup"
+	ComponentRestore    = "restore"
+	ComponentMigration  = "migration"
+	// ComponentScheduledSnapshot labels the scheduled VolumeSnapshots, which are not built from the Registry.
+	ComponentScheduledSnapshot = "scheduled-snapshot"
 )
 
 // Build creates the manifest for the registry resource.
@@ -39,6 +45,11 @@ func Build(ctx context.Context, params manifests.Params) ([]client.Object, error
 		manifests.Factory(Secret),
 		manifests.Factory(Service),
 		manifests.Factory(PersistentVolumeClaim),
+		manifests.Factory(PrometheusRule),
+		manifests.Factory(NetworkPolicy),
+		manifests.Factory(NodeMirror),
+		manifests.Factory(BackupCronJob),
+		manifests.Factory(BackupState),
 	}...)
 
 	for _, factory := range manifestFactories {
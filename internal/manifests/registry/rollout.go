@@ -0,0 +1,126 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"slices"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Revision returns the revision of the Deployment built for the given params, ignoring their Rollback.
+func Revision(ctx context.Context, params manifests.Params) (registryv1alpha1.RolloutRevision, error) {
+	params.Rollback = nil
+	dpl, err := Deployment(ctx, params)
+	if err != nil {
+		return registryv1alpha1.RolloutRevision{}, err
+	}
+	return DeploymentRevision(dpl), nil
+}
+
+// DeploymentRevision returns the revision of the Pod template of the given Registry Deployment.
+func DeploymentRevision(dpl *appsv1.Deployment) registryv1alpha1.RolloutRevision {
+	var revision registryv1alpha1.RolloutRevision
+	podSpec := dpl.Spec.Template.Spec.DeepCopy()
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		if container.Name == naming.Container() {
+			revision.Image = container.Image
+			container.Image = ""
+			// the backups and the migrations switch the read-only mode of any revision
+			container.Env = slices.DeleteFunc(container.Env, func(env corev1.EnvVar) bool {
+				return env.Name == envReadOnly
+			})
+		}
+	}
+	podSpec.Volumes = slices.DeleteFunc(podSpec.Volumes, func(volume corev1.Volume) bool {
+		if volume.Name != naming.ConfigVolume() {
+			return false
+		}
+		revision.ConfigSecret = configSecretName(volume.VolumeSource)
+		return true
+	})
+	// a Pod spec always marshals
+	revision.Template, _ = manifestutils.CalculateHash(podSpec)
+	return revision
+}
+
+// SameRevision reports whether the given revision is the recorded one.
+func SameRevision(revision, recorded registryv1alpha1.RolloutRevision) bool {
+	return revision == recorded
+}
+
+// configSecretName returns the name of the Secret the given configuration volume reads the configuration from.
+func configSecretName(source corev1.VolumeSource) string {
+	if source.Secret != nil {
+		return source.Secret.SecretName
+	}
+	if source.Projected != nil {
+		for _, projection := range source.Projected.Sources {
+			if projection.Secret != nil && slices.ContainsFunc(projection.Secret.Items, func(item corev1.KeyToPath) bool {
+				return item.Key == naming.DistributionConfig()
+			}) {
+				return projection.Secret.Name
+			}
+		}
+	}
+	return ""
+}
+
+// rollBack reverts the image and the configuration of the Pod template of the given Deployment to the given
+// revision. The configuration file is read from the Secret of the revision, while the htpasswd file is still
+// read from the current Secret, so that the Registry keeps authenticating the current RegistryUsers.
+func rollBack(dpl *appsv1.Deployment, revision *registryv1alpha1.RolloutRevision) {
+	podSpec := &dpl.Spec.Template.Spec
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == naming.Container() {
+			podSpec.Containers[i].Image = revision.Image
+		}
+	}
+	for i := range podSpec.Volumes {
+		volume := &podSpec.Volumes[i]
+		if volume.Name != naming.ConfigVolume() || volume.Secret == nil {
+			continue
+		}
+		current := volume.Secret.SecretName
+		volume.VolumeSource = corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{
+						Secret: &corev1.SecretProjection{
+							LocalObjectReference: corev1.LocalObjectReference{Name: revision.ConfigSecret},
+							Items: []corev1.KeyToPath{
+								{Key: naming.DistributionConfig(), Path: naming.DistributionConfig()},
+							},
+						},
+					},
+					{
+						Secret: &corev1.SecretProjection{
+							LocalObjectReference: corev1.LocalObjectReference{Name: current},
+							Items:                []corev1.KeyToPath{{Key: naming.Htpasswd(), Path: naming.Htpasswd()}},
+						},
+					},
+				},
+			},
+		}
+	}
+}
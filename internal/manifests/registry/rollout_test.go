@@ -0,0 +1,103 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestDeploymentRollback(t *testing.T) {
+	params := manifests.Params{
+		Registry: registryv1alpha1.Registry{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-instance", Namespace: "my-namespace"},
+			Spec: registryv1alpha1.RegistrySpec{
+				Image:          "registry:3.0.1",
+				Authentication: &registryv1alpha1.Authentication{Enabled: true},
+				RolloutPolicy: &registryv1alpha1.RolloutPolicy{
+					ProgressDeadlineSeconds: ptr.To[int32](120),
+					AutoRollback:            true,
+				},
+			},
+		},
+		Htpasswd: "user:password",
+	}
+
+	t.Run("should build the current revision", func(t *testing.T) {
+		revision, err := Revision(t.Context(), params)
+		require.NoError(t, err)
+		assert.Equal(t, "registry:3.0.1", revision.Image)
+
+		actual, err := Deployment(t.Context(), params)
+		require.NoError(t, err)
+		assert.Equal(t, revision, DeploymentRevision(actual))
+		assert.Equal(t, ptr.To[int32](120), actual.Spec.ProgressDeadlineSeconds)
+	})
+
+	t.Run("should build the revision rolled back to", func(t *testing.T) {
+		current, err := Revision(t.Context(), params)
+		require.NoError(t, err)
+
+		params := params
+		params.Rollback = &registryv1alpha1.RolloutRevision{
+			Image:        "registry:3.0.0",
+			ConfigSecret: "my-instance-0123456789",
+			Template:     current.Template,
+		}
+
+		actual, err := Deployment(t.Context(), params)
+		require.NoError(t, err)
+		assert.True(t, SameRevision(DeploymentRevision(actual), *params.Rollback))
+		assert.Equal(t, current.Template, DeploymentRevision(actual).Template)
+
+		// the configuration is rolled back, the users are the current ones
+		sources := actual.Spec.Template.Spec.Volumes[0].Projected.Sources
+		require.Len(t, sources, 2)
+		assert.Equal(t, params.Rollback.ConfigSecret, sources[0].Secret.Name)
+		assert.Equal(t, naming.DistributionConfig(), sources[0].Secret.Items[0].Key)
+		assert.Equal(t, current.ConfigSecret, sources[1].Secret.Name)
+		assert.Equal(t, naming.Htpasswd(), sources[1].Secret.Items[0].Key)
+	})
+
+	t.Run("should tell the changes of the Pod template", func(t *testing.T) {
+		current, err := Revision(t.Context(), params)
+		require.NoError(t, err)
+
+		params := params
+		params.ReadOnly = true
+		readOnly, err := Revision(t.Context(), params)
+		require.NoError(t, err)
+		assert.Equal(t, current, readOnly)
+
+		params.Registry.Spec.Resources = &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		}
+		resized, err := Revision(t.Context(), params)
+		require.NoError(t, err)
+		assert.NotEqual(t, current.Template, resized.Template)
+		assert.False(t, SameRevision(resized, current))
+	})
+}
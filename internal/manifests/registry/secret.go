@@ -21,15 +21,20 @@ import (
 	"maps"
 	"net/http"
 	"net/url"
+	"path"
 	"time"
 
 	"github.com/distribution/distribution/v3/configuration"
 
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
 	"github.com/registry-operator/registry-operator/internal/manifests"
 	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
 	"github.com/registry-operator/registry-operator/internal/naming"
+	"github.com/registry-operator/registry-operator/internal/registryuser"
+	"github.com/registry-operator/registry-operator/internal/s3client"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	yaml "sigs.k8s.io/yaml/goyaml.v2"
@@ -46,11 +51,18 @@ func Secret(ctx context.Context, params manifests.Params) (*corev1.Secret, error
 		return nil, err
 	}
 
-	hash, err := manifestutils.CalculateHash(cfg)
+	hash, err := configHash(cfg, params.Htpasswd)
 	if err != nil {
 		return nil, err
 	}
 
+	// the htpasswd file is written even without user, distribution generating a user when it is missing, and
+	// without authentication, for a rolled back configuration enabling it to authenticate the current users
+	data := map[string]string{
+		naming.DistributionConfig(): string(cfgYaml),
+		naming.Htpasswd():           params.Htpasswd,
+	}
+
 	name := naming.Secret(params.Registry.Name, hash)
 	labels := manifestutils.Labels(
 		params.Registry.ObjectMeta,
@@ -71,12 +83,18 @@ func Secret(ctx context.Context, params manifests.Params) (*corev1.Secret, error
 			Labels:      labels,
 			Annotations: annotations,
 		},
-		StringData: map[string]string{
-			naming.DistributionConfig(): string(cfgYaml),
-		},
+		StringData: data,
 	}, nil
 }
 
+// configHash returns the hash of the configuration files of the Registry, naming the Secret holding them.
+func configHash(cfg *configuration.Configuration, htpasswd string) (string, error) {
+	if htpasswd == "" {
+		return manifestutils.CalculateHash(cfg)
+	}
+	return manifestutils.CalculateHash([]any{cfg, htpasswd})
+}
+
 func generateConfig(
 	ctx context.Context,
 	params manifests.Params,
@@ -98,7 +116,7 @@ func generateConfig(
 		},
 	}
 
-	s3, err := newS3Config(ctx, params)
+	s3, err := S3Config(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +124,7 @@ func generateConfig(
 	switch {
 	case len(s3) > 0:
 		storage["s3"] = configuration.Parameters{
-			"rootdirectory": "/registry",
+			"rootdirectory": S3RootDirectory(params.Registry.Spec.Storage.S3),
 		}
 		maps.Insert(storage["s3"], maps.All(s3))
 
@@ -116,16 +134,41 @@ func generateConfig(
 		}
 	}
 
+	logConfig := configuration.Log{
+		Level: "debug",
+		Fields: map[string]interface{}{
+			"service":     "registry",
+			"environment": "development",
+		},
+	}
+	if l := params.Registry.Spec.Log; l != nil {
+		if l.Level != "" {
+			logConfig.Level = configuration.Loglevel(l.Level)
+		}
+		logConfig.Formatter = l.Formatter
+	}
+
+	proxy, err := newProxyConfig(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var auth configuration.Auth
+	if registryuser.AuthenticationEnabled(&params.Registry) {
+		auth = configuration.Auth{
+			"htpasswd": configuration.Parameters{
+				"realm": "Registry Realm",
+				"path":  path.Join(configMountPath, naming.Htpasswd()),
+			},
+		}
+	}
+
 	return &configuration.Configuration{
 		Version: "0.1",
-		Log: configuration.Log{
-			Level: "debug",
-			Fields: map[string]interface{}{
-				"service":     "registry",
-				"environment": "development",
-			},
-		},
+		Log:     logConfig,
 		Storage: storage,
+		Auth:    auth,
+		Proxy:   proxy,
 		HTTP: configuration.HTTP{
 			Addr: ":5000",
 			Debug: configuration.Debug{
@@ -149,7 +192,18 @@ func generateConfig(
 	}, nil
 }
 
-func newS3Config(ctx context.Context, params manifests.Params) (configuration.Parameters, error) {
+// S3RootDirectory returns the prefix of the keys of the objects stored by a Registry in the given S3 storage,
+// the root directory shared by the Registries stored in S3 before they had their own one by default.
+func S3RootDirectory(s3 *registryv1alpha1.S3StorageSource) string {
+	if s3.RootDirectory == "" {
+		return "/registry"
+	}
+	return s3.RootDirectory
+}
+
+// S3Config returns the parameters of the S3 storage driver read from the Secrets referenced by the Registry,
+// nil when it is not stored in S3.
+func S3Config(ctx context.Context, params manifests.Params) (configuration.Parameters, error) {
 	s3 := params.Registry.Spec.Storage.S3
 	if s3 == nil {
 		return nil, nil
@@ -208,16 +262,12 @@ func newS3Config(ctx context.Context, params manifests.Params) (configuration.Pa
 		if err != nil {
 			errs = errors.Join(errs, err)
 		} else {
+			endpoint = s3client.EndpointURL(endpoint)
 			u, err := url.Parse(endpoint)
 			if err != nil {
-				return nil, fmt.Errorf("invalid endpoint URL: %w", err)
+				return nil, fmt.Errorf("%w: invalid endpoint URL: %w", manifests.ErrInvalidConfig, err)
 			}
-
-			switch u.Scheme {
-			case "":
-				u.Scheme = "https"
-				endpoint = u.String()
-			case "http":
+			if u.Scheme == "http" {
 				s3c["secure"] = false
 			}
 
@@ -225,9 +275,54 @@ func newS3Config(ctx context.Context, params manifests.Params) (configuration.Pa
 		}
 	}
 
+	if s3.ForcePathStyle != nil {
+		s3c["forcepathstyle"] = *s3.ForcePathStyle
+	}
+
 	return s3c, errs
 }
 
+func newProxyConfig(ctx context.Context, params manifests.Params) (configuration.Proxy, error) {
+	p := params.Registry.Spec.Proxy
+	if p == nil {
+		return configuration.Proxy{}, nil
+	}
+
+	proxy := configuration.Proxy{
+		RemoteURL: p.RemoteURL,
+	}
+	if p.TTL != nil {
+		proxy.TTL = &p.TTL.Duration
+	}
+
+	nn := client.ObjectKey{
+		Namespace: params.Registry.GetNamespace(),
+	}
+
+	var (
+		err  error
+		errs error
+	)
+
+	if opt := p.Username; opt != nil {
+		nn.Name = opt.Name
+		proxy.Username, err = getDataFromSecret(ctx, params.Client, nn, opt.Key)
+		if err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	if opt := p.Password; opt != nil {
+		nn.Name = opt.Name
+		proxy.Password, err = getDataFromSecret(ctx, params.Client, nn, opt.Key)
+		if err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return proxy, errs
+}
+
 func getDataFromSecret(
 	ctx context.Context,
 	cli client.Client,
@@ -236,13 +331,15 @@ func getDataFromSecret(
 ) (string, error) {
 	sec := &corev1.Secret{}
 
-	if err := cli.Get(ctx, nn, sec); err != nil {
+	if err := cli.Get(ctx, nn, sec); apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("%w: failed to fetch secret %v: %w", manifests.ErrInvalidConfig, nn, err)
+	} else if err != nil {
 		return "", fmt.Errorf("failed to fetch secret %v: %w", nn, err)
 	}
 
 	val, ok := sec.Data[key]
 	if !ok {
-		return "", fmt.Errorf("value for %s not found in %s", key, nn)
+		return "", fmt.Errorf("%w: value for %s not found in %s", manifests.ErrInvalidConfig, key, nn)
 	}
 
 	return string(val), nil
@@ -18,16 +18,23 @@
 package registry
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
 	"github.com/registry-operator/registry-operator/internal/manifests"
 	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
 	"github.com/registry-operator/registry-operator/internal/naming"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	_ "embed"
 )
@@ -62,6 +69,7 @@ func TestDesiredSecret(t *testing.T) {
 
 		expectedData := map[string]string{
 			"config.yaml": devConfig,
+			"htpasswd":    "",
 		}
 
 		config, _ := generateConfig(t.Context(), params)
@@ -82,3 +90,94 @@ func TestDesiredSecret(t *testing.T) {
 		}
 	})
 }
+
+func TestDesiredSecretWithHtpasswd(t *testing.T) {
+	registry := registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "my-namespace",
+		},
+	}
+	htpasswd := "ci:$2a$10$hash\n"
+
+	anonymous, err := Secret(t.Context(), manifests.Params{Registry: registry})
+	assert.NoError(t, err)
+	assert.Empty(t, anonymous.StringData["htpasswd"])
+	assert.NotContains(t, anonymous.StringData["config.yaml"], "htpasswd")
+
+	registry.Spec.Authentication = &registryv1alpha1.Authentication{Enabled: true}
+	withoutUsers, err := Secret(t.Context(), manifests.Params{Registry: registry})
+	assert.NoError(t, err)
+	actual, err := Secret(t.Context(), manifests.Params{Registry: registry, Htpasswd: htpasswd})
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, anonymous.Name, withoutUsers.Name, "a new Secret is expected to roll the Registry out")
+	assert.NotEqual(t, withoutUsers.Name, actual.Name, "a new Secret is expected to roll the Registry out")
+	assert.Equal(t, htpasswd, actual.StringData["htpasswd"])
+	assert.Contains(t, actual.StringData["config.yaml"], "/etc/distribution/htpasswd")
+	assert.Contains(t, withoutUsers.StringData, "htpasswd", "an empty file is expected to reject every client")
+	assert.Empty(t, withoutUsers.StringData["htpasswd"])
+
+	d, err := Deployment(t.Context(), manifests.Params{Registry: registry, Htpasswd: htpasswd})
+	assert.NoError(t, err)
+	volume := d.Spec.Template.Spec.Volumes[0]
+	assert.Equal(t, actual.Name, volume.Secret.SecretName)
+	assert.Len(t, volume.Secret.Items, 2)
+}
+
+func TestDesiredSecretWithProxy(t *testing.T) {
+	registry := registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "my-namespace",
+		},
+		Spec: registryv1alpha1.RegistrySpec{
+			Proxy: &registryv1alpha1.Proxy{
+				RemoteURL: "https://registry-1.docker.io",
+				Username: &registryv1alpha1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "hub"},
+					Key:                  "username",
+				},
+				Password: &registryv1alpha1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "hub"},
+					Key:                  "password",
+				},
+				TTL: &metav1.Duration{Duration: time.Hour},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub", Namespace: "my-namespace"},
+		Data: map[string][]byte{
+			"username": []byte("user"),
+			"password": []byte("secret"),
+		},
+	}
+	cli := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	actual, err := Secret(t.Context(), manifests.Params{Client: cli, Registry: registry})
+	require.NoError(t, err)
+
+	cfg := actual.StringData["config.yaml"]
+	assert.Contains(t, cfg, "remoteurl: https://registry-1.docker.io")
+	assert.Contains(t, cfg, "username: user")
+	assert.Contains(t, cfg, "password: secret")
+	assert.Contains(t, cfg, "ttl: 1h0m0s")
+
+	t.Run("fails on a missing Secret", func(t *testing.T) {
+		_, err := Secret(t.Context(), manifests.Params{Client: fake.NewClientBuilder().Build(), Registry: registry})
+		assert.ErrorIs(t, err, manifests.ErrInvalidConfig)
+	})
+
+	t.Run("does not report a failed lookup as an invalid configuration", func(t *testing.T) {
+		cli := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(context.Context, client.WithWatch, client.ObjectKey, client.Object, ...client.GetOption) error {
+				return assert.AnError
+			},
+		}).Build()
+
+		_, err := Secret(t.Context(), manifests.Params{Client: cli, Registry: registry})
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NotErrorIs(t, err, manifests.ErrInvalidConfig)
+	})
+}
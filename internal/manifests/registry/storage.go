@@ -23,14 +23,36 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// The VolumeSnapshots of the CSI snapshot CRDs.
+const (
+	VolumeSnapshotGroup = "snapshot.storage.k8s.io"
+	VolumeSnapshotKind  = "VolumeSnapshot"
 )
 
 func PersistentVolumeClaim(ctx context.Context, params manifests.Params) (*corev1.PersistentVolumeClaim, error) {
 	template := params.Registry.Spec.Storage.PersistentVolumeClaimTemplate
+	if m := params.Migration; template == nil && m != nil {
+		// the images are migrated to the claim before the Registry uses it
+		template = m.Target.PersistentVolumeClaimTemplate
+	}
 	if template == nil {
 		return nil, nil
 	}
 
+	spec := template.DeepCopy()
+	if snapshots := params.Registry.Spec.Snapshots; snapshots != nil && snapshots.RestoreFrom != "" &&
+		spec.DataSource == nil && spec.DataSourceRef == nil {
+		// the data source is only used when the claim is created, changing it afterwards has no effect
+		spec.DataSourceRef = &corev1.TypedObjectReference{
+			APIGroup: ptr.To(VolumeSnapshotGroup),
+			Kind:     VolumeSnapshotKind,
+			Name:     snapshots.RestoreFrom,
+		}
+	}
+
 	name := naming.PersistentVolumeClaim(params.Registry.Name)
 	labels := manifestutils.Labels(
 		params.Registry.ObjectMeta,
@@ -51,6 +73,6 @@ func PersistentVolumeClaim(ctx context.Context, params manifests.Params) (*corev
 			Labels:      labels,
 			Annotations: annotations,
 		},
-		Spec: *template,
+		Spec: *spec,
 	}, nil
 }
@@ -13,3 +13,66 @@
 // limitations under the License.
 
 package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestPersistentVolumeClaim(t *testing.T) {
+	registry := registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "my-namespace",
+		},
+		Spec: registryv1alpha1.RegistrySpec{
+			Storage: registryv1alpha1.Storage{
+				PersistentVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				},
+			},
+		},
+	}
+
+	t.Run("should not return PersistentVolumeClaim without template", func(t *testing.T) {
+		params := manifests.Params{
+			Registry: registryv1alpha1.Registry{ObjectMeta: registry.ObjectMeta},
+		}
+
+		actual, err := PersistentVolumeClaim(t.Context(), params)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	t.Run("should return PersistentVolumeClaim", func(t *testing.T) {
+		actual, err := PersistentVolumeClaim(t.Context(), manifests.Params{Registry: registry})
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, "my-instance-registry", actual.Name)
+		assert.Equal(t, *registry.Spec.Storage.PersistentVolumeClaimTemplate, actual.Spec)
+	})
+
+	t.Run("should restore PersistentVolumeClaim from VolumeSnapshot", func(t *testing.T) {
+		registry := *registry.DeepCopy()
+		registry.Spec.Snapshots = &registryv1alpha1.Snapshots{RestoreFrom: "my-snapshot"}
+
+		actual, err := PersistentVolumeClaim(t.Context(), manifests.Params{Registry: registry})
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, &corev1.TypedObjectReference{
+			APIGroup: ptr.To("snapshot.storage.k8s.io"),
+			Kind:     "VolumeSnapshot",
+			Name:     "my-snapshot",
+		}, actual.Spec.DataSourceRef)
+		assert.Nil(t, registry.Spec.Storage.PersistentVolumeClaimTemplate.DataSourceRef)
+	})
+}
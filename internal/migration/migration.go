@@ -0,0 +1,255 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package migration implements the migrate command of the operator image, run by the Jobs copying the storage
+// of the Registries between storage drivers, from a filesystem to an S3 bucket and back.
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/registry-operator/registry-operator/internal/s3client"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// CommandMigrate is the command of the operator image migrating a storage.
+const CommandMigrate = "migrate"
+
+// Environment variables configuring the command. The source and the target are either the root directory of a
+// filesystem storage, or an S3 bucket.
+const (
+	EnvSourceRoot      = "MIGRATION_SOURCE_ROOT"
+	EnvSourceBucket    = "MIGRATION_SOURCE_S3_BUCKET"
+	EnvSourceRegion    = "MIGRATION_SOURCE_S3_REGION"
+	EnvSourceEndpoint  = "MIGRATION_SOURCE_S3_ENDPOINT"
+	EnvSourceAccessKey = "MIGRATION_SOURCE_S3_ACCESS_KEY"
+	EnvSourceSecretKey = "MIGRATION_SOURCE_S3_SECRET_KEY"
+	EnvSourcePrefix    = "MIGRATION_SOURCE_S3_PREFIX"
+	EnvTargetRoot      = "MIGRATION_TARGET_ROOT"
+	EnvTargetBucket    = "MIGRATION_TARGET_S3_BUCKET"
+	EnvTargetRegion    = "MIGRATION_TARGET_S3_REGION"
+	EnvTargetEndpoint  = "MIGRATION_TARGET_S3_ENDPOINT"
+	EnvTargetAccessKey = "MIGRATION_TARGET_S3_ACCESS_KEY"
+	EnvTargetSecretKey = "MIGRATION_TARGET_S3_SECRET_KEY"
+	EnvTargetPrefix    = "MIGRATION_TARGET_S3_PREFIX"
+)
+
+// ExitCodeVerificationFailed is the exit code of the command when the copy does not match the source,
+// which the Jobs do not retry.
+const ExitCodeVerificationFailed = 2
+
+// ErrVerificationFailed is returned when the copy does not match the source.
+var ErrVerificationFailed = errors.New("verification failed")
+
+// blobPattern matches the paths of the blobs in the storage tree of the Registry, named after their digest.
+var blobPattern = regexp.MustCompile(`^docker/registry/v2/blobs/(sha256|sha384|sha512)/[0-9a-f]{2}/([0-9a-f]+)/data$`)
+
+// maxMismatches is the number of mismatches reported by a failed verification.
+const maxMismatches = 10
+
+// Location locates a storage.
+type Location struct {
+	// Root is the root directory of a filesystem storage.
+	Root string
+	// S3 locates the bucket of an S3 storage, used when Root is empty.
+	S3 s3client.Config
+	// Prefix is the prefix of the keys of an S3 storage, its root directory.
+	Prefix string
+}
+
+// Options configures a migration.
+type Options struct {
+	// Source is the storage copied.
+	Source Location
+	// Target is the storage the Source is copied to.
+	Target Location
+}
+
+// IsCommand reports whether the given argument is a command of this package.
+func IsCommand(arg string) bool {
+	return arg == CommandMigrate
+}
+
+// Run runs the given command configured by the environment.
+func Run(ctx context.Context, command string) error {
+	if command != CommandMigrate {
+		return fmt.Errorf("unknown command %q", command)
+	}
+	opts, err := OptionsFromEnv()
+	if err != nil {
+		return err
+	}
+	return Migrate(ctx, opts)
+}
+
+// OptionsFromEnv reads the Options from the environment.
+func OptionsFromEnv() (Options, error) {
+	source, sourceErr := locationFromEnv(EnvSourceRoot, EnvSourceBucket, EnvSourceRegion, EnvSourceEndpoint,
+		EnvSourceAccessKey, EnvSourceSecretKey, EnvSourcePrefix)
+	target, targetErr := locationFromEnv(EnvTargetRoot, EnvTargetBucket, EnvTargetRegion, EnvTargetEndpoint,
+		EnvTargetAccessKey, EnvTargetSecretKey, EnvTargetPrefix)
+	return Options{Source: source, Target: target}, errors.Join(sourceErr, targetErr)
+}
+
+func locationFromEnv(root, bucket, region, endpoint, accessKey, secretKey, prefix string) (Location, error) {
+	l := Location{
+		Root: os.Getenv(root),
+		S3: s3client.Config{
+			Bucket:    os.Getenv(bucket),
+			Region:    os.Getenv(region),
+			Endpoint:  s3client.EndpointURL(os.Getenv(endpoint)),
+			AccessKey: os.Getenv(accessKey),
+			SecretKey: os.Getenv(secretKey),
+		},
+		Prefix: os.Getenv(prefix),
+	}
+	if l.Root != "" {
+		return l, nil
+	}
+	if l.S3.Bucket == "" || l.S3.Region == "" {
+		return l, fmt.Errorf("either %s, or %s and %s must be set", root, bucket, region)
+	}
+	return l, nil
+}
+
+// Migrate copies every file of the source storage to the target storage, then verifies that the target holds
+// every file of the source with the same size, and that the content of its blobs matches their digest.
+func Migrate(ctx context.Context, opts Options) error {
+	log := ctrl.Log.WithName("migration")
+
+	source, err := newStore(opts.Source)
+	if err != nil {
+		return err
+	}
+	target, err := newStore(opts.Target)
+	if err != nil {
+		return err
+	}
+
+	files, err := source.list(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the source: %w", err)
+	}
+	log.Info("Copying the storage", "from", source, "to", target, "files", len(files))
+
+	var copied int64
+	for i, f := range files {
+		if err := copyFile(ctx, source, target, f); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", f.path, err)
+		}
+		copied += f.size
+		if (i+1)%1000 == 0 {
+			log.Info("Copying the storage", "files", i+1, "bytes", copied)
+		}
+	}
+
+	log.Info("Verifying the copy", "files", len(files), "bytes", copied)
+	blobs, err := verify(ctx, files, target)
+	if err != nil {
+		return err
+	}
+	log.Info("Migrated the storage", "files", len(files), "bytes", copied, "verifiedBlobs", blobs)
+	return nil
+}
+
+func copyFile(ctx context.Context, source, target store, f file) error {
+	r, err := source.open(ctx, f.path)
+	if err != nil {
+		return err
+	}
+	defer r.Close() //nolint:errcheck // read-only content
+	return target.create(ctx, f.path, r, f.size)
+}
+
+// verify checks that the target holds the given files of the source, and returns the number of blobs whose
+// content matches their digest.
+func verify(ctx context.Context, files []file, target store) (int, error) {
+	copies, err := target.list(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list the target: %w", err)
+	}
+	sizes := make(map[string]int64, len(copies))
+	for _, f := range copies {
+		sizes[f.path] = f.size
+	}
+
+	var mismatches []string
+	blobs := 0
+	for _, f := range files {
+		size, ok := sizes[f.path]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, fmt.Sprintf("%s is missing", f.path))
+			continue
+		case size != f.size:
+			mismatches = append(mismatches, fmt.Sprintf("%s has %d bytes instead of %d", f.path, size, f.size))
+			continue
+		}
+
+		match := blobPattern.FindStringSubmatch(f.path)
+		if match == nil {
+			continue
+		}
+		digest, err := digestOf(ctx, target, f.path, match[1])
+		if err != nil {
+			return blobs, fmt.Errorf("failed to read %s: %w", f.path, err)
+		}
+		if digest != match[2] {
+			mismatches = append(mismatches, fmt.Sprintf("%s has digest %s:%s", f.path, match[1], digest))
+			continue
+		}
+		blobs++
+	}
+
+	if len(mismatches) > 0 {
+		if len(mismatches) > maxMismatches {
+			mismatches = append(mismatches[:maxMismatches], fmt.Sprintf("and %d more", len(mismatches)-maxMismatches))
+		}
+		return blobs, fmt.Errorf("%w: %s", ErrVerificationFailed, strings.Join(mismatches, ", "))
+	}
+	return blobs, nil
+}
+
+// digestOf returns the hex encoded digest of the given file with the given algorithm.
+func digestOf(ctx context.Context, s store, path, algorithm string) (string, error) {
+	var h hash.Hash
+	switch algorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha384":
+		h = sha512.New384()
+	default:
+		h = sha512.New()
+	}
+
+	r, err := s.open(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close() //nolint:errcheck // read-only content
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/registry-operator/registry-operator/internal/s3client/s3test"
+)
+
+func blobPath(content string) string {
+	digest := fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+	return fmt.Sprintf("docker/registry/v2/blobs/sha256/%s/%s/data", digest[:2], digest)
+}
+
+func TestMigrate(t *testing.T) {
+	s3 := s3test.NewServer(t, nil)
+	bucket := Location{S3: s3.Config(), Prefix: "registry"}
+
+	source := t.TempDir()
+	s3test.WriteFile(t, filepath.Join(source, blobPath("layer")), "layer")
+	s3test.WriteFile(t, filepath.Join(source, "docker/registry/v2/repositories/app/_layers/link"), "sha256:abcd")
+	require.NoError(t, os.MkdirAll(filepath.Join(source, lostAndFound), 0o700))
+
+	t.Run("copies a filesystem to a bucket", func(t *testing.T) {
+		require.NoError(t, Migrate(t.Context(), Options{Source: Location{Root: source}, Target: bucket}))
+
+		assert.Equal(t, map[string][]byte{
+			"registry/" + blobPath("layer"):                             []byte("layer"),
+			"registry/docker/registry/v2/repositories/app/_layers/link": []byte("sha256:abcd"),
+		}, s3.Objects())
+	})
+
+	t.Run("copies a bucket to a filesystem", func(t *testing.T) {
+		target := t.TempDir()
+		require.NoError(t, Migrate(t.Context(), Options{Source: bucket, Target: Location{Root: target}}))
+
+		content, err := os.ReadFile(filepath.Join(target, blobPath("layer")))
+		require.NoError(t, err)
+		assert.Equal(t, "layer", string(content))
+		assert.FileExists(t, filepath.Join(target, "docker/registry/v2/repositories/app/_layers/link"))
+	})
+
+	t.Run("fails the verification of a blob not matching its digest", func(t *testing.T) {
+		corrupted := t.TempDir()
+		s3test.WriteFile(t, filepath.Join(corrupted, blobPath("layer")), "other")
+
+		err := Migrate(t.Context(), Options{Source: Location{Root: corrupted}, Target: Location{Root: t.TempDir()}})
+		assert.ErrorIs(t, err, ErrVerificationFailed)
+		assert.ErrorContains(t, err, blobPath("layer"))
+	})
+}
+
+func TestDirStoreCreateEscape(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "storage")
+	require.NoError(t, os.Mkdir(root, 0o755))
+
+	err := dirStore{root: root}.create(t.Context(), "../escaped", strings.NewReader("x"), 1)
+	assert.Error(t, err)
+	assert.NoFileExists(t, filepath.Join(parent, "escaped"))
+}
+
+func TestOptionsFromEnv(t *testing.T) {
+	t.Run("reads a filesystem source and a bucket target", func(t *testing.T) {
+		t.Setenv(EnvSourceRoot, "/mnt/source")
+		t.Setenv(EnvTargetBucket, "bucket")
+		t.Setenv(EnvTargetRegion, "eu-west-1")
+		t.Setenv(EnvTargetEndpoint, "minio.example.com")
+		t.Setenv(EnvTargetPrefix, "registry")
+
+		opts, err := OptionsFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, "/mnt/source", opts.Source.Root)
+		assert.Equal(t, "https://minio.example.com", opts.Target.S3.Endpoint)
+		assert.Equal(t, "registry", opts.Target.Prefix)
+	})
+
+	t.Run("requires a location", func(t *testing.T) {
+		t.Setenv(EnvSourceRoot, "/mnt/source")
+		_, err := OptionsFromEnv()
+		assert.ErrorContains(t, err, EnvTargetRoot)
+	})
+}
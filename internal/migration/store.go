@@ -0,0 +1,167 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/registry-operator/registry-operator/internal/s3client"
+)
+
+// lostAndFound is created at the root of the ext filesystems of the volumes, and belongs to them.
+const lostAndFound = "lost+found"
+
+// file is a file of a storage, with a slash-separated path relative to its root.
+type file struct {
+	path string
+	size int64
+}
+
+// store reads and writes the files of a storage.
+type store interface {
+	// list returns the files of the storage sorted by path.
+	list(ctx context.Context) ([]file, error)
+	// open returns the content of the given file, to be closed by the caller.
+	open(ctx context.Context, path string) (io.ReadCloser, error)
+	// create writes the given content of the given size to the given file, replacing it if it exists.
+	create(ctx context.Context, path string, r io.Reader, size int64) error
+}
+
+func newStore(l Location) (store, error) {
+	if l.Root != "" {
+		return dirStore{root: l.Root}, nil
+	}
+	cli, err := s3client.New(l.S3, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s3Store{cli: cli, bucket: l.S3.Bucket, prefix: strings.Trim(l.Prefix, "/")}, nil
+}
+
+// dirStore is a filesystem storage rooted in a directory.
+type dirStore struct {
+	root string
+}
+
+func (s dirStore) String() string {
+	return s.root
+}
+
+func (s dirStore) list(_ context.Context) ([]file, error) {
+	var files []file
+	err := filepath.WalkDir(s.root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.root, name)
+		if err != nil {
+			return err
+		}
+		if rel == lostAndFound {
+			return filepath.SkipDir
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, file{path: filepath.ToSlash(rel), size: info.Size()})
+		return nil
+	})
+	return files, err
+}
+
+func (s dirStore) open(_ context.Context, name string) (io.ReadCloser, error) {
+	root, err := os.OpenRoot(s.root)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close() //nolint:errcheck // the opened file outlives its root
+	return root.Open(filepath.FromSlash(name))
+}
+
+func (s dirStore) create(_ context.Context, name string, r io.Reader, _ int64) error {
+	// the paths listed from a bucket may not escape the root directory
+	root, err := os.OpenRoot(s.root)
+	if err != nil {
+		return err
+	}
+	defer root.Close() //nolint:errcheck // read-only handle
+
+	name = filepath.FromSlash(name)
+	if err := root.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f, err := root.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// s3Store is an S3 storage rooted in a prefix of a bucket.
+type s3Store struct {
+	cli    *s3client.Client
+	bucket string
+	prefix string
+}
+
+func (s s3Store) String() string {
+	return "s3://" + path.Join(s.bucket, s.prefix)
+}
+
+func (s s3Store) list(ctx context.Context) ([]file, error) {
+	prefix := s.prefix + "/"
+	if s.prefix == "" {
+		prefix = ""
+	}
+	objects, err := s.cli.ListObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]file, 0, len(objects))
+	for _, object := range objects {
+		name := strings.TrimPrefix(object.Key, prefix)
+		// the keys ending with a slash are directory markers
+		if name == "" || strings.HasSuffix(name, "/") {
+			continue
+		}
+		files = append(files, file{path: name, size: object.Size})
+	}
+	slices.SortFunc(files, func(a, b file) int { return strings.Compare(a.path, b.path) })
+	return files, nil
+}
+
+func (s s3Store) open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return s.cli.Get(ctx, path.Join(s.prefix, name))
+}
+
+func (s s3Store) create(ctx context.Context, name string, r io.Reader, size int64) error {
+	return s.cli.Put(ctx, path.Join(s.prefix, name), r, size)
+}
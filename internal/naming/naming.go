@@ -18,10 +18,21 @@
 // Package naming is for determining the names for components (containers, services, ...).
 package naming
 
+import "strings"
+
 func Secret(registry, hash string) string {
 	return DNSName(Truncate("%s-%s", 63, registry, hash))
 }
 
+// IsSecret reports whether the given name is the name of a configuration Secret of the registry, as returned
+// by Secret for the hex-encoded SHA-256 hash of a configuration.
+func IsSecret(registry, name string) bool {
+	template := Secret(registry, strings.Repeat("0", 64))
+	prefix := strings.TrimRight(template, "0")
+	hash, ok := strings.CutPrefix(name, prefix)
+	return ok && len(name) == len(template) && strings.Trim(hash, "0123456789abcdef") == ""
+}
+
 func ConfigVolume() string {
 	return "config"
 }
@@ -68,3 +79,70 @@ func RegistryMetricsPort() string {
 func ServiceAccount(registry string) string {
 	return DNSName(Truncate("%s-registry", 63, registry))
 }
+
+// PrometheusRule builds the PrometheusRule name based on the instance.
+func PrometheusRule(registry string) string {
+	return DNSName(Truncate("%s-registry", 63, registry))
+}
+
+// NetworkPolicy builds the NetworkPolicy name based on the instance.
+func NetworkPolicy(registry string) string {
+	return DNSName(Truncate("%s-registry", 63, registry))
+}
+
+// RegistryUserSecret builds the name of the Secret holding the credentials of a RegistryUser.
+func RegistryUserSecret(user string) string {
+	return DNSName(Truncate("%s-registry-credentials", 63, user))
+}
+
+// Htpasswd returns the name of the htpasswd file of the Registry.
+func Htpasswd() string {
+	return "htpasswd"
+}
+
+// S3RootDirectory builds the root directory of the objects stored by the Registry in S3.
+func S3RootDirectory(namespace, registry string) string {
+	return "/registry/" + namespace + "/" + registry
+}
+
+// PullSecret builds the name of the pull Secret replicated into the namespaces consuming the Registry.
+func PullSecret(namespace, registry string) string {
+	return DNSName(Truncate("%s-%s-registry-pull", 63, namespace, registry))
+}
+
+// NodeMirror builds the name of the DaemonSet configuring the nodes to use the Registry as a mirror.
+func NodeMirror(registry string) string {
+	return DNSName(Truncate("%s-registry-node-mirror", 63, registry))
+}
+
+// FinalSnapshot builds the name of the VolumeSnapshot taken when the Registry is deleted.
+func FinalSnapshot(registry string) string {
+	return DNSName(Truncate("%s-registry-final", 63, registry))
+}
+
+// Snapshot builds the name of the scheduled VolumeSnapshot of the Registry taken at the given time, formatted
+// as a UTC timestamp such as 20250102-030405.
+func Snapshot(registry, timestamp string) string {
+	return DNSName(Truncate("%s-registry-%s", 63, registry, timestamp))
+}
+
+// Migration builds the name of the Job migrating the storage of the Registry.
+func Migration(registry string) string {
+	return DNSName(Truncate("%s-registry-migration", 63, registry))
+}
+
+// Backup builds the name of the backup CronJob based on the instance, shorter than the 52 characters allowed
+// for CronJobs so that the names of their Jobs fit in 63 characters.
+func Backup(registry string) string {
+	return DNSName(Truncate("%s-registry-backup", 52, registry))
+}
+
+// BackupState builds the name of the ConfigMap sharing the state of the Registry with its backup Jobs.
+func BackupState(registry string) string {
+	return DNSName(Truncate("%s-registry-backup", 63, registry))
+}
+
+// RestoreJob builds the name of the Job restoring a backup based on the RegistryRestore.
+func RestoreJob(restore string) string {
+	return DNSName(Truncate("%s-restore", 63, restore))
+}
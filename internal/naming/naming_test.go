@@ -0,0 +1,30 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package naming
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSecret(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+
+	assert.True(t, IsSecret("my-registry", Secret("my-registry", hash)))
+	assert.False(t, IsSecret("my-registry", "my-registry-htpasswd"))
+	assert.False(t, IsSecret("my-registry", Secret("my-registry", hash)[1:]))
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package registryclass resolves the RegistryClass of a Registry and applies its defaults.
+package registryclass
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/webhook/validation"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrNotFound is returned when the RegistryClass named by a Registry does not exist.
+var ErrNotFound = errors.New("registry class not found")
+
+// IsDefault reports whether the given RegistryClass is marked as the default class.
+func IsDefault(class *registryv1alpha1.RegistryClass) bool {
+	return class.Annotations[registryv1alpha1.DefaultRegistryClassAnnotation] == "true"
+}
+
+// Default returns the RegistryClass marked as default, or nil if there is none. Like for StorageClasses, the most
+// recently created class is returned when several are marked as default.
+func Default(ctx context.Context, cli client.Client) (*registryv1alpha1.RegistryClass, error) {
+	list := &registryv1alpha1.RegistryClassList{}
+	if err := cli.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to list registry classes: %w", err)
+	}
+
+	var found *registryv1alpha1.RegistryClass
+	for i := range list.Items {
+		class := &list.Items[i]
+		if !IsDefault(class) {
+			continue
+		}
+		if found == nil || found.CreationTimestamp.Before(&class.CreationTimestamp) ||
+			(found.CreationTimestamp.Equal(&class.CreationTimestamp) && class.Name < found.Name) {
+			found = class
+		}
+	}
+	return found, nil
+}
+
+// Get returns the RegistryClass named by the given Registry, or nil if it does not name any. The default class
+// is only assigned to the Registries when they are created, so that marking another class as default does not
+// change the existing Registries.
+func Get(
+	ctx context.Context,
+	cli client.Client,
+	registry *registryv1alpha1.Registry,
+) (*registryv1alpha1.RegistryClass, error) {
+	name := registry.Spec.RegistryClassName
+	if name == "" {
+		return nil, nil
+	}
+
+	class := &registryv1alpha1.RegistryClass{}
+	if err := cli.Get(ctx, client.ObjectKey{Name: name}, class); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return nil, fmt.Errorf("failed to get registry class: %w", err)
+	}
+	return class, nil
+}
+
+// Apply returns a copy of the given Registry with the unset fields of its spec taken from the class.
+// The fields are taken as a whole: a field set on the Registry replaces the one of the class, e.g. resources
+// with only limits are not completed with the requests of the class.
+func Apply(
+	registry registryv1alpha1.Registry,
+	class *registryv1alpha1.RegistryClass,
+) registryv1alpha1.Registry {
+	effective := *registry.DeepCopy()
+	if class == nil {
+		return effective
+	}
+
+	defaults := class.Spec.DeepCopy()
+	spec := &effective.Spec
+	if validation.PopulatedFields(spec.Storage) == 0 {
+		spec.Storage = defaults.Storage
+	}
+	spec.Image = cmp.Or(spec.Image, defaults.Image)
+	spec.Resources = cmp.Or(spec.Resources, defaults.Resources)
+	spec.Affinity = cmp.Or(spec.Affinity, defaults.Affinity)
+	spec.Log = cmp.Or(spec.Log, defaults.Log)
+	spec.Monitoring = cmp.Or(spec.Monitoring, defaults.Monitoring)
+	spec.NetworkPolicy = cmp.Or(spec.NetworkPolicy, defaults.NetworkPolicy)
+	return effective
+}
@@ -0,0 +1,178 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryclass_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/registryclass"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	utilruntime.Must(registryv1alpha1.AddToScheme(s))
+	return s
+}
+
+func TestGet(t *testing.T) {
+	standard := &registryv1alpha1.RegistryClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "standard",
+			Annotations: map[string]string{registryv1alpha1.DefaultRegistryClassAnnotation: "true"},
+		},
+	}
+	fast := &registryv1alpha1.RegistryClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "fast"},
+	}
+	cli := fake.NewClientBuilder().WithScheme(newScheme()).WithObjects(standard, fast).Build()
+
+	t.Run("should get the named class", func(t *testing.T) {
+		registry := &registryv1alpha1.Registry{Spec: registryv1alpha1.RegistrySpec{RegistryClassName: "fast"}}
+		class, err := registryclass.Get(t.Context(), cli, registry)
+		require.NoError(t, err)
+		assert.Equal(t, "fast", class.Name)
+	})
+
+	t.Run("should not resolve the default class", func(t *testing.T) {
+		class, err := registryclass.Get(t.Context(), cli, &registryv1alpha1.Registry{})
+		require.NoError(t, err)
+		assert.Nil(t, class)
+	})
+
+	t.Run("should fail on a missing class", func(t *testing.T) {
+		registry := &registryv1alpha1.Registry{Spec: registryv1alpha1.RegistrySpec{RegistryClassName: "missing"}}
+		_, err := registryclass.Get(t.Context(), cli, registry)
+		assert.ErrorIs(t, err, registryclass.ErrNotFound)
+	})
+
+}
+
+func TestDefault(t *testing.T) {
+	standard := &registryv1alpha1.RegistryClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "standard",
+			Annotations:       map[string]string{registryv1alpha1.DefaultRegistryClassAnnotation: "true"},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second)),
+		},
+	}
+	fast := &registryv1alpha1.RegistryClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "fast"},
+	}
+
+	t.Run("should get the default class", func(t *testing.T) {
+		cli := fake.NewClientBuilder().WithScheme(newScheme()).WithObjects(standard, fast).Build()
+
+		class, err := registryclass.Default(t.Context(), cli)
+		require.NoError(t, err)
+		assert.Equal(t, "standard", class.Name)
+	})
+
+	t.Run("should get the newest of multiple default classes", func(t *testing.T) {
+		newer := standard.DeepCopy()
+		newer.Name = "newer"
+		newer.CreationTimestamp = metav1.NewTime(time.Now().Truncate(time.Second))
+		cli := fake.NewClientBuilder().WithScheme(newScheme()).WithObjects(standard, newer).Build()
+
+		class, err := registryclass.Default(t.Context(), cli)
+		require.NoError(t, err)
+		assert.Equal(t, "newer", class.Name)
+	})
+
+	t.Run("should get nothing without a default class", func(t *testing.T) {
+		cli := fake.NewClientBuilder().WithScheme(newScheme()).WithObjects(fast).Build()
+
+		class, err := registryclass.Default(t.Context(), cli)
+		require.NoError(t, err)
+		assert.Nil(t, class)
+	})
+}
+
+func TestApply(t *testing.T) {
+	class := &registryv1alpha1.RegistryClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+		Spec: registryv1alpha1.RegistryClassSpec{
+			Image: "registry:3.0.0",
+			Storage: registryv1alpha1.Storage{
+				PersistentVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{
+					StorageClassName: ptr.To("ssd"),
+				},
+			},
+			Resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+			},
+			Log:           &registryv1alpha1.Log{Level: "info", Formatter: "json"},
+			NetworkPolicy: &registryv1alpha1.NetworkPolicy{Enabled: true},
+		},
+	}
+
+	t.Run("should fill in unset fields", func(t *testing.T) {
+		registry := registryv1alpha1.Registry{
+			Spec: registryv1alpha1.RegistrySpec{
+				Log: &registryv1alpha1.Log{Level: "debug"},
+			},
+		}
+
+		effective := registryclass.Apply(registry, class)
+		assert.Equal(t, "registry:3.0.0", effective.Spec.Image)
+		assert.Equal(t, class.Spec.Storage, effective.Spec.Storage)
+		assert.Equal(t, class.Spec.Resources, effective.Spec.Resources)
+		assert.Equal(t, &registryv1alpha1.Log{Level: "debug"}, effective.Spec.Log, "fields are not merged")
+		assert.True(t, effective.Spec.NetworkPolicy.Enabled)
+		assert.Empty(t, registry.Spec.Image, "registry should not be modified")
+	})
+
+	t.Run("should not merge the resources", func(t *testing.T) {
+		resources := &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+		}
+		registry := registryv1alpha1.Registry{Spec: registryv1alpha1.RegistrySpec{Resources: resources}}
+
+		effective := registryclass.Apply(registry, class)
+		assert.Equal(t, resources, effective.Spec.Resources)
+	})
+
+	t.Run("should keep the storage of the registry", func(t *testing.T) {
+		storage := registryv1alpha1.Storage{
+			EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: ptr.To(resource.MustParse("1Gi"))},
+		}
+		registry := registryv1alpha1.Registry{
+			Spec: registryv1alpha1.RegistrySpec{Image: "registry:3.0.1", Storage: storage},
+		}
+
+		effective := registryclass.Apply(registry, class)
+		assert.Equal(t, "registry:3.0.1", effective.Spec.Image)
+		assert.Equal(t, storage, effective.Spec.Storage)
+	})
+
+	t.Run("should return the registry without a class", func(t *testing.T) {
+		registry := registryv1alpha1.Registry{Spec: registryv1alpha1.RegistrySpec{Image: "registry:3.0.1"}}
+
+		effective := registryclass.Apply(registry, nil)
+		assert.Equal(t, registry, effective)
+	})
+}
@@ -0,0 +1,202 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	schemeBasic  = "basic"
+	schemeBearer = "bearer"
+)
+
+// Credentials authenticate the requests to a Registry.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// challenge is the authentication requested by a Registry through the WWW-Authenticate header.
+type challenge struct {
+	scheme  string
+	realm   string
+	service string
+}
+
+// challenged records the authentication requested by the given unauthorized response and reports whether
+// the request is worth sending again. The body of the response is closed.
+func (c *Client) challenged(resp *http.Response, r request) bool {
+	_ = resp.Body.Close()
+
+	ch, ok := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+	if !ok {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	known := c.challenge != nil && *c.challenge == ch
+	c.challenge = &ch
+	scope := scopeFor(r)
+	_, cached := c.tokens[scope]
+	delete(c.tokens, scope)
+
+	switch ch.scheme {
+	case schemeBasic:
+		// credentials already sent are not going to work better
+		return !known && c.credentials != nil
+	case schemeBearer:
+		// a new token is obtained, unless the one just obtained is refused
+		return !known || cached
+	}
+	return false
+}
+
+// authorize adds the authorization requested by the Registry so far to the given request.
+func (c *Client) authorize(ctx context.Context, req *http.Request, r request) error {
+	c.mu.Lock()
+	ch := c.challenge
+	c.mu.Unlock()
+	if ch == nil {
+		return nil
+	}
+
+	switch ch.scheme {
+	case schemeBasic:
+		if c.credentials != nil {
+			req.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+		}
+	case schemeBearer:
+		token, err := c.token(ctx, *ch, scopeFor(r))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// token returns a bearer token for the given scope, requesting one from the authorization service if needed.
+func (c *Client) token(ctx context.Context, ch challenge, scope string) (string, error) {
+	c.mu.Lock()
+	token, ok := c.tokens[scope]
+	c.mu.Unlock()
+	if ok {
+		return token, nil
+	}
+
+	u, err := url.Parse(ch.realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization realm %q: %w", ch.realm, err)
+	}
+	query := u.Query()
+	if ch.service != "" {
+		query.Set("service", ch.service)
+	}
+	if scope != "" {
+		query.Set("scope", scope)
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.credentials != nil {
+		req.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get token: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("%w: failed to get token: %s", ErrUnauthorized, strings.TrimSpace(string(body)))
+	}
+
+	var response struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to decode token: %w", err)
+	}
+	token = response.Token
+	if token == "" {
+		token = response.AccessToken
+	}
+
+	c.mu.Lock()
+	c.tokens[scope] = token
+	c.mu.Unlock()
+	return token, nil
+}
+
+// scopeFor returns the scope of the token needed for the given request.
+func scopeFor(r request) string {
+	if r.repository == "" {
+		return ""
+	}
+	action := "pull,push"
+	switch r.method {
+	case http.MethodGet, http.MethodHead:
+		action = "pull"
+	case http.MethodDelete:
+		action = "delete"
+	}
+	return fmt.Sprintf("repository:%s:%s", r.repository, action)
+}
+
+// parseChallenge parses a WWW-Authenticate header, e.g. Bearer realm="https://auth.example.com/token",service="x".
+func parseChallenge(header string) (challenge, bool) {
+	scheme, params, _ := strings.Cut(strings.TrimSpace(header), " ")
+	ch := challenge{scheme: strings.ToLower(scheme)}
+	if ch.scheme != schemeBasic && ch.scheme != schemeBearer {
+		return challenge{}, false
+	}
+
+	for params != "" {
+		var key, value string
+		key, params, _ = strings.Cut(strings.TrimLeft(params, " ,"), "=")
+		if strings.HasPrefix(params, `"`) {
+			value, params, _ = strings.Cut(params[1:], `"`)
+		} else {
+			value, params, _ = strings.Cut(params, ",")
+		}
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "realm":
+			ch.realm = value
+		case "service":
+			ch.service = value
+		}
+	}
+
+	if ch.scheme == schemeBearer && ch.realm == "" {
+		return challenge{}, false
+	}
+	return ch, true
+}
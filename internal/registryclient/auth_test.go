@@ -0,0 +1,133 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/registry-operator/registry-operator/internal/registryclient/registrytest"
+)
+
+func TestParseChallenge(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		header   string
+		expected challenge
+		ok       bool
+	}{
+		{
+			name:     "basic",
+			header:   `Basic realm="Registry Realm"`,
+			expected: challenge{scheme: schemeBasic, realm: "Registry Realm"},
+			ok:       true,
+		},
+		{
+			name:     "bearer",
+			header:   `Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="x"`,
+			expected: challenge{scheme: schemeBearer, realm: "https://auth.docker.io/token", service: "registry.docker.io"},
+			ok:       true,
+		},
+		{
+			name:   "bearer without realm",
+			header: `Bearer service="registry.docker.io"`,
+		},
+		{
+			name:   "unsupported scheme",
+			header: `Digest realm="x"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, ok := parseChallenge(tc.header)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	server := registrytest.NewServer(t)
+	registrytest.Push(t, server, "team/app", "v1", time.Now())
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	credentials := &Credentials{Username: "user", Password: "secret"}
+
+	t.Run("should authenticate with basic auth", func(t *testing.T) {
+		protected := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if username, password, ok := r.BasicAuth(); !ok ||
+				username != credentials.Username || password != credentials.Password {
+				w.Header().Set("WWW-Authenticate", `Basic realm="Registry Realm"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			proxy.ServeHTTP(w, r)
+		}))
+		t.Cleanup(protected.Close)
+
+		cli, err := New(protected.URL, nil, credentials)
+		require.NoError(t, err)
+		tags, err := cli.Tags(t.Context(), "team/app")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"v1"}, tags)
+
+		anonymous, err := New(protected.URL, nil, nil)
+		require.NoError(t, err)
+		_, err = anonymous.Tags(t.Context(), "team/app")
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("should authenticate with a bearer token", func(t *testing.T) {
+		var scopes []string
+		mux := http.NewServeMux()
+		mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+			if username, password, ok := r.BasicAuth(); !ok ||
+				username != credentials.Username || password != credentials.Password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			scopes = append(scopes, r.URL.Query().Get("scope"))
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "t0k3n"})
+		})
+		var protected *httptest.Server
+		mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer t0k3n" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+protected.URL+`/token",service="test"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			proxy.ServeHTTP(w, r)
+		})
+		protected = httptest.NewServer(mux)
+		t.Cleanup(protected.Close)
+
+		cli, err := New(protected.URL, nil, credentials)
+		require.NoError(t, err)
+		for range 2 {
+			tags, err := cli.Tags(t.Context(), "team/app")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"v1"}, tags)
+		}
+		assert.Equal(t, []string{"repository:team/app:pull"}, scopes)
+	})
+}
@@ -0,0 +1,356 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package registryclient implements the parts of the distribution HTTP API used by the operator.
+package registryclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+const (
+	headerDockerContentDigest = "Docker-Content-Digest"
+	linkRelationNext          = `rel="next"`
+	maxManifestSize           = 4 << 20
+	defaultResponseTimeout    = 30 * time.Second
+)
+
+var acceptedManifestTypes = strings.Join([]string{
+	mediaTypeOCIManifest,
+	mediaTypeOCIIndex,
+	mediaTypeDockerManifest,
+	mediaTypeDockerManifestList,
+}, ",")
+
+var (
+	// ErrNotFound is returned when the requested repository, manifest or blob does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnauthorized is returned when the Registry denies the access to the requested resource.
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// Client talks to the HTTP API of a Registry.
+type Client struct {
+	baseURL     *url.URL
+	httpClient  *http.Client
+	credentials *Credentials
+
+	mu        sync.Mutex
+	challenge *challenge
+	tokens    map[string]string
+}
+
+// New returns a Client for the Registry served at the given base URL, e.g. http://registry.ns.svc:5000.
+// A nil httpClient defaults to a client waiting a limited time for the responses, without limiting the
+// transfer of blobs; nil credentials to anonymous access.
+func New(baseURL string, httpClient *http.Client, credentials *Credentials) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry URL: %w", err)
+	}
+	if httpClient == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.ResponseHeaderTimeout = defaultResponseTimeout
+		httpClient = &http.Client{Transport: transport}
+	}
+	return &Client{
+		baseURL:     u,
+		httpClient:  httpClient,
+		credentials: credentials,
+		tokens:      map[string]string{},
+	}, nil
+}
+
+// request is a request to the API of the Registry, for the given repository.
+type request struct {
+	method     string
+	repository string
+	url        *url.URL
+	header     http.Header
+
+	// body is sent as is and can be sent again once authenticated.
+	body []byte
+	// stream is sent with the given size, only once.
+	stream io.Reader
+	size   int64
+}
+
+// Manifest describes a manifest stored in a repository.
+type Manifest struct {
+	// Digest of the manifest.
+	Digest string
+	// MediaType of the manifest.
+	MediaType string
+	// Created is the creation time of the image, zero if unknown.
+	Created time.Time
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+}
+
+type manifestContent struct {
+	MediaType string       `json:"mediaType"`
+	Config    *descriptor  `json:"config,omitempty"`
+	Manifests []descriptor `json:"manifests,omitempty"`
+}
+
+type imageConfig struct {
+	Created *time.Time `json:"created,omitempty"`
+}
+
+// Tags returns all the tags of the given repository.
+func (c *Client) Tags(ctx context.Context, repository string) ([]string, error) {
+	var tags []string
+	next := c.url("v2", repository, "tags", "list")
+	for next != nil {
+		resp, err := c.do(ctx, request{method: http.MethodGet, repository: repository, url: next})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s: %w", repository, err)
+		}
+
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tags of %s: %w", repository, err)
+		}
+		tags = append(tags, page.Tags...)
+
+		next, err = c.nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// Manifest returns the manifest of the given repository referenced by a tag or digest.
+// The creation time of an image index is the one of the first image it references.
+func (c *Client) Manifest(ctx context.Context, repository, reference string) (Manifest, error) {
+	content, m, err := c.manifest(ctx, repository, reference)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to get manifest %s:%s: %w", repository, reference, err)
+	}
+
+	switch {
+	case content.Config != nil:
+		m.Created, err = c.created(ctx, repository, content.Config.Digest)
+		if err != nil {
+			return Manifest{}, err
+		}
+	case len(content.Manifests) > 0:
+		child, err := c.Manifest(ctx, repository, content.Manifests[0].Digest)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return Manifest{}, err
+		}
+		m.Created = child.Created
+	}
+	return m, nil
+}
+
+// DeleteManifest deletes the manifest with the given digest, along with all the tags referencing it.
+func (c *Client) DeleteManifest(ctx context.Context, repository, digest string) error {
+	resp, err := c.do(ctx, request{
+		method:     http.MethodDelete,
+		repository: repository,
+		url:        c.url("v2", repository, "manifests", digest),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete manifest %s@%s: %w", repository, digest, err)
+	}
+	return resp.Body.Close()
+}
+
+// manifest fetches the manifest referenced by a tag or digest, along with its digest and media type.
+func (c *Client) manifest(ctx context.Context, repository, reference string) (manifestContent, Manifest, error) {
+	body, m, err := c.rawManifest(ctx, repository, reference)
+	if err != nil {
+		return manifestContent{}, Manifest{}, err
+	}
+
+	var content manifestContent
+	if err := json.Unmarshal(body, &content); err != nil {
+		return manifestContent{}, Manifest{}, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return content, m, nil
+}
+
+// rawManifest fetches the manifest referenced by a tag or digest as it is stored in the Registry.
+func (c *Client) rawManifest(ctx context.Context, repository, reference string) ([]byte, Manifest, error) {
+	resp, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		repository: repository,
+		url:        c.url("v2", repository, "manifests", reference),
+		header:     http.Header{"Accept": []string{acceptedManifestTypes}},
+	})
+	if err != nil {
+		return nil, Manifest{}, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, Manifest{}, err
+	}
+
+	m := Manifest{
+		Digest:    resp.Header.Get(headerDockerContentDigest),
+		MediaType: mediaType(resp.Header),
+	}
+	if m.Digest == "" {
+		m.Digest = digestOf(body)
+	}
+	if m.MediaType == "" {
+		var content manifestContent
+		if err := json.Unmarshal(body, &content); err == nil {
+			m.MediaType = content.MediaType
+		}
+	}
+	return body, m, nil
+}
+
+// created returns the creation time recorded in the image config blob with the given digest.
+func (c *Client) created(ctx context.Context, repository, digest string) (time.Time, error) {
+	resp, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		repository: repository,
+		url:        c.url("v2", repository, "blobs", digest),
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get config %s@%s: %w", repository, digest, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+
+	var config imageConfig
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&config); err != nil {
+		// not an image config, e.g. an artifact
+		return time.Time{}, nil
+	}
+	if config.Created == nil {
+		return time.Time{}, nil
+	}
+	return *config.Created, nil
+}
+
+func (c *Client) url(elem ...string) *url.URL {
+	return c.baseURL.JoinPath(elem...)
+}
+
+// nextPage returns the URL of the next page referenced by the Link header of the response, if any.
+func (c *Client) nextPage(resp *http.Response) (*url.URL, error) {
+	for _, link := range resp.Header.Values("Link") {
+		target, params, _ := strings.Cut(link, ";")
+		if !strings.Contains(params, linkRelationNext) {
+			continue
+		}
+		ref, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid link %q: %w", link, err)
+		}
+		return c.baseURL.ResolveReference(ref), nil
+	}
+	return nil, nil
+}
+
+// do sends the request, authenticating if the Registry requires it, and returns the response when successful.
+// The caller must close the body of the response.
+func (c *Client) do(ctx context.Context, r request) (*http.Response, error) {
+	resp, err := c.send(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && r.stream == nil && c.challenged(resp, r) {
+		if resp, err = c.send(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, strings.TrimSpace(string(body)))
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("%w: %s", ErrUnauthorized, strings.TrimSpace(string(body)))
+	}
+	return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// send sends the request once, with the authorization known so far.
+func (c *Client) send(ctx context.Context, r request) (*http.Response, error) {
+	var body io.Reader
+	switch {
+	case r.body != nil:
+		body = bytes.NewReader(r.body)
+	case r.stream != nil:
+		body = r.stream
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, r.url.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if r.stream != nil {
+		req.ContentLength = r.size
+	}
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+
+	if err := c.authorize(ctx, req, r); err != nil {
+		return nil, err
+	}
+	return c.httpClient.Do(req)
+}
+
+// mediaType returns the media type of the Content-Type header, without parameters.
+func mediaType(header http.Header) string {
+	t, _, _ := strings.Cut(header.Get("Content-Type"), ";")
+	return strings.TrimSpace(t)
+}
+
+// digestOf returns the sha256 digest of the given content.
+func digestOf(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryclient_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/registry-operator/registry-operator/internal/registryclient"
+	"github.com/registry-operator/registry-operator/internal/registryclient/registrytest"
+)
+
+func TestClient(t *testing.T) {
+	server := registrytest.NewServer(t)
+	created := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	digest := registrytest.Push(t, server, "team/app", "v1", created)
+	registrytest.Push(t, server, "team/app", "latest", created)
+	for i := range 3 {
+		registrytest.Push(t, server, "team/app", fmt.Sprintf("build-%d", i), created.Add(time.Duration(i+1)*time.Hour))
+	}
+
+	cli, err := registryclient.New(server.URL, nil, nil)
+	require.NoError(t, err)
+
+	t.Run("should list tags", func(t *testing.T) {
+		tags, err := cli.Tags(t.Context(), "team/app")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"v1", "latest", "build-0", "build-1", "build-2"}, tags)
+	})
+
+	t.Run("should fail to list tags of a missing repository", func(t *testing.T) {
+		_, err := cli.Tags(t.Context(), "team/missing")
+		assert.ErrorIs(t, err, registryclient.ErrNotFound)
+	})
+
+	t.Run("should get manifest", func(t *testing.T) {
+		m, err := cli.Manifest(t.Context(), "team/app", "latest")
+		require.NoError(t, err)
+		assert.Equal(t, digest, m.Digest)
+		assert.Equal(t, "application/vnd.oci.image.manifest.v1+json", m.MediaType)
+		assert.True(t, created.Equal(m.Created), "expected %s, got %s", created, m.Created)
+	})
+
+	t.Run("should delete manifest with its tags", func(t *testing.T) {
+		require.NoError(t, cli.DeleteManifest(t.Context(), "team/app", digest))
+
+		tags, err := cli.Tags(t.Context(), "team/app")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"build-0", "build-1", "build-2"}, tags)
+
+		_, err = cli.Manifest(t.Context(), "team/app", digest)
+		assert.ErrorIs(t, err, registryclient.ErrNotFound)
+	})
+}
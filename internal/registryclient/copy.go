@@ -0,0 +1,210 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Image is the location of an image, a repository of a Registry and a tag.
+type Image struct {
+	Client     *Client
+	Repository string
+	Tag        string
+}
+
+// Copy copies the manifest of the source image to the target, along with the blobs and the manifests it
+// references. It returns the digest of the manifest, and whether the target was already up to date.
+func Copy(ctx context.Context, source, target Image) (string, bool, error) {
+	body, m, err := source.Client.rawManifest(ctx, source.Repository, source.Tag)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get source manifest: %w", err)
+	}
+
+	existing, err := target.Client.headManifest(ctx, target.Repository, target.Tag)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return "", false, fmt.Errorf("failed to get target manifest: %w", err)
+	}
+	if existing == m.Digest {
+		return m.Digest, true, nil
+	}
+
+	if err := copyManifest(ctx, source, target, body, m, target.Tag); err != nil {
+		return "", false, err
+	}
+	return m.Digest, false, nil
+}
+
+// copyManifest copies what the given manifest references, then pushes it to the target with the reference.
+func copyManifest(ctx context.Context, source, target Image, body []byte, m Manifest, reference string) error {
+	var content struct {
+		manifestContent
+		Layers []struct {
+			descriptor
+			URLs []string `json:"urls,omitempty"`
+		} `json:"layers,omitempty"`
+	}
+	if err := json.Unmarshal(body, &content); err != nil {
+		return fmt.Errorf("invalid manifest %s: %w", m.Digest, err)
+	}
+
+	for _, child := range content.Manifests {
+		childBody, childManifest, err := source.Client.rawManifest(ctx, source.Repository, child.Digest)
+		if err != nil {
+			return fmt.Errorf("failed to get source manifest: %w", err)
+		}
+		if err := copyManifest(ctx, source, target, childBody, childManifest, child.Digest); err != nil {
+			return err
+		}
+	}
+
+	if content.Config != nil {
+		if err := copyBlob(ctx, source, target, content.Config.Digest); err != nil {
+			return err
+		}
+	}
+	for _, layer := range content.Layers {
+		if len(layer.URLs) > 0 {
+			// foreign layers are not stored in the Registry
+			continue
+		}
+		if err := copyBlob(ctx, source, target, layer.Digest); err != nil {
+			return err
+		}
+	}
+
+	if err := target.Client.putManifest(ctx, target.Repository, reference, m.MediaType, body); err != nil {
+		return fmt.Errorf("failed to push manifest %s: %w", m.Digest, err)
+	}
+	return nil
+}
+
+// copyBlob copies the blob with the given digest from the source repository, unless the target already has it.
+func copyBlob(ctx context.Context, source, target Image, digest string) error {
+	exists, err := target.Client.blobExists(ctx, target.Repository, digest)
+	if err != nil {
+		return fmt.Errorf("failed to check blob %s: %w", digest, err)
+	}
+	if exists {
+		return nil
+	}
+
+	resp, err := source.Client.do(ctx, request{
+		method:     http.MethodGet,
+		repository: source.Repository,
+		url:        source.Client.url("v2", source.Repository, "blobs", digest),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get blob %s: %w", digest, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+
+	if err := target.Client.putBlob(ctx, target.Repository, digest, resp.Body, resp.ContentLength); err != nil {
+		return fmt.Errorf("failed to push blob %s: %w", digest, err)
+	}
+	return nil
+}
+
+// headManifest returns the digest of the manifest referenced by a tag or digest.
+func (c *Client) headManifest(ctx context.Context, repository, reference string) (string, error) {
+	resp, err := c.do(ctx, request{
+		method:     http.MethodHead,
+		repository: repository,
+		url:        c.url("v2", repository, "manifests", reference),
+		header:     http.Header{"Accept": []string{acceptedManifestTypes}},
+	})
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+
+	if digest := resp.Header.Get(headerDockerContentDigest); digest != "" {
+		return digest, nil
+	}
+	// not all registries return the digest, fall back to the content
+	_, m, err := c.rawManifest(ctx, repository, reference)
+	return m.Digest, err
+}
+
+// putManifest pushes the given manifest with the reference, a tag or its digest.
+func (c *Client) putManifest(ctx context.Context, repository, reference, mediaType string, body []byte) error {
+	resp, err := c.do(ctx, request{
+		method:     http.MethodPut,
+		repository: repository,
+		url:        c.url("v2", repository, "manifests", reference),
+		header:     http.Header{"Content-Type": []string{mediaType}},
+		body:       body,
+	})
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// blobExists reports whether the repository has the blob with the given digest.
+func (c *Client) blobExists(ctx context.Context, repository, digest string) (bool, error) {
+	resp, err := c.do(ctx, request{
+		method:     http.MethodHead,
+		repository: repository,
+		url:        c.url("v2", repository, "blobs", digest),
+	})
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, resp.Body.Close()
+}
+
+// putBlob uploads the content of a blob with the given digest in a single request.
+func (c *Client) putBlob(ctx context.Context, repository, digest string, content io.Reader, size int64) error {
+	resp, err := c.do(ctx, request{
+		method:     http.MethodPost,
+		repository: repository,
+		url:        c.url("v2", repository, "blobs", "uploads/"),
+	})
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("invalid upload location: %w", err)
+	}
+	location = resp.Request.URL.ResolveReference(location)
+	query := location.Query()
+	query.Set("digest", digest)
+	location.RawQuery = query.Encode()
+
+	resp, err = c.do(ctx, request{
+		method:     http.MethodPut,
+		repository: repository,
+		url:        location,
+		header:     http.Header{"Content-Type": []string{"application/octet-stream"}},
+		stream:     content,
+		size:       size,
+	})
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryclient_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/registry-operator/registry-operator/internal/registryclient"
+	"github.com/registry-operator/registry-operator/internal/registryclient/registrytest"
+)
+
+func TestCopy(t *testing.T) {
+	sourceServer := registrytest.NewServer(t)
+	targetServer := registrytest.NewServer(t)
+	created := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	digest := registrytest.Push(t, sourceServer, "library/nginx", "1.27", created)
+
+	source, err := registryclient.New(sourceServer.URL, nil, nil)
+	require.NoError(t, err)
+	target, err := registryclient.New(targetServer.URL, nil, nil)
+	require.NoError(t, err)
+
+	src := registryclient.Image{Client: source, Repository: "library/nginx", Tag: "1.27"}
+	dst := registryclient.Image{Client: target, Repository: "mirror/library/nginx", Tag: "1.27"}
+
+	t.Run("should copy image", func(t *testing.T) {
+		actual, upToDate, err := registryclient.Copy(t.Context(), src, dst)
+		require.NoError(t, err)
+		assert.Equal(t, digest, actual)
+		assert.False(t, upToDate)
+
+		m, err := target.Manifest(t.Context(), "mirror/library/nginx", "1.27")
+		require.NoError(t, err)
+		assert.Equal(t, digest, m.Digest)
+		assert.True(t, created.Equal(m.Created), "expected %s, got %s", created, m.Created)
+	})
+
+	t.Run("should skip up to date image", func(t *testing.T) {
+		actual, upToDate, err := registryclient.Copy(t.Context(), src, dst)
+		require.NoError(t, err)
+		assert.Equal(t, digest, actual)
+		assert.True(t, upToDate)
+	})
+
+	t.Run("should update moved tag", func(t *testing.T) {
+		moved := registrytest.Push(t, sourceServer, "library/nginx", "1.27", created.Add(time.Hour))
+
+		actual, upToDate, err := registryclient.Copy(t.Context(), src, dst)
+		require.NoError(t, err)
+		assert.Equal(t, moved, actual)
+		assert.False(t, upToDate)
+	})
+
+	t.Run("should fail to copy missing image", func(t *testing.T) {
+		missing := src
+		missing.Tag = "missing"
+		_, _, err := registryclient.Copy(t.Context(), missing, dst)
+		assert.ErrorIs(t, err, registryclient.ErrNotFound)
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package registrytest runs an in-memory distribution registry for tests.
+package registrytest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/distribution/distribution/v3/configuration"
+	"github.com/distribution/distribution/v3/registry/handlers"
+	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory" // storage driver
+)
+
+const (
+	mediaTypeManifest = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeConfig   = "application/vnd.oci.image.config.v1+json"
+	mediaTypeLayer    = "application/vnd.oci.image.layer.v1.tar+gzip"
+)
+
+// NewServer starts a registry with in-memory storage and deletes enabled, stopped with the test.
+func NewServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	config := &configuration.Configuration{
+		Storage: configuration.Storage{
+			"inmemory": configuration.Parameters{},
+			"delete":   configuration.Parameters{"enabled": true},
+			"maintenance": configuration.Parameters{"uploadpurging": map[any]any{
+				"enabled": false,
+			}},
+		},
+	}
+
+	server := httptest.NewServer(handlers.NewApp(context.Background(), config))
+	t.Cleanup(server.Close)
+	return server
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int    `json:"size"`
+}
+
+// Push uploads an image created at the given time to the registry and tags it, returning its digest.
+// Images pushed with the same creation time share their digest.
+func Push(t *testing.T, server *httptest.Server, repository, tag string, created time.Time) string {
+	t.Helper()
+
+	config, err := json.Marshal(map[string]any{
+		"created":      created.UTC().Format(time.RFC3339),
+		"architecture": "amd64",
+		"os":           "linux",
+		"rootfs":       map[string]any{"type": "layers", "diff_ids": []string{}},
+	})
+	if err != nil {
+		t.Fatalf("failed to encode config: %v", err)
+	}
+	layer := []byte("layer")
+
+	manifest, err := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     mediaTypeManifest,
+		"config":        uploadBlob(t, server, repository, mediaTypeConfig, config),
+		"layers":        []descriptor{uploadBlob(t, server, repository, mediaTypeLayer, layer)},
+	})
+	if err != nil {
+		t.Fatalf("failed to encode manifest: %v", err)
+	}
+
+	u := server.URL + "/v2/" + repository + "/manifests/" + tag
+	resp := do(t, http.MethodPut, u, mediaTypeManifest, manifest, http.StatusCreated)
+	return resp.Header.Get("Docker-Content-Digest")
+}
+
+func uploadBlob(t *testing.T, server *httptest.Server, repository, mediaType string, content []byte) descriptor {
+	t.Helper()
+
+	sum := sha256.Sum256(content)
+	d := descriptor{MediaType: mediaType, Digest: "sha256:" + hex.EncodeToString(sum[:]), Size: len(content)}
+
+	resp := do(t, http.MethodPost, server.URL+"/v2/"+repository+"/blobs/uploads/", "", nil, http.StatusAccepted)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid upload location: %v", err)
+	}
+	query := location.Query()
+	query.Set("digest", d.Digest)
+	location.RawQuery = query.Encode()
+
+	do(t, http.MethodPut, server.URL+location.RequestURI(), "application/octet-stream", content, http.StatusCreated)
+	return d
+}
+
+func do(t *testing.T, method, u, contentType string, body []byte, status int) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), method, u, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send %s %s: %v", method, u, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+
+	if resp.StatusCode != status {
+		t.Fatalf("%s %s: expected status %d, got %s", method, u, status, resp.Status)
+	}
+	return resp
+}
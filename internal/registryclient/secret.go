@@ -0,0 +1,105 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryclient
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ErrNoCredentials is returned when a Secret does not hold credentials for the Registry.
+var ErrNoCredentials = errors.New("no credentials")
+
+// dockerHubHosts are the names Docker Hub is known by in docker config files.
+var dockerHubHosts = []string{"docker.io", "index.docker.io", "registry-1.docker.io"}
+
+// dockerConfig is the content of a kubernetes.io/dockerconfigjson Secret.
+type dockerConfig struct {
+	Auths map[string]struct {
+		Username string `json:"username,omitempty"`
+		Password string `json:"password,omitempty"`
+		Auth     string `json:"auth,omitempty"`
+	} `json:"auths"`
+}
+
+// CredentialsFromSecret returns the credentials held by a Secret of type kubernetes.io/basic-auth, or the
+// ones for the Registry served at the given base URL in a Secret of type kubernetes.io/dockerconfigjson.
+func CredentialsFromSecret(secret *corev1.Secret, baseURL string) (*Credentials, error) {
+	switch secret.Type {
+	case corev1.SecretTypeBasicAuth:
+		return &Credentials{
+			Username: string(secret.Data[corev1.BasicAuthUsernameKey]),
+			Password: string(secret.Data[corev1.BasicAuthPasswordKey]),
+		}, nil
+
+	case corev1.SecretTypeDockerConfigJson:
+		config := dockerConfig{}
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+			return nil, fmt.Errorf("invalid %s in Secret %s: %w", corev1.DockerConfigJsonKey, secret.Name, err)
+		}
+
+		host := hostOf(baseURL)
+		for key, auth := range config.Auths {
+			if !sameHost(hostOf(key), host) {
+				continue
+			}
+			if auth.Auth != "" {
+				decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+				if err != nil {
+					return nil, fmt.Errorf("invalid auth for %s in Secret %s: %w", key, secret.Name, err)
+				}
+				username, password, _ := strings.Cut(string(decoded), ":")
+				return &Credentials{Username: username, Password: password}, nil
+			}
+			return &Credentials{Username: auth.Username, Password: auth.Password}, nil
+		}
+		return nil, fmt.Errorf("%w for %s in Secret %s", ErrNoCredentials, host, secret.Name)
+	}
+
+	return nil, fmt.Errorf("%w: Secret %s has unsupported type %s", ErrNoCredentials, secret.Name, secret.Type)
+}
+
+// hostOf returns the host of an URL, or of a registry name without scheme, e.g. index.docker.io/v1/.
+func hostOf(s string) string {
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+	return u.Host
+}
+
+// SameRegistry reports whether both URLs or registry names, e.g. https://registry-1.docker.io and docker.io,
+// name the same registry.
+func SameRegistry(a, b string) bool {
+	return sameHost(hostOf(a), hostOf(b))
+}
+
+// sameHost reports whether both hosts name the same registry.
+func sameHost(a, b string) bool {
+	if a == b {
+		return true
+	}
+	return slices.Contains(dockerHubHosts, a) && slices.Contains(dockerHubHosts, b)
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryclient_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/registry-operator/registry-operator/internal/registryclient"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCredentialsFromSecret(t *testing.T) {
+	dockerConfig := []byte(`{"auths":{
+		"https://index.docker.io/v1/":{"auth":"aHViOnMzY3IzdA=="},
+		"ghcr.io":{"username":"gh","password":"token"}
+	}}`)
+
+	for _, tc := range []struct {
+		name     string
+		secret   *corev1.Secret
+		baseURL  string
+		expected *registryclient.Credentials
+		err      error
+	}{
+		{
+			name: "basic auth",
+			secret: &corev1.Secret{
+				Type: corev1.SecretTypeBasicAuth,
+				Data: map[string][]byte{
+					corev1.BasicAuthUsernameKey: []byte("user"),
+					corev1.BasicAuthPasswordKey: []byte("secret"),
+				},
+			},
+			baseURL:  "http://registry.default.svc:5000",
+			expected: &registryclient.Credentials{Username: "user", Password: "secret"},
+		},
+		{
+			name: "docker config with encoded auth",
+			secret: &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: dockerConfig},
+			},
+			baseURL:  "https://registry-1.docker.io",
+			expected: &registryclient.Credentials{Username: "hub", Password: "s3cr3t"},
+		},
+		{
+			name: "docker config with username and password",
+			secret: &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: dockerConfig},
+			},
+			baseURL:  "https://ghcr.io",
+			expected: &registryclient.Credentials{Username: "gh", Password: "token"},
+		},
+		{
+			name: "docker config without the registry",
+			secret: &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: dockerConfig},
+			},
+			baseURL: "https://quay.io",
+			err:     registryclient.ErrNoCredentials,
+		},
+		{
+			name:    "unsupported type",
+			secret:  &corev1.Secret{Type: corev1.SecretTypeOpaque},
+			baseURL: "https://quay.io",
+			err:     registryclient.ErrNoCredentials,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.secret.ObjectMeta = metav1.ObjectMeta{Name: "credentials"}
+			actual, err := registryclient.CredentialsFromSecret(tc.secret, tc.baseURL)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package registryuser generates the credentials of the RegistryUsers and the htpasswd file of their Registry.
+package registryuser
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// HtpasswdKey is the key of the generated Secret holding the htpasswd entry of the user.
+	HtpasswdKey = "htpasswd"
+
+	passwordLength = 32
+)
+
+// Username returns the name the given RegistryUser authenticates with.
+func Username(user *registryv1alpha1.RegistryUser) string {
+	if user.Spec.Username != "" {
+		return user.Spec.Username
+	}
+	return user.Name
+}
+
+// SecretName returns the name of the Secret holding the credentials of the given RegistryUser.
+func SecretName(user *registryv1alpha1.RegistryUser) string {
+	if user.Spec.SecretName != "" {
+		return user.Spec.SecretName
+	}
+	return naming.RegistryUserSecret(user.Name)
+}
+
+// IsExpired reports whether the given RegistryUser expired at the given time.
+func IsExpired(user *registryv1alpha1.RegistryUser, now time.Time) bool {
+	return user.Spec.ExpiresAt != nil && !now.Before(user.Spec.ExpiresAt.Time)
+}
+
+// GeneratePassword returns a random password.
+func GeneratePassword() string {
+	b := make([]byte, passwordLength*3/4)
+	_, _ = rand.Read(b) // never returns an error
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// HtpasswdEntry returns the htpasswd entry of the given credentials, hashed with bcrypt.
+func HtpasswdEntry(username, password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return username + ":" + string(hash), nil
+}
+
+// DockerConfig returns the content of a kubernetes.io/dockerconfigjson Secret authenticating
+// the given credentials to every endpoint of a Registry.
+func DockerConfig(endpoints []registryv1alpha1.Endpoint, username, password string) ([]byte, error) {
+	type auth struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	}
+
+	auths := map[string]auth{}
+	for _, endpoint := range endpoints {
+		auths[endpoint.Address] = auth{
+			Username: username,
+			Password: password,
+			Auth:     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+		}
+	}
+	return json.Marshal(map[string]any{"auths": auths})
+}
+
+// AuthenticationEnabled reports whether the given Registry requires its clients to authenticate.
+func AuthenticationEnabled(registry *registryv1alpha1.Registry) bool {
+	return registry.Spec.Authentication != nil && registry.Spec.Authentication.Enabled
+}
+
+// Htpasswd returns the content of the htpasswd file authenticating the RegistryUsers of the given Registry,
+// which is empty when the Registry has no user or does not enable authentication.
+func Htpasswd(ctx context.Context, cli client.Client, registry *registryv1alpha1.Registry) (string, error) {
+	if !AuthenticationEnabled(registry) {
+		return "", nil
+	}
+
+	list := &registryv1alpha1.RegistryUserList{}
+	if err := cli.List(ctx, list, client.InNamespace(registry.Namespace)); err != nil {
+		return "", fmt.Errorf("failed to list registry users: %w", err)
+	}
+
+	now := time.Now()
+	entries := []string{}
+	for i := range list.Items {
+		user := &list.Items[i]
+		if user.Spec.RegistryRef.Name != registry.Name || user.Status.SecretName == "" ||
+			user.GetDeletionTimestamp() != nil || IsExpired(user, now) {
+			continue
+		}
+
+		secret := &corev1.Secret{}
+		key := client.ObjectKey{Namespace: user.Namespace, Name: user.Status.SecretName}
+		if err := cli.Get(ctx, key, secret); apierrors.IsNotFound(err) {
+			// being rotated or expired
+			continue
+		} else if err != nil {
+			return "", fmt.Errorf("failed to get credentials of %s: %w", user.Name, err)
+		}
+
+		entry := strings.TrimSpace(string(secret.Data[HtpasswdKey]))
+		if strings.HasPrefix(entry, Username(user)+":") {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) == 0 {
+		return "", nil
+	}
+	slices.Sort(entries)
+	return strings.Join(entries, "\n") + "\n", nil
+}
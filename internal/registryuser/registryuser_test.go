@@ -0,0 +1,135 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryuser
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/registryclient"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHtpasswdEntry(t *testing.T) {
+	password := GeneratePassword()
+	assert.Len(t, password, passwordLength)
+	assert.NotEqual(t, password, GeneratePassword())
+
+	entry, err := HtpasswdEntry("ci", password)
+	require.NoError(t, err)
+
+	username, hash, ok := strings.Cut(entry, ":")
+	require.True(t, ok)
+	assert.Equal(t, "ci", username)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)))
+}
+
+func TestDockerConfig(t *testing.T) {
+	endpoints := []registryv1alpha1.Endpoint{
+		{Type: registryv1alpha1.EndpointTypeClusterIP, Address: "my-registry.default.svc:5000"},
+		{Type: registryv1alpha1.EndpointTypeIngress, Address: "registry.example.com", TLS: true},
+	}
+	config, err := DockerConfig(endpoints, "ci", "s3cr3t")
+	require.NoError(t, err)
+
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: config},
+	}
+	for _, baseURL := range []string{"http://my-registry.default.svc:5000", "https://registry.example.com"} {
+		credentials, err := registryclient.CredentialsFromSecret(secret, baseURL)
+		require.NoError(t, err)
+		assert.Equal(t, &registryclient.Credentials{Username: "ci", Password: "s3cr3t"}, credentials)
+	}
+}
+
+func TestHtpasswd(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(registryv1alpha1.AddToScheme(scheme))
+
+	registry := &registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-registry", Namespace: "default"},
+		Spec: registryv1alpha1.RegistrySpec{
+			Authentication: &registryv1alpha1.Authentication{Enabled: true},
+		},
+	}
+	user := func(name, registry string, expiresAt *metav1.Time) *registryv1alpha1.RegistryUser {
+		return &registryv1alpha1.RegistryUser{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: registryv1alpha1.RegistryUserSpec{
+				RegistryRef: corev1.LocalObjectReference{Name: registry},
+				ExpiresAt:   expiresAt,
+			},
+			Status: registryv1alpha1.RegistryUserStatus{SecretName: name + "-credentials"},
+		}
+	}
+	secret := func(name, entry string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-credentials", Namespace: "default"},
+			Data:       map[string][]byte{HtpasswdKey: []byte(entry)},
+		}
+	}
+	past := &metav1.Time{Time: time.Now().Add(-time.Hour)}
+
+	t.Run("should be empty without users", func(t *testing.T) {
+		cli := fake.NewClientBuilder().WithScheme(scheme).Build()
+		actual, err := Htpasswd(t.Context(), cli, registry)
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+
+	t.Run("should list the valid users of the registry", func(t *testing.T) {
+		cli := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(
+				user("ci", "my-registry", nil), secret("ci", "ci:$2a$10$ci"),
+				user("bob", "my-registry", nil), secret("bob", "bob:$2a$10$bob"),
+				user("expired", "my-registry", past), secret("expired", "expired:$2a$10$expired"),
+				user("other", "other-registry", nil), secret("other", "other:$2a$10$other"),
+				user("pending", "my-registry", nil),
+			).
+			Build()
+
+		actual, err := Htpasswd(t.Context(), cli, registry)
+		require.NoError(t, err)
+		assert.Equal(t, "bob:$2a$10$bob\nci:$2a$10$ci\n", actual)
+	})
+
+	t.Run("should be empty without authentication", func(t *testing.T) {
+		cli := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(user("ci", "my-registry", nil), secret("ci", "ci:$2a$10$ci")).
+			Build()
+
+		anonymous := registry.DeepCopy()
+		anonymous.Spec.Authentication = nil
+		actual, err := Htpasswd(t.Context(), cli, anonymous)
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+}
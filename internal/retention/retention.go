@@ -0,0 +1,118 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package retention decides which manifests of a repository are deleted by a retention policy.
+package retention
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Tag is a tag of a repository along with the manifest it references.
+type Tag struct {
+	Name    string
+	Digest  string
+	Created time.Time
+}
+
+// ExpiredTags returns the digests of the manifests to delete so that the tags follow the policy.
+// A manifest referenced by a tag to keep is never deleted, as deleting it would delete that tag too.
+func ExpiredTags(policy registryv1alpha1.RetentionPolicy, tags []Tag) ([]string, error) {
+	if policy.KeepLast == nil {
+		return nil, nil
+	}
+
+	patterns := make([]*regexp.Regexp, 0, len(policy.KeepMatching))
+	for _, expr := range policy.KeepMatching {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keepMatching expression %q: %w", expr, err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	// most recent first, tags without creation time being the oldest
+	sorted := slices.Clone(tags)
+	slices.SortStableFunc(sorted, func(a, b Tag) int {
+		if c := b.Created.Compare(a.Created); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Name, a.Name)
+	})
+
+	kept := sets.New[string]()
+	candidates := sets.New[string]()
+	for i, tag := range sorted {
+		matches := slices.ContainsFunc(patterns, func(re *regexp.Regexp) bool {
+			return re.MatchString(tag.Name)
+		})
+		if i < int(*policy.KeepLast) || matches {
+			kept.Insert(tag.Digest)
+		} else {
+			candidates.Insert(tag.Digest)
+		}
+	}
+
+	return sets.List(candidates.Difference(kept)), nil
+}
+
+// TrackUntagged returns the manifests without tag, given the manifests which were tagged at the previous
+// cleanup and the ones tagged now. The manifests deleted meanwhile are not tracked.
+func TrackUntagged(
+	untagged []registryv1alpha1.UntaggedManifest,
+	previous []string,
+	tagged sets.Set[string],
+	deleted sets.Set[string],
+	now time.Time,
+) []registryv1alpha1.UntaggedManifest {
+	tracked := sets.New[string]()
+	result := []registryv1alpha1.UntaggedManifest{}
+	for _, m := range untagged {
+		if tagged.Has(m.Digest) || deleted.Has(m.Digest) {
+			continue
+		}
+		tracked.Insert(m.Digest)
+		result = append(result, m)
+	}
+
+	for _, digest := range previous {
+		if tagged.Has(digest) || deleted.Has(digest) || tracked.Has(digest) {
+			continue
+		}
+		tracked.Insert(digest)
+		result = append(result, registryv1alpha1.UntaggedManifest{Digest: digest, Since: metav1.NewTime(now)})
+	}
+
+	return result
+}
+
+// ExpiredUntagged returns the digests of the manifests which have no tag for longer than the given duration.
+func ExpiredUntagged(untagged []registryv1alpha1.UntaggedManifest, olderThan time.Duration, now time.Time) []string {
+	var expired []string
+	for _, m := range untagged {
+		if now.Sub(m.Since.Time) >= olderThan {
+			expired = append(expired, m.Digest)
+		}
+	}
+	return expired
+}
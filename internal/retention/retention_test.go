@@ -0,0 +1,106 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/retention"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+)
+
+func TestExpiredTags(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tags := []retention.Tag{
+		{Name: "v1.0.0", Digest: "sha256:a", Created: now.Add(-72 * time.Hour)},
+		{Name: "build-1", Digest: "sha256:b", Created: now.Add(-48 * time.Hour)},
+		{Name: "build-2", Digest: "sha256:c", Created: now.Add(-24 * time.Hour)},
+		{Name: "build-3", Digest: "sha256:d", Created: now},
+		{Name: "latest", Digest: "sha256:d", Created: now},
+		{Name: "stable", Digest: "sha256:b", Created: now.Add(-48 * time.Hour)},
+		{Name: "artifact", Digest: "sha256:e"},
+	}
+
+	tests := map[string]struct {
+		policy   registryv1alpha1.RetentionPolicy
+		expected []string
+	}{
+		"no keepLast": {
+			policy: registryv1alpha1.RetentionPolicy{KeepMatching: []string{"^v"}},
+		},
+		"keepLast": {
+			policy:   registryv1alpha1.RetentionPolicy{KeepLast: ptr.To[int32](3)},
+			expected: []string{"sha256:a", "sha256:b", "sha256:e"},
+		},
+		"keepLast and keepMatching": {
+			policy: registryv1alpha1.RetentionPolicy{
+				KeepLast:     ptr.To[int32](2),
+				KeepMatching: []string{`^v\d+\.\d+\.\d+$`, "^stable$"},
+			},
+			expected: []string{"sha256:c", "sha256:e"},
+		},
+		"keep nothing": {
+			policy:   registryv1alpha1.RetentionPolicy{KeepLast: ptr.To[int32](0)},
+			expected: []string{"sha256:a", "sha256:b", "sha256:c", "sha256:d", "sha256:e"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := retention.ExpiredTags(tc.policy, tags)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.expected, actual)
+		})
+	}
+
+	t.Run("should fail on invalid expression", func(t *testing.T) {
+		policy := registryv1alpha1.RetentionPolicy{KeepLast: ptr.To[int32](1), KeepMatching: []string{"("}}
+		_, err := retention.ExpiredTags(policy, tags)
+		assert.Error(t, err)
+	})
+}
+
+func TestUntagged(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	since := metav1.NewTime(now.Add(-2 * time.Hour))
+	untagged := []registryv1alpha1.UntaggedManifest{
+		{Digest: "sha256:a", Since: since},
+		{Digest: "sha256:b", Since: since},
+		{Digest: "sha256:c", Since: since},
+	}
+
+	tracked := retention.TrackUntagged(
+		untagged,
+		[]string{"sha256:a", "sha256:d", "sha256:e", "sha256:f"},
+		sets.New("sha256:b", "sha256:e"),
+		sets.New("sha256:c", "sha256:f"),
+		now,
+	)
+	assert.Equal(t, []registryv1alpha1.UntaggedManifest{
+		{Digest: "sha256:a", Since: since},
+		{Digest: "sha256:d", Since: metav1.NewTime(now)},
+	}, tracked)
+
+	assert.Equal(t, []string{"sha256:a"}, retention.ExpiredUntagged(tracked, time.Hour, now))
+	assert.Empty(t, retention.ExpiredUntagged(tracked, 3*time.Hour, now))
+}
@@ -0,0 +1,416 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package s3client implements the parts of the S3 API used by the operator to back up and purge the data
+// of the Registries.
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// emptyPayloadHash is the SHA-256 of the empty body of the requests.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	// unsignedPayload replaces the hash of the uploaded objects, streamed without being read twice.
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	amzDateFormat    = "20060102T150405Z"
+	maxErrorSize     = 64 << 10
+
+	defaultResponseTimeout = 30 * time.Second
+
+	defaultPartSize = 64 << 20
+	maxParts        = 10000
+)
+
+var errTooManyParts = fmt.Errorf("the object exceeds the %d parts of a multipart upload", maxParts)
+
+// Config locates a bucket and holds the static credentials to access it.
+type Config struct {
+	// Bucket is the name of the bucket.
+	Bucket string
+	// Region is the region of the bucket, e.g. us-east-1.
+	Region string
+	// Endpoint overrides the URL of the S3-compatible service, defaulting to the AWS endpoint of the region.
+	Endpoint string
+	// AccessKey and SecretKey are the static credentials signing the requests.
+	AccessKey string
+	SecretKey string
+	// PartSize is the size of the parts of the multipart uploads, held in memory while being sent. It defaults
+	// to 64 MiB, the 10,000 parts of an upload making objects of up to 625 GiB.
+	PartSize int
+}
+
+// Client talks to the S3 API, addressing the bucket in the path of the requests.
+type Client struct {
+	config     Config
+	endpoint   *url.URL
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// EndpointURL returns the URL of the given endpoint, configured like the regionendpoint of the S3 storage driver:
+// an endpoint without scheme, e.g. minio.example:9000, is reached over https.
+func EndpointURL(endpoint string) string {
+	if endpoint == "" || strings.Contains(endpoint, "://") {
+		return endpoint
+	}
+	return "https://" + endpoint
+}
+
+// New returns a Client for the given bucket. A nil httpClient defaults to a client waiting a limited time for
+// the responses, without limiting the transfer of the objects.
+func New(config Config, httpClient *http.Client) (*Client, error) {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	if config.PartSize <= 0 {
+		config.PartSize = defaultPartSize
+	}
+	if httpClient == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.ResponseHeaderTimeout = defaultResponseTimeout
+		httpClient = &http.Client{Transport: transport}
+	}
+	return &Client{
+		config:     config,
+		endpoint:   u,
+		httpClient: httpClient,
+		now:        time.Now,
+	}, nil
+}
+
+type listBucketResult struct {
+	Contents              []Object `xml:"Contents"`
+	IsTruncated           bool     `xml:"IsTruncated"`
+	NextContinuationToken string   `xml:"NextContinuationToken"`
+}
+
+// Object describes an object of the bucket.
+type Object struct {
+	Key  string `xml:"Key"`
+	Size int64  `xml:"Size"`
+}
+
+// List returns the keys of the objects of the bucket starting with the given prefix, in lexicographical order.
+func (c *Client) List(ctx context.Context, prefix string) ([]string, error) {
+	objects, err := c.ListObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	return keys, nil
+}
+
+// ListObjects returns the objects of the bucket starting with the given prefix, in lexicographical order.
+func (c *Client) ListObjects(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := c.do(ctx, http.MethodGet, "", query, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		result := listBucketResult{}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode objects: %w", err)
+		}
+
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Get returns the content of the given object, to be closed by the caller.
+func (c *Client) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	return resp.Body, nil
+}
+
+// Put uploads the given content of the given size as the given object.
+func (c *Client) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	resp, err := c.do(ctx, http.MethodPut, key, nil, body, size)
+	if err != nil {
+		return fmt.Errorf("failed to put object %s: %w", key, err)
+	}
+	return resp.Body.Close()
+}
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// Upload uploads the content read from the given reader as the given object with a multipart upload, reading
+// a part at a time. The content is neither staged nor limited to the 5 GiB of a single request. A failed
+// upload is aborted, so that the bucket drops its parts.
+func (c *Client) Upload(ctx context.Context, key string, body io.Reader) error {
+	resp, err := c.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, 0)
+	if err != nil {
+		return fmt.Errorf("failed to upload object %s: %w", key, err)
+	}
+	result := initiateMultipartUploadResult{}
+	err = xml.NewDecoder(resp.Body).Decode(&result)
+	_ = resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to decode the upload of object %s: %w", key, err)
+	}
+
+	if err := c.uploadParts(ctx, key, result.UploadID, body); err != nil {
+		query := url.Values{"uploadId": {result.UploadID}}
+		if resp, abortErr := c.do(context.WithoutCancel(ctx), http.MethodDelete, key, query, nil, 0); abortErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to abort the upload: %w", abortErr))
+		} else {
+			_ = resp.Body.Close()
+		}
+		return fmt.Errorf("failed to upload object %s: %w", key, err)
+	}
+	return nil
+}
+
+// uploadParts uploads the content read from the given reader as the parts of the given multipart upload, and
+// completes it.
+func (c *Client) uploadParts(ctx context.Context, key, uploadID string, body io.Reader) error {
+	complete := completeMultipartUpload{}
+	buf := make([]byte, c.config.PartSize)
+	for number := 1; ; number++ {
+		n, readErr := io.ReadFull(body, buf)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return readErr
+		}
+		// an empty object is made of a single empty part
+		if n > 0 || number == 1 {
+			if number > maxParts {
+				return errTooManyParts
+			}
+			query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+			resp, err := c.do(ctx, http.MethodPut, key, query, bytes.NewReader(buf[:n]), int64(n))
+			if err != nil {
+				return fmt.Errorf("failed to upload part %d: %w", number, err)
+			}
+			_ = resp.Body.Close()
+			complete.Parts = append(complete.Parts, completedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+		}
+		if readErr != nil {
+			break
+		}
+	}
+
+	payload, err := xml.Marshal(complete)
+	if err != nil {
+		return err
+	}
+	query := url.Values{"uploadId": {uploadID}}
+	resp, err := c.do(ctx, http.MethodPost, key, query, bytes.NewReader(payload), int64(len(payload)))
+	if err != nil {
+		return fmt.Errorf("failed to complete the upload: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+
+	// the completion may fail after the response status was sent, the error being its body
+	message, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorSize))
+	if err != nil {
+		return fmt.Errorf("failed to complete the upload: %w", err)
+	}
+	if bytes.Contains(message, []byte("<Error>")) {
+		return fmt.Errorf("failed to complete the upload: %s", strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// Delete deletes the given object.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	resp, err := c.do(ctx, http.MethodDelete, key, nil, nil, 0)
+	if err != nil {
+		return fmt.Errorf("failed to delete object %s: %w", key, err)
+	}
+	return resp.Body.Close()
+}
+
+// DeletePrefix deletes every object of the bucket whose key starts with the given prefix,
+// and returns the number of deleted objects.
+func (c *Client) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	keys, err := c.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	for i, key := range keys {
+		if err := c.Delete(ctx, key); err != nil {
+			return i, err
+		}
+	}
+	return len(keys), nil
+}
+
+// do sends a signed request for the given object key of the bucket, or for the bucket itself when empty,
+// and returns the successful response, whose body must be closed.
+func (c *Client) do(
+	ctx context.Context,
+	method, key string,
+	query url.Values,
+	body io.Reader,
+	size int64,
+) (*http.Response, error) {
+	u := c.endpoint.JoinPath(c.config.Bucket, key)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		req.ContentLength = size
+		payloadHash = unsignedPayload
+	}
+	sign(req, c.config, payloadHash, c.now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close() //nolint:errcheck // read-only body
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorSize))
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+// sign signs the given request with the AWS Signature Version 4, covering its host, its headers and
+// the given hash of its payload.
+func sign(req *http.Request, config Config, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := slices.Sorted(maps.Keys(headers))
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, headers[name])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		escape(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, config.Region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{signingAlgorithm, amzDate, scope, hashHex(canonicalRequest)}, "\n")
+
+	key := []byte("AWS4" + config.SecretKey)
+	for _, part := range []string{date, config.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, config.AccessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery returns the query parameters sorted and escaped as signed by the AWS Signature Version 4.
+func canonicalQuery(query url.Values) string {
+	params := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, escape(name, true)+"="+escape(value, true))
+		}
+	}
+	slices.Sort(params)
+	return strings.Join(params, "&")
+}
+
+// escape percent-encodes every byte of s but the unreserved characters, and the slashes unless escapeSlash.
+func escape(s string, escapeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !escapeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hashHex(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
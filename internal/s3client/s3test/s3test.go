@@ -0,0 +1,170 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package s3test serves an in-memory S3 bucket for tests.
+package s3test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/registry-operator/registry-operator/internal/s3client"
+)
+
+// Server serves a single bucket from memory.
+type Server struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	// uploads holds the parts of the multipart uploads in progress, by upload ID.
+	uploads map[string]map[int][]byte
+	nextID  int
+}
+
+// NewServer starts a server holding the given objects, stopped with the test.
+func NewServer(t *testing.T, objects map[string][]byte) *Server {
+	t.Helper()
+
+	s := &Server{objects: maps.Clone(objects), uploads: map[string]map[int][]byte{}}
+	if s.objects == nil {
+		s.objects = map[string][]byte{}
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Config returns the configuration of a client of the bucket.
+func (s *Server) Config() s3client.Config {
+	return s3client.Config{
+		Bucket:    "bucket",
+		Region:    "us-east-1",
+		Endpoint:  s.URL,
+		AccessKey: "access",
+		SecretKey: "secret",
+	}
+}
+
+// Objects returns a copy of the objects of the bucket.
+func (s *Server) Objects() map[string][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.objects)
+}
+
+// Keys returns the sorted keys of the objects of the bucket.
+func (s *Server) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Sorted(maps.Keys(s.objects))
+}
+
+// Uploads returns the number of multipart uploads neither completed nor aborted.
+func (s *Server) Uploads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.uploads)
+}
+
+// Put stores an object in the bucket.
+func (s *Server) Put(key string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = content
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, _ := strings.CutPrefix(r.URL.Path, "/bucket/")
+	query := r.URL.Query()
+	uploadID := query.Get("uploadId")
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.nextID++
+		uploadID = strconv.Itoa(s.nextID)
+		s.uploads[uploadID] = map[int][]byte{}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", uploadID)
+	case uploadID != "" && s.uploads[uploadID] == nil:
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodPut && uploadID != "":
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		s.uploads[uploadID][number], _ = io.ReadAll(r.Body)
+		w.Header().Set("ETag", fmt.Sprintf("%q", "part-"+strconv.Itoa(number)))
+	case r.Method == http.MethodPost && uploadID != "":
+		complete := struct {
+			Parts []struct {
+				PartNumber int
+				ETag       string
+			} `xml:"Part"`
+		}{}
+		if err := xml.NewDecoder(r.Body).Decode(&complete); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var content []byte
+		for _, part := range complete.Parts {
+			content = append(content, s.uploads[uploadID][part.PartNumber]...)
+		}
+		s.objects[key] = content
+		delete(s.uploads, uploadID)
+		fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodDelete && uploadID != "":
+		delete(s.uploads, uploadID)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Path == "/bucket":
+		fmt.Fprint(w, "<ListBucketResult>")
+		for _, name := range slices.Sorted(maps.Keys(s.objects)) {
+			if strings.HasPrefix(name, r.URL.Query().Get("prefix")) {
+				fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size></Contents>", name, len(s.objects[name]))
+			}
+		}
+		fmt.Fprint(w, "</ListBucketResult>")
+	case r.Method == http.MethodGet:
+		content, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	case r.Method == http.MethodPut:
+		content, _ := io.ReadAll(r.Body)
+		s.objects[key] = content
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// WriteFile writes a file of a filesystem storage, creating its directories.
+func WriteFile(t *testing.T, name, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+	require.NoError(t, os.WriteFile(name, []byte(content), 0o644))
+}
@@ -0,0 +1,329 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// HTTPRouteGVK is the kind of the Gateway API HTTPRoutes publishing the Registry Service.
+	HTTPRouteGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+	// GatewayGVK is the kind of the Gateway API Gateways the HTTPRoutes are attached to.
+	GatewayGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "Gateway"}
+)
+
+// updateEndpoints publishes the addresses the Registry Service can be reached at.
+func updateEndpoints(ctx context.Context, cli client.Client, changed *registryv1alpha1.Registry) error {
+	svc := &corev1.Service{}
+	objKey := client.ObjectKey{
+		Namespace: changed.GetNamespace(),
+		Name:      naming.Service(changed.Name),
+	}
+	if err := cli.Get(ctx, objKey, svc); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get service: %w", err)
+		}
+		changed.Status.Endpoints = nil
+		changed.Status.PullPrefix = ""
+		return nil
+	}
+
+	port := ""
+	for _, p := range svc.Spec.Ports {
+		if p.Name == naming.RegistryDistributionPort() {
+			port = strconv.Itoa(int(p.Port))
+			break
+		}
+	}
+	if port == "" {
+		return fmt.Errorf("service %s has no %s port", svc.Name, naming.RegistryDistributionPort())
+	}
+
+	endpoints := []registryv1alpha1.Endpoint{
+		{
+			Type:    registryv1alpha1.EndpointTypeClusterIP,
+			Address: net.JoinHostPort(fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace), port),
+		},
+	}
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if host := loadBalancerHost(ingress); host != "" {
+			endpoints = append(endpoints, registryv1alpha1.Endpoint{
+				Type:    registryv1alpha1.EndpointTypeLoadBalancer,
+				Address: net.JoinHostPort(host, port),
+			})
+		}
+	}
+
+	ingressEndpoints, err := ingressEndpoints(ctx, cli, svc)
+	if err != nil {
+		return err
+	}
+	endpoints = append(endpoints, ingressEndpoints...)
+
+	gatewayEndpoints, err := gatewayEndpoints(ctx, cli, svc)
+	if err != nil {
+		return err
+	}
+	endpoints = append(endpoints, gatewayEndpoints...)
+
+	changed.Status.Endpoints = endpoints
+	changed.Status.PullPrefix = endpoints[0].Address
+	for _, endpoint := range endpoints {
+		if endpoint.Type != registryv1alpha1.EndpointTypeClusterIP {
+			changed.Status.PullPrefix = endpoint.Address
+			break
+		}
+	}
+
+	return nil
+}
+
+// ingressEndpoints returns the hosts of all Ingresses routing to the given Service.
+func ingressEndpoints(
+	ctx context.Context,
+	cli client.Client,
+	svc *corev1.Service,
+) ([]registryv1alpha1.Endpoint, error) {
+	list := &networkingv1.IngressList{}
+	if err := cli.List(ctx, list, client.InNamespace(svc.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+
+	endpoints := []registryv1alpha1.Endpoint{}
+	for _, ing := range list.Items {
+		hosts := []string{}
+		if isIngressBackend(ing.Spec.DefaultBackend, svc.Name) {
+			hosts = append(hosts, "")
+		}
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				if isIngressBackend(&path.Backend, svc.Name) {
+					hosts = append(hosts, rule.Host)
+					break
+				}
+			}
+		}
+
+		for _, host := range hosts {
+			tls := false
+			for _, t := range ing.Spec.TLS {
+				if slices.ContainsFunc(t.Hosts, func(pattern string) bool { return hostMatches(pattern, host) }) {
+					tls = true
+					break
+				}
+			}
+
+			// Rules without a host are served on the address of the Ingress itself.
+			addresses := []string{host}
+			if host == "" {
+				addresses = []string{}
+				for _, lb := range ing.Status.LoadBalancer.Ingress {
+					if h := ingressLoadBalancerHost(lb); h != "" {
+						addresses = append(addresses, h)
+					}
+				}
+			}
+
+			for _, address := range addresses {
+				endpoint := registryv1alpha1.Endpoint{
+					Type:    registryv1alpha1.EndpointTypeIngress,
+					Address: address,
+					TLS:     tls,
+				}
+				if !slices.Contains(endpoints, endpoint) {
+					endpoints = append(endpoints, endpoint)
+				}
+			}
+		}
+	}
+
+	return endpoints, nil
+}
+
+// gatewayEndpoints returns the hostnames of all HTTPRoutes routing to the given Service.
+// Gateway API is optional, so nothing is returned when its CRDs are not installed.
+func gatewayEndpoints(
+	ctx context.Context,
+	cli client.Client,
+	svc *corev1.Service,
+) ([]registryv1alpha1.Endpoint, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(HTTPRouteGVK.GroupVersion().WithKind(HTTPRouteGVK.Kind + "List"))
+	if err := cli.List(ctx, list, client.InNamespace(svc.Namespace)); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list httproutes: %w", err)
+	}
+
+	endpoints := []registryv1alpha1.Endpoint{}
+	for _, route := range list.Items {
+		if !isHTTPRouteBackend(route, svc) {
+			continue
+		}
+
+		hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+		parents, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+
+		for _, hostname := range hostnames {
+			tls := false
+			for _, parent := range parents {
+				ref, ok := parent.(map[string]any)
+				if !ok {
+					continue
+				}
+				https, err := isHTTPSListener(ctx, cli, route.GetNamespace(), ref, hostname)
+				if err != nil {
+					return nil, err
+				}
+				if https {
+					tls = true
+					break
+				}
+			}
+
+			endpoint := registryv1alpha1.Endpoint{
+				Type:    registryv1alpha1.EndpointTypeGateway,
+				Address: hostname,
+				TLS:     tls,
+			}
+			if !slices.Contains(endpoints, endpoint) {
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+	}
+
+	return endpoints, nil
+}
+
+// isHTTPRouteBackend reports whether any rule of the HTTPRoute forwards to the given Service.
+func isHTTPRouteBackend(route unstructured.Unstructured, svc *corev1.Service) bool {
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	for _, rule := range rules {
+		r, ok := rule.(map[string]any)
+		if !ok {
+			continue
+		}
+		refs, _, _ := unstructured.NestedSlice(r, "backendRefs")
+		for _, ref := range refs {
+			b, ok := ref.(map[string]any)
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(b, "name")
+			kind, _, _ := unstructured.NestedString(b, "kind")
+			namespace, _, _ := unstructured.NestedString(b, "namespace")
+			if name == svc.Name &&
+				(kind == "" || kind == "Service") &&
+				(namespace == "" || namespace == svc.Namespace) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isHTTPSListener reports whether the parent Gateway terminates TLS for the given hostname.
+func isHTTPSListener(
+	ctx context.Context,
+	cli client.Client,
+	namespace string,
+	ref map[string]any,
+	hostname string,
+) (bool, error) {
+	name, _, _ := unstructured.NestedString(ref, "name")
+	kind, _, _ := unstructured.NestedString(ref, "kind")
+	if ns, ok, _ := unstructured.NestedString(ref, "namespace"); ok && ns != "" {
+		namespace = ns
+	}
+	if kind != "" && kind != GatewayGVK.Kind {
+		return false, nil
+	}
+
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(GatewayGVK)
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, gateway); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get gateway: %w", err)
+	}
+
+	sectionName, _, _ := unstructured.NestedString(ref, "sectionName")
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+	for _, listener := range listeners {
+		l, ok := listener.(map[string]any)
+		if !ok {
+			continue
+		}
+		lName, _, _ := unstructured.NestedString(l, "name")
+		protocol, _, _ := unstructured.NestedString(l, "protocol")
+		lHostname, _, _ := unstructured.NestedString(l, "hostname")
+		if sectionName != "" && sectionName != lName {
+			continue
+		}
+		if protocol == "HTTPS" && (lHostname == "" || hostMatches(lHostname, hostname)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isIngressBackend(backend *networkingv1.IngressBackend, service string) bool {
+	return backend != nil && backend.Service != nil && backend.Service.Name == service
+}
+
+// hostMatches reports whether host matches pattern, which may contain a leading wildcard label.
+func hostMatches(pattern, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		prefix, found := strings.CutSuffix(host, suffix)
+		return found && prefix != "" && !strings.Contains(prefix, ".")
+	}
+	return pattern == host
+}
+
+func loadBalancerHost(ingress corev1.LoadBalancerIngress) string {
+	if ingress.Hostname != "" {
+		return ingress.Hostname
+	}
+	return ingress.IP
+}
+
+func ingressLoadBalancerHost(ingress networkingv1.IngressLoadBalancerIngress) string {
+	if ingress.Hostname != "" {
+		return ingress.Hostname
+	}
+	return ingress.IP
+}
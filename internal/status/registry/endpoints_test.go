@@ -0,0 +1,166 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateEndpoints(t *testing.T) {
+	registry := &registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-instance",
+			Namespace: "my-namespace",
+		},
+	}
+	service := func(lb ...corev1.LoadBalancerIngress) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-instance-registry", Namespace: "my-namespace"},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "distribution", Port: 5000}},
+			},
+			Status: corev1.ServiceStatus{
+				LoadBalancer: corev1.LoadBalancerStatus{Ingress: lb},
+			},
+		}
+	}
+	backend := networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{
+			Name: "my-instance-registry",
+			Port: networkingv1.ServiceBackendPort{Name: "distribution"},
+		},
+	}
+
+	t.Run("should publish nothing without service", func(t *testing.T) {
+		cli := fake.NewClientBuilder().Build()
+		changed := registry.DeepCopy()
+
+		require.NoError(t, updateEndpoints(t.Context(), cli, changed))
+		assert.Empty(t, changed.Status.Endpoints)
+		assert.Empty(t, changed.Status.PullPrefix)
+	})
+
+	t.Run("should publish in-cluster address", func(t *testing.T) {
+		cli := fake.NewClientBuilder().WithObjects(service()).Build()
+		changed := registry.DeepCopy()
+
+		require.NoError(t, updateEndpoints(t.Context(), cli, changed))
+		assert.Equal(t, []registryv1alpha1.Endpoint{
+			{Type: registryv1alpha1.EndpointTypeClusterIP, Address: "my-instance-registry.my-namespace.svc:5000"},
+		}, changed.Status.Endpoints)
+		assert.Equal(t, "my-instance-registry.my-namespace.svc:5000", changed.Status.PullPrefix)
+	})
+
+	t.Run("should publish load balancer and ingress addresses", func(t *testing.T) {
+		ingress := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "my-namespace"},
+			Spec: networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{{Hosts: []string{"*.example.com"}}},
+				Rules: []networkingv1.IngressRule{
+					{
+						Host: "registry.example.com",
+						IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{{Path: "/", Backend: backend}},
+							},
+						},
+					},
+				},
+			},
+		}
+		unrelated := ingress.DeepCopy()
+		unrelated.Name = "unrelated"
+		unrelated.Spec.Rules[0].Host = "other.example.com"
+		unrelated.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = "other"
+
+		cli := fake.NewClientBuilder().
+			WithObjects(service(corev1.LoadBalancerIngress{IP: "192.168.1.10"}), ingress, unrelated).
+			Build()
+		changed := registry.DeepCopy()
+
+		require.NoError(t, updateEndpoints(t.Context(), cli, changed))
+		assert.Equal(t, []registryv1alpha1.Endpoint{
+			{Type: registryv1alpha1.EndpointTypeClusterIP, Address: "my-instance-registry.my-namespace.svc:5000"},
+			{Type: registryv1alpha1.EndpointTypeLoadBalancer, Address: "192.168.1.10:5000"},
+			{Type: registryv1alpha1.EndpointTypeIngress, Address: "registry.example.com", TLS: true},
+		}, changed.Status.Endpoints)
+		assert.Equal(t, "192.168.1.10:5000", changed.Status.PullPrefix)
+	})
+
+	t.Run("should publish httproute hostnames", func(t *testing.T) {
+		route := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "gateway.networking.k8s.io/v1",
+			"kind":       "HTTPRoute",
+			"metadata":   map[string]any{"name": "registry", "namespace": "my-namespace"},
+			"spec": map[string]any{
+				"hostnames":  []any{"registry.example.com"},
+				"parentRefs": []any{map[string]any{"name": "gateway"}},
+				"rules": []any{map[string]any{
+					"backendRefs": []any{map[string]any{"name": "my-instance-registry", "port": int64(5000)}},
+				}},
+			},
+		}}
+		gateway := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "gateway.networking.k8s.io/v1",
+			"kind":       "Gateway",
+			"metadata":   map[string]any{"name": "gateway", "namespace": "my-namespace"},
+			"spec": map[string]any{
+				"listeners": []any{map[string]any{"name": "https", "protocol": "HTTPS"}},
+			},
+		}}
+
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(HTTPRouteGVK, meta.RESTScopeNamespace)
+		mapper.Add(GatewayGVK, meta.RESTScopeNamespace)
+		for gvk := range scheme.Scheme.AllKnownTypes() {
+			mapper.Add(gvk, meta.RESTScopeNamespace)
+		}
+
+		cli := fake.NewClientBuilder().
+			WithRESTMapper(mapper).
+			WithObjects(service(), route, gateway).
+			Build()
+		changed := registry.DeepCopy()
+
+		require.NoError(t, updateEndpoints(t.Context(), cli, changed))
+		require.Len(t, changed.Status.Endpoints, 2)
+		assert.Equal(t, registryv1alpha1.Endpoint{
+			Type:    registryv1alpha1.EndpointTypeGateway,
+			Address: "registry.example.com",
+			TLS:     true,
+		}, changed.Status.Endpoints[1])
+		assert.Equal(t, "registry.example.com", changed.Status.PullPrefix)
+	})
+}
+
+func TestHostMatches(t *testing.T) {
+	assert.True(t, hostMatches("registry.example.com", "registry.example.com"))
+	assert.True(t, hostMatches("*.example.com", "registry.example.com"))
+	assert.False(t, hostMatches("*.example.com", "a.registry.example.com"))
+	assert.False(t, hostMatches("*.example.com", "example.com"))
+}
@@ -23,8 +23,6 @@ import (
 
 	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
 	"github.com/registry-operator/registry-operator/internal/manifests"
-	registryupgrade "github.com/registry-operator/registry-operator/internal/upgrade/registry"
-	"github.com/registry-operator/registry-operator/internal/version"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -52,28 +50,27 @@ func HandleReconcileStatus(
 	log.V(2).Info("Updating registry status")
 	if err != nil {
 		params.Recorder.Event(&registry, eventTypeWarning, reasonError, err.Error())
+
+		changed := registry.DeepCopy()
+		SetReconcileError(changed, err)
+		statusPatch := client.MergeFrom(&registry)
+		if patchErr := params.Client.Status().Patch(ctx, changed, statusPatch); patchErr != nil {
+			log.Error(patchErr, "Failed to record reconcile error in the Registry status")
+		}
 		return ctrl.Result{}, err
 	}
 	changed := registry.DeepCopy()
 
-	up := &registryupgrade.VersionUpgrade{
-		Version:  version.Get(),
-		Client:   params.Client,
-		Recorder: params.Recorder,
-	}
-
-	upgraded, upgradeErr := up.ManagedInstance(ctx, *changed)
-	if upgradeErr != nil {
-		// don't fail to allow setting the status
-		log.V(2).Error(upgradeErr, "Failed to upgrade the Registry CR")
-	}
-
-	changed = &upgraded
-	statusErr := UpdateRegistryStatus(ctx, params.Client, changed)
+	// the status reflects the effective spec, including the defaults of the RegistryClass
+	effective := params.Registry.DeepCopy()
+	effective.Status = changed.Status
+	statusErr := UpdateRegistryStatus(ctx, params.Client, effective)
 	if statusErr != nil {
 		params.Recorder.Event(changed, eventTypeWarning, reasonStatusFailure, statusErr.Error())
 		return ctrl.Result{}, statusErr
 	}
+	SetStorageMigration(effective, params.Migration)
+	changed.Status = effective.Status
 
 	statusPatch := client.MergeFrom(&registry)
 	if err := params.Client.Status().Patch(ctx, changed, statusPatch); err != nil {
@@ -0,0 +1,56 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	reasonMigrationPending    = "MigrationPending"
+	reasonMigrationInProgress = "MigrationInProgress"
+	reasonMigrationSucceeded  = "MigrationSucceeded"
+	reasonVerificationFailed  = "VerificationFailed"
+	reasonMigrationFailed     = "MigrationFailed"
+)
+
+// SetStorageMigration sets the StorageMigrated condition from the storage migration of the Registry. The
+// condition of the last migration is kept once the Registry serves from its new storage.
+func SetStorageMigration(changed *registryv1alpha1.Registry, m *manifests.StorageMigration) {
+	if m == nil {
+		return
+	}
+
+	switch m.Phase {
+	case manifests.StorageMigrationPending:
+		setCondition(changed, registryv1alpha1.ConditionTypeStorageMigrated, metav1.ConditionFalse,
+			reasonMigrationPending, "Waiting for every replica to serve in read-only mode")
+	case manifests.StorageMigrationRunning:
+		setCondition(changed, registryv1alpha1.ConditionTypeStorageMigrated, metav1.ConditionFalse,
+			reasonMigrationInProgress, "Images are being copied to the new storage, the Registry is read-only")
+	case manifests.StorageMigrationSucceeded:
+		setCondition(changed, registryv1alpha1.ConditionTypeStorageMigrated, metav1.ConditionTrue,
+			reasonMigrationSucceeded, "Images were copied to the new storage and their digests verified")
+	case manifests.StorageMigrationVerificationFailed:
+		setCondition(changed, registryv1alpha1.ConditionTypeStorageMigrated, metav1.ConditionFalse,
+			reasonVerificationFailed, m.Message+"; delete the Job to retry")
+	case manifests.StorageMigrationFailed:
+		setCondition(changed, registryv1alpha1.ConditionTypeStorageMigrated, metav1.ConditionFalse,
+			reasonMigrationFailed, m.Message+"; delete the Job to retry")
+	}
+}
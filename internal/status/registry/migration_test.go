@@ -0,0 +1,52 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetStorageMigration(t *testing.T) {
+	changed := &registryv1alpha1.Registry{}
+
+	SetStorageMigration(changed, nil)
+	assert.Empty(t, changed.Status.Conditions)
+
+	SetStorageMigration(changed, &manifests.StorageMigration{Phase: manifests.StorageMigrationRunning})
+	condition := meta.FindStatusCondition(changed.Status.Conditions, registryv1alpha1.ConditionTypeStorageMigrated)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, reasonMigrationInProgress, condition.Reason)
+
+	SetStorageMigration(changed, &manifests.StorageMigration{
+		Phase:   manifests.StorageMigrationVerificationFailed,
+		Message: "Job my-instance-registry-migration failed",
+	})
+	condition = meta.FindStatusCondition(changed.Status.Conditions, registryv1alpha1.ConditionTypeStorageMigrated)
+	assert.Equal(t, reasonVerificationFailed, condition.Reason)
+	assert.Contains(t, condition.Message, "Job my-instance-registry-migration failed")
+
+	SetStorageMigration(changed, &manifests.StorageMigration{Phase: manifests.StorageMigrationSucceeded})
+	assert.True(t, meta.IsStatusConditionTrue(changed.Status.Conditions, registryv1alpha1.ConditionTypeStorageMigrated))
+}
@@ -19,44 +19,358 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+	"github.com/registry-operator/registry-operator/internal/manifests/manifestutils"
+	manifestsregistry "github.com/registry-operator/registry-operator/internal/manifests/registry"
 	"github.com/registry-operator/registry-operator/internal/naming"
+	registryupgrade "github.com/registry-operator/registry-operator/internal/upgrade/registry"
 	"github.com/registry-operator/registry-operator/internal/version"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const (
+	reasonDeploymentNotFound     = "DeploymentNotFound"
+	reasonMinimumReplicas        = "MinimumReplicasAvailable"
+	reasonReplicasUnavailable    = "ReplicasUnavailable"
+	reasonRolloutInProgress      = "RolloutInProgress"
+	reasonRolloutComplete        = "RolloutComplete"
+	reasonProgressDeadline       = "ProgressDeadlineExceeded"
+	reasonReplicaFailure         = "ReplicaFailure"
+	reasonAsExpected             = "AsExpected"
+	reasonReconcileFailed        = "ReconcileFailed"
+	reasonConfigGenerated        = "ConfigGenerated"
+	reasonConfigInvalid          = "ConfigInvalid"
+	reasonVolumeBound            = "VolumeBound"
+	reasonVolumeNotBound         = "VolumeNotBound"
+	reasonVolumeNotFound         = "VolumeNotFound"
+	reasonStorageConfigured      = "StorageConfigured"
+	reasonImageSupported         = "ImageSupported"
+	reasonConfigurationIgnored   = "ConfigurationIgnored"
+	deploymentProgressCompletion = "NewReplicaSetAvailable"
+)
+
+// UpdateRegistryStatus refreshes the status of the given Registry from the objects it owns.
 func UpdateRegistryStatus(ctx context.Context, cli client.Client, changed *registryv1alpha1.Registry) error {
 	if changed.Status.Version == "" {
 		// a version is not set, otherwise let the upgrade mechanism take care of it!
 		changed.Status.Version = version.Registry()
 	}
+	changed.Status.ObservedGeneration = changed.Generation
+	changed.Status.ServedStorage = changed.Spec.Storage.DeepCopy()
+	changed.Status.Selector = labels.SelectorFromSet(
+		manifestutils.SelectorLabels(changed.ObjectMeta, manifestsregistry.ComponentRegistry),
+	).String()
+
+	setCondition(changed, registryv1alpha1.ConditionTypeConfigValid, metav1.ConditionTrue,
+		reasonConfigGenerated, "Registry configuration was generated")
+	updateImageSupported(changed)
+
+	if err := updateStorageStatus(ctx, cli, changed); err != nil {
+		return err
+	}
+
+	if err := updateEndpoints(ctx, cli, changed); err != nil {
+		return err
+	}
+
+	if err := updateLastBackup(ctx, cli, changed); err != nil {
+		return err
+	}
+	if err := updateLastSnapshot(ctx, cli, changed); err != nil {
+		return err
+	}
 
-	// Set the scale replicas
 	objKey := client.ObjectKey{
 		Namespace: changed.GetNamespace(),
 		Name:      naming.Registry(changed.Name),
 	}
 
-	var replicas int32
-	var readyReplicas int32
-	var statusImage string
-
 	obj := &appsv1.Deployment{}
 	if err := cli.Get(ctx, objKey, obj); err != nil {
-		return fmt.Errorf("failed to get deployment status.replicas: %w", err)
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get deployment status.replicas: %w", err)
+		}
+		changed.Status.DesiredReplicas = 0
+		changed.Status.ReadyReplicas = 0
+		changed.Status.Replicas = 0
+		setCondition(changed, registryv1alpha1.ConditionTypeAvailable, metav1.ConditionFalse,
+			reasonDeploymentNotFound, "Registry Deployment does not exist yet")
+		setCondition(changed, registryv1alpha1.ConditionTypeProgressing, metav1.ConditionTrue,
+			reasonDeploymentNotFound, "Registry Deployment does not exist yet")
+		setCondition(changed, registryv1alpha1.ConditionTypeDegraded, metav1.ConditionFalse,
+			reasonAsExpected, "")
+		changed.Status.Phase = registryv1alpha1.RegistryPhasePending
+		return nil
+	}
+
+	if len(obj.Spec.Template.Spec.Containers) > 0 {
+		changed.Status.Image = obj.Spec.Template.Spec.Containers[0].Image
+	}
+	changed.Status.DesiredReplicas = 1
+	if obj.Spec.Replicas != nil {
+		changed.Status.DesiredReplicas = *obj.Spec.Replicas
+	}
+	changed.Status.ReadyReplicas = obj.Status.ReadyReplicas
+	changed.Status.Replicas = obj.Status.Replicas
+
+	updateWorkloadConditions(changed, obj)
+	changed.Status.Phase = phase(changed)
+
+	return nil
+}
+
+// SetReconcileError records a failed reconciliation in the status of the given Registry. The ConfigValid
+// condition only reports the errors marked as manifests.ErrInvalidConfig, leaving out the transient ones.
+func SetReconcileError(changed *registryv1alpha1.Registry, err error) {
+	changed.Status.ObservedGeneration = changed.Generation
+	if invalid := invalidConfig(err); invalid != nil {
+		setCondition(changed, registryv1alpha1.ConditionTypeConfigValid, metav1.ConditionFalse,
+			reasonConfigInvalid, invalid.Error())
+	}
+	setCondition(changed, registryv1alpha1.ConditionTypeDegraded, metav1.ConditionTrue,
+		reasonReconcileFailed, err.Error())
+	changed.Status.Phase = phase(changed)
+}
+
+// updateImageSupported reports whether the image of the Registry reads the generated configuration, the image
+// pinned to a 2.x release of the distribution being kept by the upgrades.
+func updateImageSupported(changed *registryv1alpha1.Registry) {
+	if image := changed.Spec.Image; registryupgrade.IgnoresConfiguration(image) {
+		setCondition(changed, registryv1alpha1.ConditionTypeImageSupported, metav1.ConditionFalse,
+			reasonConfigurationIgnored, fmt.Sprintf("Image %s is a 2.x release of the distribution, which ignores "+
+				"the generated configuration, pin a 3.x release or remove spec.image", image))
+		return
+	}
+	setCondition(changed, registryv1alpha1.ConditionTypeImageSupported, metav1.ConditionTrue,
+		reasonImageSupported, "Registry image reads the generated configuration")
+}
+
+// invalidConfig returns the errors marked as manifests.ErrInvalidConfig among the given, possibly joined, errors.
+func invalidConfig(err error) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, e := range joined.Unwrap() {
+			if invalid := invalidConfig(e); invalid != nil {
+				errs = append(errs, invalid)
+			}
+		}
+		return errors.Join(errs...)
+	}
+	if errors.Is(err, manifests.ErrInvalidConfig) {
+		return err
+	}
+	return nil
+}
+
+// updateWorkloadConditions derives the Available, Progressing and Degraded conditions from the Deployment.
+func updateWorkloadConditions(changed *registryv1alpha1.Registry, dpl *appsv1.Deployment) {
+	desired := changed.Status.DesiredReplicas
+	ready := changed.Status.ReadyReplicas
+
+	available := deploymentCondition(dpl, appsv1.DeploymentAvailable)
+	if available != nil && available.Status == corev1.ConditionTrue && ready > 0 {
+		setCondition(changed, registryv1alpha1.ConditionTypeAvailable, metav1.ConditionTrue,
+			reasonMinimumReplicas, fmt.Sprintf("%d/%d replicas are ready", ready, desired))
+	} else {
+		setCondition(changed, registryv1alpha1.ConditionTypeAvailable, metav1.ConditionFalse,
+			reasonReplicasUnavailable, fmt.Sprintf("%d/%d replicas are ready", ready, desired))
+	}
+
+	degraded := false
+	rolledOut := dpl.Status.ObservedGeneration >= dpl.Generation &&
+		dpl.Status.UpdatedReplicas == desired &&
+		dpl.Status.Replicas == desired &&
+		dpl.Status.AvailableReplicas == desired
+
+	progressing := deploymentCondition(dpl, appsv1.DeploymentProgressing)
+	switch {
+	case progressing != nil && progressing.Reason == reasonProgressDeadline:
+		degraded = true
+		setCondition(changed, registryv1alpha1.ConditionTypeProgressing, metav1.ConditionFalse,
+			reasonProgressDeadline, progressing.Message)
+		setCondition(changed, registryv1alpha1.ConditionTypeDegraded, metav1.ConditionTrue,
+			reasonProgressDeadline, progressing.Message)
+		recordFailedRevision(changed, dpl)
+	case rolledOut && (progressing == nil || progressing.Reason == deploymentProgressCompletion):
+		setCondition(changed, registryv1alpha1.ConditionTypeProgressing, metav1.ConditionFalse,
+			reasonRolloutComplete, "Registry Deployment is rolled out")
+		recordGoodRevision(changed, dpl)
+	default:
+		setCondition(changed, registryv1alpha1.ConditionTypeProgressing, metav1.ConditionTrue,
+			reasonRolloutInProgress, fmt.Sprintf("%d/%d replicas are updated", dpl.Status.UpdatedReplicas, desired))
 	}
-	replicas = obj.Status.Replicas
-	readyReplicas = obj.Status.ReadyReplicas
-	statusImage = obj.Spec.Template.Spec.Containers[0].Image
 
-	changed.Status.Image = statusImage
-	if replicas != 0 && replicas == readyReplicas {
-		changed.Status.Ready = true
+	if failure := deploymentCondition(dpl, appsv1.DeploymentReplicaFailure); !degraded &&
+		failure != nil && failure.Status == corev1.ConditionTrue {
+		degraded = true
+		setCondition(changed, registryv1alpha1.ConditionTypeDegraded, metav1.ConditionTrue,
+			reasonReplicaFailure, failure.Message)
 	}
 
+	if !degraded && isRolledBack(changed, dpl) {
+		degraded = true
+		setRolledBack(changed)
+	}
+
+	if !degraded {
+		setCondition(changed, registryv1alpha1.ConditionTypeDegraded, metav1.ConditionFalse,
+			reasonAsExpected, "")
+	}
+}
+
+// updateStorageStatus sets the StorageReady condition based on the storage source of the Registry.
+func updateStorageStatus(ctx context.Context, cli client.Client, changed *registryv1alpha1.Registry) error {
+	var claimName string
+	switch storage := changed.Spec.Storage; {
+	case storage.PersistentVolumeClaim != nil:
+		claimName = storage.PersistentVolumeClaim.ClaimName
+	case storage.PersistentVolumeClaimTemplate != nil:
+		claimName = naming.PersistentVolumeClaim(changed.Name)
+	default:
+		setCondition(changed, registryv1alpha1.ConditionTypeStorageReady, metav1.ConditionTrue,
+			reasonStorageConfigured, "Registry storage is configured")
+		return nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	objKey := client.ObjectKey{Namespace: changed.GetNamespace(), Name: claimName}
+	if err := cli.Get(ctx, objKey, pvc); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get persistent volume claim: %w", err)
+		}
+		setCondition(changed, registryv1alpha1.ConditionTypeStorageReady, metav1.ConditionFalse,
+			reasonVolumeNotFound, fmt.Sprintf("PersistentVolumeClaim %s does not exist", claimName))
+		return nil
+	}
+
+	if pvc.Status.Phase != corev1.ClaimBound {
+		setCondition(changed, registryv1alpha1.ConditionTypeStorageReady, metav1.ConditionFalse,
+			reasonVolumeNotBound, fmt.Sprintf("PersistentVolumeClaim %s is %s", claimName, pvc.Status.Phase))
+		return nil
+	}
+
+	setCondition(changed, registryv1alpha1.ConditionTypeStorageReady, metav1.ConditionTrue,
+		reasonVolumeBound, fmt.Sprintf("PersistentVolumeClaim %s is bound", claimName))
+	return nil
+}
+
+// updateLastBackup records the most recent successful backup Job of the Registry, which outlives the Jobs
+// removed from the history of the CronJob.
+func updateLastBackup(ctx context.Context, cli client.Client, changed *registryv1alpha1.Registry) error {
+	jobs := &batchv1.JobList{}
+	if err := cli.List(ctx, jobs, client.InNamespace(changed.Namespace),
+		client.MatchingLabels(manifestutils.SelectorLabels(changed.ObjectMeta, manifestsregistry.ComponentBackup)),
+	); err != nil {
+		return fmt.Errorf("failed to list backup jobs: %w", err)
+	}
+
+	for _, job := range jobs.Items {
+		completed := job.Status.CompletionTime
+		if completed == nil || job.Status.Succeeded == 0 {
+			continue
+		}
+		if last := changed.Status.LastBackup; last == nil || last.CompletionTime.Before(completed) {
+			changed.Status.LastBackup = &registryv1alpha1.BackupRecord{
+				Name:           job.Name,
+				CompletionTime: *completed,
+			}
+		}
+	}
+	return nil
+}
+
+// updateLastSnapshot records the most recent scheduled VolumeSnapshot of the Registry ready to use.
+func updateLastSnapshot(ctx context.Context, cli client.Client, changed *registryv1alpha1.Registry) error {
+	if s := changed.Spec.Snapshots; s == nil || s.Schedule == "" {
+		return nil
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   manifestsregistry.VolumeSnapshotGroup,
+		Version: "v1",
+		Kind:    manifestsregistry.VolumeSnapshotKind + "List",
+	})
+	err := cli.List(ctx, list, client.InNamespace(changed.Namespace),
+		client.MatchingLabels(manifestutils.SelectorLabels(changed.ObjectMeta, manifestsregistry.ComponentScheduledSnapshot)),
+	)
+	if meta.IsNoMatchError(err) {
+		// reported by the reconciliation of the snapshots
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to list VolumeSnapshots: %w", err)
+	}
+
+	for _, snapshot := range list.Items {
+		if ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse"); !ready {
+			continue
+		}
+		created := snapshot.GetCreationTimestamp()
+		if last := changed.Status.LastSnapshot; last == nil || last.CompletionTime.Before(&created) {
+			changed.Status.LastSnapshot = &registryv1alpha1.BackupRecord{
+				Name:           snapshot.GetName(),
+				CompletionTime: created,
+			}
+		}
+	}
+	return nil
+}
+
+// phase summarizes the conditions of the Registry.
+func phase(registry *registryv1alpha1.Registry) registryv1alpha1.RegistryPhase {
+	conditions := registry.Status.Conditions
+	switch {
+	case meta.IsStatusConditionFalse(conditions, registryv1alpha1.ConditionTypeConfigValid):
+		return registryv1alpha1.RegistryPhaseFailed
+	case meta.IsStatusConditionTrue(conditions, registryv1alpha1.ConditionTypeDegraded):
+		return registryv1alpha1.RegistryPhaseDegraded
+	case meta.FindStatusCondition(conditions, registryv1alpha1.ConditionTypeAvailable) == nil:
+		return registryv1alpha1.RegistryPhasePending
+	case meta.IsStatusConditionTrue(conditions, registryv1alpha1.ConditionTypeProgressing),
+		meta.IsStatusConditionFalse(conditions, registryv1alpha1.ConditionTypeAvailable):
+		return registryv1alpha1.RegistryPhaseProgressing
+	default:
+		return registryv1alpha1.RegistryPhaseRunning
+	}
+}
+
+func setCondition(
+	registry *registryv1alpha1.Registry,
+	conditionType string,
+	status metav1.ConditionStatus,
+	reason, message string,
+) {
+	meta.SetStatusCondition(&registry.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: registry.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+func deploymentCondition(
+	dpl *appsv1.Deployment,
+	conditionType appsv1.DeploymentConditionType,
+) *appsv1.DeploymentCondition {
+	for i := range dpl.Status.Conditions {
+		if dpl.Status.Conditions[i].Type == conditionType {
+			return &dpl.Status.Conditions[i]
+		}
+	}
 	return nil
 }
@@ -16,3 +16,221 @@
 // Copyright The OpenTelemetry Authors
 
 package registry
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateRegistryStatus(t *testing.T) {
+	registry := registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "my-instance",
+			Namespace:  "my-namespace",
+			Generation: 3,
+		},
+	}
+
+	deployment := func(status appsv1.DeploymentStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-instance-registry",
+				Namespace: "my-namespace",
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To[int32](2),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Image: "registry:3.0.0"}},
+					},
+				},
+			},
+			Status: status,
+		}
+	}
+
+	t.Run("should be pending without deployment", func(t *testing.T) {
+		cli := fake.NewClientBuilder().Build()
+		changed := registry.DeepCopy()
+
+		require.NoError(t, UpdateRegistryStatus(t.Context(), cli, changed))
+		assert.Equal(t, registryv1alpha1.RegistryPhasePending, changed.Status.Phase)
+		assert.Equal(t, int64(3), changed.Status.ObservedGeneration)
+		assert.Equal(t, &changed.Spec.Storage, changed.Status.ServedStorage)
+		assert.True(t, meta.IsStatusConditionFalse(changed.Status.Conditions, registryv1alpha1.ConditionTypeAvailable))
+		assert.True(t, meta.IsStatusConditionTrue(changed.Status.Conditions, registryv1alpha1.ConditionTypeImageSupported))
+	})
+
+	t.Run("should report a pinned 2.x image", func(t *testing.T) {
+		cli := fake.NewClientBuilder().Build()
+		changed := registry.DeepCopy()
+		changed.Spec.Image = "registry:2.8.3"
+
+		require.NoError(t, UpdateRegistryStatus(t.Context(), cli, changed))
+		cond := meta.FindStatusCondition(changed.Status.Conditions, registryv1alpha1.ConditionTypeImageSupported)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, reasonConfigurationIgnored, cond.Reason)
+	})
+
+	t.Run("should be running when rolled out", func(t *testing.T) {
+		cli := fake.NewClientBuilder().WithObjects(deployment(appsv1.DeploymentStatus{
+			Replicas:          2,
+			UpdatedReplicas:   2,
+			ReadyReplicas:     2,
+			AvailableReplicas: 2,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "NewReplicaSetAvailable"},
+			},
+		})).Build()
+		changed := registry.DeepCopy()
+
+		require.NoError(t, UpdateRegistryStatus(t.Context(), cli, changed))
+		assert.Equal(t, registryv1alpha1.RegistryPhaseRunning, changed.Status.Phase)
+		assert.Equal(t, int32(2), changed.Status.DesiredReplicas)
+		assert.Equal(t, int32(2), changed.Status.ReadyReplicas)
+		assert.Equal(t, int32(2), changed.Status.Replicas)
+		assert.Contains(t, changed.Status.Selector, "app.kubernetes.io/instance=my-namespace.my-instance")
+		assert.Equal(t, "registry:3.0.0", changed.Status.Image)
+		assert.True(t, meta.IsStatusConditionTrue(changed.Status.Conditions, registryv1alpha1.ConditionTypeAvailable))
+		assert.True(t, meta.IsStatusConditionFalse(changed.Status.Conditions, registryv1alpha1.ConditionTypeProgressing))
+		assert.True(t, meta.IsStatusConditionFalse(changed.Status.Conditions, registryv1alpha1.ConditionTypeDegraded))
+		assert.True(t, meta.IsStatusConditionTrue(changed.Status.Conditions, registryv1alpha1.ConditionTypeStorageReady))
+	})
+
+	t.Run("should become unavailable again", func(t *testing.T) {
+		cli := fake.NewClientBuilder().WithObjects(deployment(appsv1.DeploymentStatus{
+			Replicas:        2,
+			UpdatedReplicas: 2,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse},
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+			},
+		})).Build()
+		changed := registry.DeepCopy()
+		changed.Status.Conditions = []metav1.Condition{
+			{Type: registryv1alpha1.ConditionTypeAvailable, Status: metav1.ConditionTrue, Reason: "MinimumReplicasAvailable"},
+		}
+
+		require.NoError(t, UpdateRegistryStatus(t.Context(), cli, changed))
+		assert.Equal(t, registryv1alpha1.RegistryPhaseDegraded, changed.Status.Phase)
+		assert.True(t, meta.IsStatusConditionFalse(changed.Status.Conditions, registryv1alpha1.ConditionTypeAvailable))
+		assert.True(t, meta.IsStatusConditionTrue(changed.Status.Conditions, registryv1alpha1.ConditionTypeDegraded))
+	})
+
+	t.Run("should report unbound volume", func(t *testing.T) {
+		cli := fake.NewClientBuilder().WithObjects(&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-instance-registry", Namespace: "my-namespace"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		}).Build()
+		changed := registry.DeepCopy()
+		changed.Spec.Storage.PersistentVolumeClaimTemplate = &corev1.PersistentVolumeClaimSpec{}
+
+		require.NoError(t, UpdateRegistryStatus(t.Context(), cli, changed))
+		assert.True(t, meta.IsStatusConditionFalse(changed.Status.Conditions, registryv1alpha1.ConditionTypeStorageReady))
+	})
+
+	t.Run("should record the last successful backup", func(t *testing.T) {
+		backupJob := func(name string, completed time.Time, succeeded int32) *batchv1.Job {
+			return &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "my-namespace",
+					Labels: map[string]string{
+						"app.kubernetes.io/component":  "backup",
+						"app.kubernetes.io/instance":   "my-namespace.my-instance",
+						"app.kubernetes.io/managed-by": "registry-operator",
+						"app.kubernetes.io/part-of":    "registry",
+					},
+				},
+				Status: batchv1.JobStatus{CompletionTime: &metav1.Time{Time: completed}, Succeeded: succeeded},
+			}
+		}
+		now := time.Now().Truncate(time.Second)
+		cli := fake.NewClientBuilder().WithObjects(
+			backupJob("my-instance-registry-backup-1", now.Add(-2*time.Hour), 1),
+			backupJob("my-instance-registry-backup-2", now.Add(-time.Hour), 1),
+			backupJob("my-instance-registry-backup-3", now, 0),
+		).Build()
+		changed := registry.DeepCopy()
+
+		require.NoError(t, UpdateRegistryStatus(t.Context(), cli, changed))
+		require.NotNil(t, changed.Status.LastBackup)
+		assert.Equal(t, "my-instance-registry-backup-2", changed.Status.LastBackup.Name)
+		assert.True(t, changed.Status.LastBackup.CompletionTime.Equal(&metav1.Time{Time: now.Add(-time.Hour)}))
+	})
+
+	t.Run("should record the last scheduled snapshot ready to use", func(t *testing.T) {
+		snapshot := func(name string, created time.Time, ready bool) *unstructured.Unstructured {
+			snapshot := &unstructured.Unstructured{}
+			snapshot.SetAPIVersion("snapshot.storage.k8s.io/v1")
+			snapshot.SetKind("VolumeSnapshot")
+			snapshot.SetName(name)
+			snapshot.SetNamespace("my-namespace")
+			snapshot.SetCreationTimestamp(metav1.NewTime(created))
+			snapshot.SetLabels(map[string]string{
+				"app.kubernetes.io/component":  "scheduled-snapshot",
+				"app.kubernetes.io/instance":   "my-namespace.my-instance",
+				"app.kubernetes.io/managed-by": "registry-operator",
+				"app.kubernetes.io/part-of":    "registry",
+			})
+			require.NoError(t, unstructured.SetNestedField(snapshot.Object, ready, "status", "readyToUse"))
+			return snapshot
+		}
+		now := time.Now().Truncate(time.Second)
+		cli := fake.NewClientBuilder().WithObjects(
+			snapshot("my-instance-registry-1", now.Add(-time.Hour), true),
+			snapshot("my-instance-registry-2", now, false),
+		).Build()
+		changed := registry.DeepCopy()
+		changed.Spec.Snapshots = &registryv1alpha1.Snapshots{Schedule: "@hourly"}
+
+		require.NoError(t, UpdateRegistryStatus(t.Context(), cli, changed))
+		require.NotNil(t, changed.Status.LastSnapshot)
+		assert.Equal(t, "my-instance-registry-1", changed.Status.LastSnapshot.Name)
+	})
+}
+
+func TestSetReconcileError(t *testing.T) {
+	t.Run("invalid configuration", func(t *testing.T) {
+		changed := &registryv1alpha1.Registry{}
+		invalid := fmt.Errorf("%w: invalid endpoint URL", manifests.ErrInvalidConfig)
+
+		SetReconcileError(changed, errors.Join(assert.AnError, invalid))
+
+		assert.Equal(t, registryv1alpha1.RegistryPhaseFailed, changed.Status.Phase)
+		cond := meta.FindStatusCondition(changed.Status.Conditions, registryv1alpha1.ConditionTypeConfigValid)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, invalid.Error(), cond.Message)
+		assert.True(t, meta.IsStatusConditionTrue(changed.Status.Conditions, registryv1alpha1.ConditionTypeDegraded))
+	})
+
+	t.Run("transient error", func(t *testing.T) {
+		changed := &registryv1alpha1.Registry{}
+
+		SetReconcileError(changed, fmt.Errorf("failed to fetch secret: %w", assert.AnError))
+
+		assert.Equal(t, registryv1alpha1.RegistryPhaseDegraded, changed.Status.Phase)
+		assert.Nil(t, meta.FindStatusCondition(changed.Status.Conditions, registryv1alpha1.ConditionTypeConfigValid))
+		assert.True(t, meta.IsStatusConditionTrue(changed.Status.Conditions, registryv1alpha1.ConditionTypeDegraded))
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	manifestsregistry "github.com/registry-operator/registry-operator/internal/manifests/registry"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	reasonRolledBack          = "RolledBack"
+	reasonRollbackUnsupported = "RollbackUnsupported"
+)
+
+// recordFailedRevision records the revision of the given Deployment, whose rollout exceeded its progress deadline,
+// for the Registry to be rolled back to its last known good revision. A revision which changed more than the image
+// and the configuration of the Registry is not rolled back, reverting only them could not fix it.
+func recordFailedRevision(changed *registryv1alpha1.Registry, dpl *appsv1.Deployment) {
+	rp := changed.Spec.RolloutPolicy
+	if rp == nil || !rp.AutoRollback || dpl.Status.ObservedGeneration < dpl.Generation {
+		// the condition may belong to the previous revision
+		return
+	}
+
+	revision := manifestsregistry.DeploymentRevision(dpl)
+	good := changed.Status.LastKnownGoodRevision
+	if good == nil || manifestsregistry.SameRevision(revision, *good) {
+		// there is no revision left to roll back to
+		return
+	}
+	if revision.Template != good.Template {
+		setCondition(changed, registryv1alpha1.ConditionTypeDegraded, metav1.ConditionTrue, reasonRollbackUnsupported,
+			fmt.Sprintf("Rollout of image %s with configuration Secret %s exceeded its progress deadline, "+
+				"and cannot be rolled back since it changed the storage, resources or environment of the Registry",
+				revision.Image, revision.ConfigSecret))
+		return
+	}
+	changed.Status.FailedRevision = &revision
+}
+
+// recordGoodRevision records the revision of the given rolled out Deployment as the last known good one, unless
+// the Registry was rolled back to it.
+func recordGoodRevision(changed *registryv1alpha1.Registry, dpl *appsv1.Deployment) {
+	if isRolledBack(changed, dpl) {
+		return
+	}
+	revision := manifestsregistry.DeploymentRevision(dpl)
+	changed.Status.LastKnownGoodRevision = &revision
+	changed.Status.FailedRevision = nil
+}
+
+// isRolledBack reports whether the given Deployment was rolled back to the last known good revision of the
+// Registry after the rollout of its failed revision.
+func isRolledBack(changed *registryv1alpha1.Registry, dpl *appsv1.Deployment) bool {
+	good, failed := changed.Status.LastKnownGoodRevision, changed.Status.FailedRevision
+	return good != nil && failed != nil && manifestsregistry.SameRevision(manifestsregistry.DeploymentRevision(dpl), *good)
+}
+
+// setRolledBack marks the Registry Degraded while it is rolled back from its failed revision.
+func setRolledBack(changed *registryv1alpha1.Registry) {
+	good, failed := changed.Status.LastKnownGoodRevision, changed.Status.FailedRevision
+	setCondition(changed, registryv1alpha1.ConditionTypeDegraded, metav1.ConditionTrue, reasonRolledBack,
+		fmt.Sprintf("Rollout of image %s with configuration Secret %s exceeded its progress deadline, "+
+			"rolled back to image %s with configuration Secret %s",
+			failed.Image, failed.ConfigSecret, good.Image, good.ConfigSecret))
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	manifestsregistry "github.com/registry-operator/registry-operator/internal/manifests/registry"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateRegistryStatusRollback(t *testing.T) {
+	good := registryv1alpha1.RolloutRevision{Image: "registry:3.0.0", ConfigSecret: "my-instance-good"}
+	broken := registryv1alpha1.RolloutRevision{Image: "registry:3.0.1", ConfigSecret: "my-instance-broken"}
+	registry := registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-instance", Namespace: "my-namespace"},
+		Spec: registryv1alpha1.RegistrySpec{
+			RolloutPolicy: &registryv1alpha1.RolloutPolicy{AutoRollback: true},
+		},
+	}
+
+	deployment := func(revision registryv1alpha1.RolloutRevision, progressing string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-instance-registry", Namespace: "my-namespace", Generation: 2},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To[int32](1),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "distribution", Image: revision.Image}},
+						Volumes: []corev1.Volume{{
+							Name: "config",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{SecretName: revision.ConfigSecret},
+							},
+						}},
+					},
+				},
+			},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: 2,
+				Replicas:           1,
+				UpdatedReplicas:    1,
+				ReadyReplicas:      1,
+				AvailableReplicas:  1,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+					{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: progressing},
+				},
+			},
+		}
+	}
+	// the revisions share the rest of the Pod template
+	template := manifestsregistry.DeploymentRevision(deployment(good, "")).Template
+	good.Template, broken.Template = template, template
+	update := func(changed *registryv1alpha1.Registry, dpl *appsv1.Deployment) {
+		t.Helper()
+		cli := fake.NewClientBuilder().WithObjects(dpl).Build()
+		require.NoError(t, UpdateRegistryStatus(t.Context(), cli, changed))
+	}
+
+	changed := registry.DeepCopy()
+
+	t.Run("should record the last known good revision", func(t *testing.T) {
+		update(changed, deployment(good, deploymentProgressCompletion))
+		assert.Equal(t, &good, changed.Status.LastKnownGoodRevision)
+		assert.Nil(t, changed.Status.FailedRevision)
+	})
+
+	t.Run("should record the failed revision", func(t *testing.T) {
+		update(changed, deployment(broken, reasonProgressDeadline))
+		assert.Equal(t, &broken, changed.Status.FailedRevision)
+		assert.Equal(t, &good, changed.Status.LastKnownGoodRevision)
+	})
+
+	t.Run("should stay degraded once rolled back", func(t *testing.T) {
+		update(changed, deployment(good, deploymentProgressCompletion))
+		assert.Equal(t, &broken, changed.Status.FailedRevision)
+		assert.Equal(t, registryv1alpha1.RegistryPhaseDegraded, changed.Status.Phase)
+		condition := meta.FindStatusCondition(changed.Status.Conditions, registryv1alpha1.ConditionTypeDegraded)
+		require.NotNil(t, condition)
+		assert.Equal(t, reasonRolledBack, condition.Reason)
+		assert.Contains(t, condition.Message, "registry:3.0.1")
+	})
+
+	t.Run("should recover once a new revision is rolled out", func(t *testing.T) {
+		fixed := registryv1alpha1.RolloutRevision{
+			Image:        "registry:3.0.2",
+			ConfigSecret: "my-instance-good",
+			Template:     template,
+		}
+		update(changed, deployment(fixed, deploymentProgressCompletion))
+		assert.Equal(t, &fixed, changed.Status.LastKnownGoodRevision)
+		assert.Nil(t, changed.Status.FailedRevision)
+		assert.Equal(t, registryv1alpha1.RegistryPhaseRunning, changed.Status.Phase)
+	})
+
+	t.Run("should not roll back a failed change of the Pod template", func(t *testing.T) {
+		changed := changed.DeepCopy()
+		dpl := deployment(broken, reasonProgressDeadline)
+		dpl.Spec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		}
+		update(changed, dpl)
+		assert.Nil(t, changed.Status.FailedRevision)
+		condition := meta.FindStatusCondition(changed.Status.Conditions, registryv1alpha1.ConditionTypeDegraded)
+		require.NotNil(t, condition)
+		assert.Equal(t, reasonRollbackUnsupported, condition.Reason)
+	})
+
+	t.Run("should not record the failed revision without auto rollback", func(t *testing.T) {
+		changed := changed.DeepCopy()
+		changed.Spec.RolloutPolicy = nil
+		update(changed, deployment(broken, reasonProgressDeadline))
+		assert.Nil(t, changed.Status.FailedRevision)
+	})
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package trust decides whether a Registry may act on a namespace other than its own.
+package trust
+
+import (
+	"strings"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Trusts reports whether the given namespace trusts the given Registry, either because the Registry lives in
+// it, or because its registry-operator.dev/trusted-registries annotation lists the Registry.
+func Trusts(namespace *corev1.Namespace, registry *registryv1alpha1.Registry) bool {
+	if namespace.Name == registry.Namespace {
+		return true
+	}
+
+	for entry := range strings.SplitSeq(namespace.Annotations[registryv1alpha1.TrustedRegistriesAnnotation], ",") {
+		ns, name, ok := strings.Cut(strings.TrimSpace(entry), "/")
+		if ok && ns == registry.Namespace && (name == "*" || name == registry.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trust_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/trust"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTrusts(t *testing.T) {
+	registry := &registryv1alpha1.Registry{ObjectMeta: metav1.ObjectMeta{Name: "mirror", Namespace: "registry"}}
+	namespace := func(name, trusted string) *corev1.Namespace {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if trusted != "" {
+			ns.Annotations = map[string]string{registryv1alpha1.TrustedRegistriesAnnotation: trusted}
+		}
+		return ns
+	}
+
+	for _, tc := range []struct {
+		desc      string
+		namespace *corev1.Namespace
+		expected  bool
+	}{
+		{desc: "own namespace", namespace: namespace("registry", ""), expected: true},
+		{desc: "no annotation", namespace: namespace("team-a", "")},
+		{desc: "listed", namespace: namespace("team-a", "other/cache, registry/mirror"), expected: true},
+		{desc: "namespace wildcard", namespace: namespace("team-a", "registry/*"), expected: true},
+		{desc: "other Registry", namespace: namespace("team-a", "registry/cache")},
+		{desc: "other namespace", namespace: namespace("team-a", "other/*")},
+		{desc: "malformed", namespace: namespace("team-a", "registry")},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, trust.Trusts(tc.namespace, registry))
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+
+	semver "github.com/Masterminds/semver/v3"
+)
+
+// LatestInChannel returns the newest of the given tags released in the channel, e.g. 3.0.x. Only the tags of
+// full semantic versions are considered, so that floating tags like 3.0 and pre-releases are skipped.
+func LatestInChannel(channel string, tags []string) (string, error) {
+	constraint, err := semver.NewConstraint(channel)
+	if err != nil {
+		return "", fmt.Errorf("invalid channel %q: %w", channel, err)
+	}
+
+	var latest *semver.Version
+	for _, tag := range tags {
+		v, err := semver.StrictNewVersion(tag)
+		if err != nil || !constraint.Check(v) {
+			continue
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+		}
+	}
+	if latest == nil {
+		return "", fmt.Errorf("no release of channel %s", channel)
+	}
+	return latest.Original(), nil
+}
+
+// CompareVersions compares the version of a Registry instance with a candidate version, parsed as the versions
+// of the upgrades. It returns -1, 0 or 1 when the candidate is older, the same or newer.
+func CompareVersions(current, candidate string) (int, error) {
+	currentV, err := semver.NewVersion(current)
+	if err != nil {
+		return 0, fmt.Errorf("%w: current version: %v", err, current)
+	}
+	candidateV, err := semver.NewVersion(candidate)
+	if err != nil {
+		return 0, fmt.Errorf("%w: new version: %v", err, candidate)
+	}
+	return candidateV.Compare(currentV), nil
+}
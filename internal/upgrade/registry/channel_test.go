@@ -0,0 +1,74 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/registry-operator/registry-operator/internal/upgrade/registry"
+)
+
+func TestLatestInChannel(t *testing.T) {
+	tags := []string{"latest", "2", "2.8", "2.8.3", "3", "3.0", "3.0.0", "3.0.1", "3.0.2-rc.1", "3.1.0", "v3.0.9"}
+	for _, tt := range []struct {
+		channel  string
+		expected string
+		err      bool
+	}{
+		{channel: "3.0.x", expected: "3.0.1"},
+		{channel: "3.x", expected: "3.1.0"},
+		{channel: "2.8.x", expected: "2.8.3"},
+		{channel: "4.0.x", err: true},
+		{channel: "not a channel", err: true},
+	} {
+		t.Run(tt.channel, func(t *testing.T) {
+			latest, err := registry.LatestInChannel(tt.channel, tags)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, latest)
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tt := range []struct {
+		current   string
+		candidate string
+		expected  int
+		err       bool
+	}{
+		{current: "3.0.0", candidate: "3.0.1", expected: 1},
+		{current: "3.0.1", candidate: "3.0.1", expected: 0},
+		{current: "3.0.1", candidate: "2.8.3", expected: -1},
+		{current: "unparseable", candidate: "3.0.1", err: true},
+		{current: "3.0.1", candidate: "unparseable", err: true},
+	} {
+		t.Run(tt.current+"-"+tt.candidate, func(t *testing.T) {
+			cmp, err := registry.CompareVersions(tt.current, tt.candidate)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cmp)
+		})
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// MigratedFromAnnotation records the UID of the legacy Registry a Registry was migrated from.
+const MigratedFromAnnotation = "registry-operator.dev/migrated-from"
+
+// LegacyGroupVersion is the API group the Registry kind was served from by earlier releases.
+var LegacyGroupVersion = schema.GroupVersion{Group: "registry.registry-operator.dev", Version: "v1alpha1"}
+
+var errMigrationConflict = errors.New("registry already exists and was not migrated from the legacy object")
+
+// GroupMigration moves Registries from the legacy API group into the current one.
+type GroupMigration struct {
+	Client   client.Client
+	Recorder record.EventRecorder
+}
+
+// ManagedInstances finds all the registry instances of the legacy API group and migrates them.
+// The objects owned by a legacy instance are handed over to its successor, so they are kept as they are.
+func (m GroupMigration) ManagedInstances(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	log.Info("Looking for legacy instances to migrate")
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(LegacyGroupVersion.WithKind(registryv1alpha1.RegistryKind + "List"))
+	if err := m.Client.List(ctx, list); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			log.Info("No legacy instances to migrate")
+			return nil
+		}
+		return fmt.Errorf("failed to list: %w", err)
+	}
+
+	for i := range list.Items {
+		legacy := &list.Items[i]
+		itemLogger := log.WithValues("registry", klog.KObj(legacy))
+
+		if err := m.ManagedInstance(ctx, legacy); err != nil {
+			itemLogger.Error(err, "failed to migrate legacy instance")
+			m.Recorder.Event(legacy, corev1.EventTypeWarning, "Migration", fmt.Sprintf("Migration failed: %v", err))
+			continue
+		}
+		itemLogger.Info("Instance migrated", "group", registryv1alpha1.GroupVersion.Group)
+	}
+
+	if len(list.Items) == 0 {
+		log.Info("No legacy instances to migrate")
+	}
+
+	return nil
+}
+
+// ManagedInstance copies the given legacy registry instance into the current API group, re-parents
+// the objects it owns and removes it. It can be retried until it succeeds.
+func (m GroupMigration) ManagedInstance(ctx context.Context, legacy *unstructured.Unstructured) error {
+	if legacy.GetDeletionTimestamp() != nil {
+		// the instance is going away, nothing to migrate
+		return m.removeLegacy(ctx, legacy)
+	}
+
+	successor, err := m.successor(ctx, legacy)
+	if err != nil {
+		return err
+	}
+
+	ownedObjectTypes := []client.ObjectList{
+		&appsv1.DeploymentList{},
+		&corev1.ServiceList{},
+		&corev1.SecretList{},
+		&corev1.PersistentVolumeClaimList{},
+	}
+	for _, objectList := range ownedObjectTypes {
+		if err := m.reparent(ctx, legacy.GetUID(), successor, objectList); err != nil {
+			return err
+		}
+	}
+
+	if err := m.removeLegacy(ctx, legacy); err != nil {
+		return err
+	}
+
+	m.Recorder.Event(successor, corev1.EventTypeNormal, "Migration", fmt.Sprintf(
+		"Migrated from %s, the legacy object was removed", LegacyGroupVersion.Group,
+	))
+	return nil
+}
+
+// successor returns the Registry in the current API group replacing the legacy instance, creating it if needed.
+func (m GroupMigration) successor(
+	ctx context.Context,
+	legacy *unstructured.Unstructured,
+) (*registryv1alpha1.Registry, error) {
+	successor := &registryv1alpha1.Registry{}
+	err := m.Client.Get(ctx, client.ObjectKeyFromObject(legacy), successor)
+	switch {
+	case err == nil:
+		if successor.Annotations[MigratedFromAnnotation] != string(legacy.GetUID()) {
+			return nil, errMigrationConflict
+		}
+		return successor, nil
+	case !apierrors.IsNotFound(err):
+		return nil, fmt.Errorf("failed to get registry: %w", err)
+	}
+
+	successor = &registryv1alpha1.Registry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        legacy.GetName(),
+			Namespace:   legacy.GetNamespace(),
+			Labels:      legacy.GetLabels(),
+			Annotations: legacy.GetAnnotations(),
+		},
+	}
+	if successor.Annotations == nil {
+		successor.Annotations = map[string]string{}
+	}
+	successor.Annotations[MigratedFromAnnotation] = string(legacy.GetUID())
+
+	if spec, ok, _ := unstructured.NestedMap(legacy.Object, "spec"); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, &successor.Spec); err != nil {
+			return nil, fmt.Errorf("failed to convert spec: %w", err)
+		}
+	}
+
+	if err := m.Client.Create(ctx, successor); err != nil {
+		return nil, fmt.Errorf("failed to create registry: %w", err)
+	}
+
+	// keep the version, so that the upgrade routines apply to the successor as well
+	if v, ok, _ := unstructured.NestedString(legacy.Object, "status", "version"); ok && v != "" {
+		patch := client.MergeFrom(successor.DeepCopy())
+		successor.Status.Version = v
+		if err := m.Client.Status().Patch(ctx, successor, patch); err != nil {
+			return nil, fmt.Errorf("failed to apply changes to registry's status object: %w", err)
+		}
+	}
+
+	m.Recorder.Event(successor, corev1.EventTypeNormal, "Migration", fmt.Sprintf(
+		"Created from the %s object, handing over its resources", LegacyGroupVersion.Group,
+	))
+	return successor, nil
+}
+
+// reparent transfers the objects of the given type owned by the legacy instance to its successor.
+func (m GroupMigration) reparent(
+	ctx context.Context,
+	legacyUID types.UID,
+	successor *registryv1alpha1.Registry,
+	objectList client.ObjectList,
+) error {
+	if err := m.Client.List(ctx, objectList, client.InNamespace(successor.Namespace)); err != nil {
+		return fmt.Errorf("failed to list %T: %w", objectList, err)
+	}
+
+	owner := metav1.NewControllerRef(successor, registryv1alpha1.GroupVersion.WithKind(registryv1alpha1.RegistryKind))
+
+	return meta.EachListItem(objectList, func(o runtime.Object) error {
+		obj, ok := o.(client.Object)
+		if !ok {
+			return fmt.Errorf("unexpected list item %T", o)
+		}
+
+		refs := obj.GetOwnerReferences()
+		idx := -1
+		for i, ref := range refs {
+			if ref.UID == legacyUID {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil
+		}
+
+		patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		refs[idx] = *owner
+		obj.SetOwnerReferences(refs)
+		if err := m.Client.Patch(ctx, obj, patch); err != nil {
+			return fmt.Errorf("failed to re-parent %s: %w", klog.KObj(obj), err)
+		}
+
+		gvk, err := apiutil.GVKForObject(obj, m.Client.Scheme())
+		if err != nil {
+			return err
+		}
+		m.Recorder.Event(successor, corev1.EventTypeNormal, "Migration", fmt.Sprintf(
+			"Took over %s %s", gvk.Kind, obj.GetName(),
+		))
+		return nil
+	})
+}
+
+// removeLegacy deletes the legacy instance without cascading to the objects it may still own.
+func (m GroupMigration) removeLegacy(ctx context.Context, legacy *unstructured.Unstructured) error {
+	// no controller serves the legacy group anymore, so its finalizers would never be removed
+	if len(legacy.GetFinalizers()) > 0 {
+		patch := client.MergeFrom(legacy.DeepCopy())
+		legacy.SetFinalizers(nil)
+		if err := m.Client.Patch(ctx, legacy, patch); err != nil {
+			return fmt.Errorf("failed to remove finalizers: %w", err)
+		}
+	}
+
+	if legacy.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	err := m.Client.Delete(ctx, legacy, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+	return client.IgnoreNotFound(err)
+}
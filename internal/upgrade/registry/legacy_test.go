@@ -0,0 +1,125 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/upgrade/registry"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGroupMigration(t *testing.T) {
+	legacyUID := types.UID("legacy-uid")
+	legacy := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": registry.LegacyGroupVersion.String(),
+		"kind":       registryv1alpha1.RegistryKind,
+		"metadata": map[string]any{
+			"name":       "my-instance",
+			"namespace":  "default",
+			"uid":        string(legacyUID),
+			"labels":     map[string]any{"team": "platform"},
+			"finalizers": []any{"registry.registry-operator.dev/finalizer"},
+		},
+		"spec":   map[string]any{"image": "registry:2.8.3"},
+		"status": map[string]any{"version": "2.8.3", "ready": true},
+	}}
+	legacyRef := metav1.OwnerReference{
+		APIVersion: registry.LegacyGroupVersion.String(),
+		Kind:       registryv1alpha1.RegistryKind,
+		Name:       "my-instance",
+		UID:        legacyUID,
+		Controller: ptr.To(true),
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "my-instance-registry",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{legacyRef},
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "my-instance-registry",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{legacyRef},
+		},
+	}
+	unrelated := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "default"},
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for gvk := range testScheme.AllKnownTypes() {
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+	mapper.Add(registry.LegacyGroupVersion.WithKind(registryv1alpha1.RegistryKind), meta.RESTScopeNamespace)
+
+	cli := fake.NewClientBuilder().
+		WithScheme(testScheme).
+		WithRESTMapper(mapper).
+		WithStatusSubresource(&registryv1alpha1.Registry{}).
+		WithObjects(legacy, deployment, service, unrelated).
+		Build()
+	recorder := record.NewFakeRecorder(registry.RecordBufferSize)
+
+	m := registry.GroupMigration{Client: cli, Recorder: recorder}
+	require.NoError(t, m.ManagedInstances(t.Context()))
+
+	successor := &registryv1alpha1.Registry{}
+	require.NoError(t, cli.Get(t.Context(), client.ObjectKeyFromObject(legacy), successor))
+	assert.Equal(t, "registry:2.8.3", successor.Spec.Image)
+	assert.Equal(t, "2.8.3", successor.Status.Version)
+	assert.Equal(t, "platform", successor.Labels["team"])
+	assert.Equal(t, string(legacyUID), successor.Annotations[registry.MigratedFromAnnotation])
+
+	for _, obj := range []client.Object{&appsv1.Deployment{}, &corev1.Service{}} {
+		require.NoError(t, cli.Get(t.Context(), client.ObjectKeyFromObject(deployment), obj))
+		require.Len(t, obj.GetOwnerReferences(), 1)
+		owner := obj.GetOwnerReferences()[0]
+		assert.Equal(t, successor.UID, owner.UID)
+		assert.Equal(t, registryv1alpha1.GroupVersion.String(), owner.APIVersion)
+	}
+
+	err := cli.Get(t.Context(), client.ObjectKeyFromObject(legacy), legacy.DeepCopy())
+	assert.True(t, apierrors.IsNotFound(err), "legacy instance should be removed")
+
+	assert.Len(t, recorder.Events, 4, "created, two objects taken over and migrated")
+
+	t.Run("should not migrate over an unrelated registry", func(t *testing.T) {
+		conflicting := legacy.DeepCopy()
+		conflicting.SetUID("another-uid")
+		conflicting.SetResourceVersion("")
+		require.NoError(t, cli.Create(t.Context(), conflicting))
+
+		require.NoError(t, m.ManagedInstances(t.Context()))
+		require.NoError(t, cli.Get(t.Context(), client.ObjectKeyFromObject(legacy), legacy.DeepCopy()))
+	})
+}
@@ -22,12 +22,15 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	semver "github.com/Masterminds/semver/v3"
 
 	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
 	"github.com/registry-operator/registry-operator/internal/version"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,6 +45,25 @@ type VersionUpgrade struct {
 
 const RecordBufferSize int = 100
 
+// maxUpgradeHistory is the number of upgrades kept in the history of a Registry.
+const maxUpgradeHistory = 10
+
+const (
+	reasonUpgradeDryRun = "UpgradeDryRun"
+	reasonUpgradeHeld   = "UpgradeHeld"
+)
+
+// HeldImage returns the image run by the given Registry while its upgrades are held: the default image at the
+// version of its status. It returns an empty string when its upgrades are not held, or its image is pinned.
+func HeldImage(registry registryv1alpha1.Registry) string {
+	held := registry.Annotations[registryv1alpha1.UpgradeHoldAnnotation] == "true" ||
+		registry.Annotations[registryv1alpha1.UpgradeDryRunAnnotation] == "true"
+	if !held || registry.Spec.Image != "" || registry.Status.Version == "" {
+		return ""
+	}
+	return version.GetRegistryRepository() + ":" + registry.Status.Version
+}
+
 // ManagedInstances finds all the registry instances for the current operator and upgrades them, if necessary.
 func (u VersionUpgrade) ManagedInstances(ctx context.Context) error {
 	log := log.FromContext(ctx)
@@ -55,6 +77,10 @@ func (u VersionUpgrade) ManagedInstances(ctx context.Context) error {
 	for i := range list.Items {
 		original := list.Items[i]
 		itemLogger := log.WithValues("registry", klog.KObj(&original))
+		if original.Spec.UpdatePolicy != nil {
+			itemLogger.V(4).Info("Skipping upgrade for Registry instance following an update channel")
+			continue
+		}
 
 		upgraded, err := u.ManagedInstance(ctx, original)
 		if err != nil {
@@ -64,22 +90,12 @@ func (u VersionUpgrade) ManagedInstances(ctx context.Context) error {
 			u.Recorder.Event(&original, "Error", "Upgrade", msg)
 			continue
 		}
-		if !reflect.DeepEqual(upgraded, list.Items[i]) {
-			// the resource update overrides the status, so, keep it so that we can reset it later
-			st := upgraded.Status
-			patch := client.MergeFrom(&original)
-			if err := u.Client.Patch(ctx, &upgraded, patch); err != nil {
-				itemLogger.Error(err, "failed to apply changes to instance")
-				continue
-			}
-
-			// the status object requires its own update
-			upgraded.Status = st
-			if err := u.Client.Status().Patch(ctx, &upgraded, patch); err != nil {
-				itemLogger.Error(err, "failed to apply changes to instance's status object")
-				continue
-			}
-			itemLogger.Info("Instance upgraded", "version", upgraded.Status.Version)
+		if err := u.apply(ctx, &original, upgraded); err != nil {
+			itemLogger.Error(err, "failed to apply changes to instance")
+			continue
+		}
+		if original.Status.Version != list.Items[i].Status.Version {
+			itemLogger.Info("Instance upgraded", "version", original.Status.Version)
 		}
 	}
 
@@ -90,6 +106,42 @@ func (u VersionUpgrade) ManagedInstances(ctx context.Context) error {
 	return nil
 }
 
+// Upgrade brings the given registry instance to the current version and applies the changes, updating the
+// given instance in place.
+func (u VersionUpgrade) Upgrade(ctx context.Context, registry *registryv1alpha1.Registry) error {
+	upgraded, err := u.ManagedInstance(ctx, *registry)
+	if err != nil {
+		return err
+	}
+	return u.apply(ctx, registry, upgraded)
+}
+
+// apply patches the given original instance into the upgraded one, and updates it in place.
+func (u VersionUpgrade) apply(
+	ctx context.Context,
+	original *registryv1alpha1.Registry,
+	upgraded registryv1alpha1.Registry,
+) error {
+	if reflect.DeepEqual(upgraded, *original) {
+		return nil
+	}
+
+	// the resource update overrides the status, so, keep it so that we can reset it later
+	st := upgraded.Status
+	patch := client.MergeFrom(original)
+	if err := u.Client.Patch(ctx, &upgraded, patch); err != nil {
+		return fmt.Errorf("failed to apply changes to instance: %w", err)
+	}
+
+	// the status object requires its own update
+	upgraded.Status = st
+	if err := u.Client.Status().Patch(ctx, &upgraded, patch); err != nil {
+		return fmt.Errorf("failed to apply changes to instance's status object: %w", err)
+	}
+	*original = upgraded
+	return nil
+}
+
 // ManagedInstance performs the necessary changes to bring the given registry instance to the current version.
 func (u VersionUpgrade) ManagedInstance(
 	ctx context.Context,
@@ -114,6 +166,8 @@ func (u VersionUpgrade) ManagedInstance(
 	}
 
 	updated := *(registry.DeepCopy())
+	updated.Status.HeldUpgrade = nil
+	var changes []string
 	if instanceV.GreaterThan(&Latest.Version) {
 		log.V(4).Info(
 			"No upgrade routines are needed for the Registry instance",
@@ -127,46 +181,81 @@ func (u VersionUpgrade) ManagedInstance(
 			return updated, fmt.Errorf("%w: new version: %v", err, u.Version.Registry)
 		}
 
-		if instanceV.LessThan(registryV) {
-			log.Info(
-				"Upgraded Registry version",
-				"registry", klog.KObj(&updated),
-				"version", updated.Status.Version,
-			)
-			updated.Status.Version = u.Version.Registry
-		} else {
+		if !instanceV.LessThan(registryV) {
 			log.V(4).Info(
 				"Skipping upgrade for Registry instance",
 				"registry", klog.KObj(&updated),
 			)
+			return updated, nil
 		}
+	} else {
+		for _, available := range versions {
+			if available.GreaterThan(instanceV) {
+				upgraded, stepChanges, err := available.upgrade(u, &updated)
+				if err != nil {
+					log.Error(
+						err,
+						"Failed to upgrade managed registry instances",
+						"registry", klog.KObj(&updated),
+					)
+					return updated, err
+				}
 
-		return updated, nil
-	}
-
-	for _, available := range versions {
-		if available.GreaterThan(instanceV) {
-			upgraded, err := available.upgrade(u, &updated)
-			if err != nil {
-				log.Error(
-					err,
-					"Failed to upgrade managed registry instances",
+				log.V(1).Info(
+					"Step upgrade",
 					"registry", klog.KObj(&updated),
+					"version", available.String(),
 				)
-				return updated, err
+				upgraded.Status.Version = available.String()
+				updated = *upgraded
+				changes = append(changes, stepChanges...)
 			}
-
-			log.V(1).Info(
-				"Step upgrade",
-				"registry", klog.KObj(&updated),
-				"version", available.String(),
-			)
-			upgraded.Status.Version = available.String()
-			updated = *upgraded
 		}
 	}
 	// Update with the latest known version, which is what we have from values.yaml
 	updated.Status.Version = u.Version.Registry
+	if updated.Status.Version == registry.Status.Version && len(changes) == 0 {
+		return updated, nil
+	}
+
+	from, to := registry.Status.Version, updated.Status.Version
+	dryRun := registry.Annotations[registryv1alpha1.UpgradeDryRunAnnotation] == "true"
+	if dryRun || registry.Annotations[registryv1alpha1.UpgradeHoldAnnotation] == "true" {
+		// the held upgrade is recorded, so that it is only reported once
+		if held := registry.Status.HeldUpgrade; held != nil && held.From == from && held.To == to {
+			return registry, nil
+		}
+		if dryRun {
+			planned := "would not change the spec"
+			if len(changes) > 0 {
+				planned = "would apply: " + strings.Join(changes, "; ")
+			}
+			u.Recorder.Eventf(&registry, corev1.EventTypeNormal, reasonUpgradeDryRun,
+				"Upgrading from %s to %s %s", from, to, planned)
+		} else {
+			u.Recorder.Eventf(&registry, corev1.EventTypeNormal, reasonUpgradeHeld,
+				"Upgrade from %s to %s is held by the %s annotation", from, to, registryv1alpha1.UpgradeHoldAnnotation)
+		}
+		held := registry.DeepCopy()
+		held.Status.HeldUpgrade = &registryv1alpha1.UpgradeRecord{
+			From:    from,
+			To:      to,
+			Time:    metav1.Now(),
+			Changes: changes,
+		}
+		return *held, nil
+	}
+
+	updated.Status.UpgradeHistory = append(updated.Status.UpgradeHistory, registryv1alpha1.UpgradeRecord{
+		From:    from,
+		To:      to,
+		Time:    metav1.Now(),
+		Changes: changes,
+	})
+	if excess := len(updated.Status.UpgradeHistory) - maxUpgradeHistory; excess > 0 {
+		updated.Status.UpgradeHistory = updated.Status.UpgradeHistory[excess:]
+	}
+	log.Info("Upgraded Registry version", "registry", klog.KObj(&updated), "from", from, "to", to)
 
 	log.V(1).Info("Final version", "name", updated.Name, "namespace", updated.Namespace, "version", updated.Status.Version)
 	return updated, nil
@@ -19,17 +19,22 @@ package registry_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
 	"github.com/registry-operator/registry-operator/internal/upgrade/registry"
 	"github.com/registry-operator/registry-operator/internal/version"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestVersionsShouldNotBeChanged(t *testing.T) {
@@ -81,3 +86,145 @@ func makeRegistry(nsn types.NamespacedName) registryv1alpha1.Registry {
 		},
 	}
 }
+
+func TestUpgradeHistory(t *testing.T) {
+	nsn := types.NamespacedName{Name: "my-instance", Namespace: "default"}
+	currentV := version.Get()
+	currentV.Registry = "3.0.0"
+
+	t.Run("should record the upgrade", func(t *testing.T) {
+		existing := makeRegistry(nsn)
+		existing.Status.Version = "2.8.3"
+		for i := range 10 {
+			existing.Status.UpgradeHistory = append(existing.Status.UpgradeHistory, registryv1alpha1.UpgradeRecord{
+				From: fmt.Sprintf("2.%d.0", i),
+				To:   fmt.Sprintf("2.%d.0", i+1),
+			})
+		}
+
+		up := &registry.VersionUpgrade{
+			Version:  currentV,
+			Client:   k8sClient,
+			Recorder: record.NewFakeRecorder(registry.RecordBufferSize),
+		}
+		res, err := up.ManagedInstance(context.Background(), existing)
+		require.NoError(t, err)
+
+		require.Len(t, res.Status.UpgradeHistory, 10)
+		assert.Equal(t, "2.1.0", res.Status.UpgradeHistory[0].From)
+		last := res.Status.UpgradeHistory[9]
+		assert.Equal(t, "2.8.3", last.From)
+		assert.Equal(t, "3.0.0", last.To)
+		assert.False(t, last.Time.IsZero())
+	})
+
+	for _, tt := range []struct {
+		annotation string
+		event      string
+	}{
+		{registryv1alpha1.UpgradeHoldAnnotation, "Upgrade from 2.8.3 to 3.0.0 is held"},
+		{registryv1alpha1.UpgradeDryRunAnnotation, "Upgrading from 2.8.3 to 3.0.0 would not change the spec"},
+	} {
+		t.Run("should not upgrade with the "+tt.annotation+" annotation", func(t *testing.T) {
+			existing := makeRegistry(nsn)
+			existing.Annotations = map[string]string{tt.annotation: "true"}
+			existing.Spec.Image = "registry:2.8.3"
+			existing.Status.Version = "2.8.3"
+
+			recorder := record.NewFakeRecorder(registry.RecordBufferSize)
+			up := &registry.VersionUpgrade{Version: currentV, Client: k8sClient, Recorder: recorder}
+			res, err := up.ManagedInstance(context.Background(), existing)
+			require.NoError(t, err)
+			assert.Equal(t, existing.Spec, res.Spec)
+			assert.Equal(t, "2.8.3", res.Status.Version)
+			assert.Empty(t, res.Status.UpgradeHistory)
+			require.NotNil(t, res.Status.HeldUpgrade)
+			assert.Equal(t, "2.8.3", res.Status.HeldUpgrade.From)
+			assert.Equal(t, "3.0.0", res.Status.HeldUpgrade.To)
+
+			require.Len(t, recorder.Events, 1)
+			assert.Contains(t, <-recorder.Events, tt.event)
+
+			// the held upgrade is only reported once
+			again, err := up.ManagedInstance(context.Background(), res)
+			require.NoError(t, err)
+			assert.Equal(t, res, again)
+			assert.Empty(t, recorder.Events)
+
+			// the record is cleared once the upgrade is applied
+			delete(res.Annotations, tt.annotation)
+			upgraded, err := up.ManagedInstance(context.Background(), res)
+			require.NoError(t, err)
+			assert.Nil(t, upgraded.Status.HeldUpgrade)
+			assert.Equal(t, "3.0.0", upgraded.Status.Version)
+		})
+	}
+}
+
+func TestUpgradeDryRunChanges(t *testing.T) {
+	existing := makeRegistry(types.NamespacedName{Name: "my-instance", Namespace: "default"})
+	existing.Annotations = map[string]string{registryv1alpha1.UpgradeDryRunAnnotation: "true"}
+	existing.Spec.Storage.S3 = &registryv1alpha1.S3StorageSource{
+		EndpointURL: &registryv1alpha1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "s3"},
+			Key:                  "endpoint",
+		},
+	}
+	existing.Status.Version = "2.8.3"
+
+	currentV := version.Get()
+	currentV.Registry = "3.0.0"
+	recorder := record.NewFakeRecorder(registry.RecordBufferSize)
+	up := &registry.VersionUpgrade{Version: currentV, Client: k8sClient, Recorder: recorder}
+	res, err := up.ManagedInstance(context.Background(), existing)
+	require.NoError(t, err)
+
+	assert.Equal(t, existing.Spec, res.Spec, "the planned changes are not applied")
+	require.NotNil(t, res.Status.HeldUpgrade)
+	require.Len(t, res.Status.HeldUpgrade.Changes, 1)
+	assert.Contains(t, res.Status.HeldUpgrade.Changes[0], "spec.storage.s3.forcePathStyle")
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Upgrading from 2.8.3 to 3.0.0 would apply: spec.storage.s3.forcePathStyle")
+}
+
+func TestUpgrade(t *testing.T) {
+	existing := makeRegistry(types.NamespacedName{Name: "my-upgraded-instance", Namespace: "default"})
+	existing.Spec.Image = "registry:2.8.3"
+	existing.Status.Version = "2.8.3"
+	cli := fake.NewClientBuilder().
+		WithScheme(testScheme).
+		WithStatusSubresource(&registryv1alpha1.Registry{}).
+		WithObjects(&existing).
+		Build()
+
+	currentV := version.Get()
+	currentV.Registry = "3.0.0"
+	up := &registry.VersionUpgrade{
+		Version:  currentV,
+		Client:   cli,
+		Recorder: record.NewFakeRecorder(registry.RecordBufferSize),
+	}
+
+	instance := &registryv1alpha1.Registry{}
+	require.NoError(t, cli.Get(context.Background(), client.ObjectKeyFromObject(&existing), instance))
+	require.NoError(t, up.Upgrade(context.Background(), instance))
+	assert.Equal(t, "registry:2.8.3", instance.Spec.Image)
+
+	stored := &registryv1alpha1.Registry{}
+	require.NoError(t, cli.Get(context.Background(), client.ObjectKeyFromObject(&existing), stored))
+	assert.Equal(t, "registry:2.8.3", stored.Spec.Image)
+	assert.Equal(t, "3.0.0", stored.Status.Version)
+	assert.Len(t, stored.Status.UpgradeHistory, 1)
+}
+
+func TestHeldImage(t *testing.T) {
+	existing := makeRegistry(types.NamespacedName{Name: "my-instance", Namespace: "default"})
+	existing.Status.Version = "2.8.3"
+	assert.Empty(t, registry.HeldImage(existing))
+
+	existing.Annotations = map[string]string{registryv1alpha1.UpgradeHoldAnnotation: "true"}
+	assert.Equal(t, "docker.io/library/registry:2.8.3", registry.HeldImage(existing))
+
+	existing.Spec.Image = "registry:2.8.3"
+	assert.Empty(t, registry.HeldImage(existing))
+}
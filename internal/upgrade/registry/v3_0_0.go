@@ -18,9 +18,58 @@
 package registry
 
 import (
+	semver "github.com/Masterminds/semver/v3"
+	"github.com/distribution/reference"
+
 	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/version"
+
+	"k8s.io/utils/ptr"
 )
 
-func upgrade3_0_0(u VersionUpgrade, registry *registryv1alpha1.Registry) (*registryv1alpha1.Registry, error) {
-	return registry, nil
+// upgrade3_0_0 moves the Registries from the 2.x releases of the distribution to 3.0.0. A Registry left to the
+// default image follows it to 3.0.0. The image pinned by the users is kept, a 2.x tag of the default repository
+// being reported by the ImageSupported condition.
+//
+// The 2.x releases addressed the bucket in the path of the requests sent to an S3 endpoint URL, which 3.0.0 only
+// does with the forcepathstyle parameter, so it is set for the Registries relying on it.
+func upgrade3_0_0(_ VersionUpgrade, registry *registryv1alpha1.Registry) (*registryv1alpha1.Registry, []string, error) {
+	var changes []string
+	if s3 := registry.Spec.Storage.S3; s3 != nil && s3.EndpointURL != nil && s3.ForcePathStyle == nil {
+		s3.ForcePathStyle = ptr.To(true)
+		changes = append(changes, "spec.storage.s3.forcePathStyle was set to true, "+
+			"keeping the bucket in the path of the requests sent to the endpoint URL")
+	}
+	return registry, changes, nil
+}
+
+// IgnoresConfiguration reports whether the given image is a 2.x tag of the default repository. The 2.x images
+// read their configuration from /etc/docker/registry rather than the one generated by the operator.
+func IgnoresConfiguration(image string) bool {
+	major, ok := defaultRepositoryMajor(image)
+	return ok && major == 2
+}
+
+// defaultRepositoryMajor returns the major version of the given image when it is a tag of the default repository.
+func defaultRepositoryMajor(image string) (uint64, bool) {
+	if image == "" {
+		return 0, false
+	}
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return 0, false
+	}
+	defaultNamed, err := reference.ParseNormalizedNamed(version.GetRegistryRepository())
+	if err != nil || named.Name() != defaultNamed.Name() {
+		return 0, false
+	}
+	tagged, ok := named.(reference.Tagged)
+	if !ok {
+		return 0, false
+	}
+	v, err := semver.NewVersion(tagged.Tag())
+	if err != nil {
+		return 0, false
+	}
+	return v.Major(), true
 }
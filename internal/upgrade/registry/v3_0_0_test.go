@@ -19,16 +19,21 @@ package registry_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
 	"github.com/registry-operator/registry-operator/internal/upgrade/registry"
 	"github.com/registry-operator/registry-operator/internal/version"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 )
 
 func TestV3_0_0(t *testing.T) {
@@ -57,3 +62,93 @@ func TestV3_0_0(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, registryInstance, reg)
 }
+
+func TestV3_0_0FromV2(t *testing.T) {
+	registryInstance := makeRegistry(types.NamespacedName{Name: "my-instance", Namespace: "default"})
+	registryInstance.Spec.Image = "registry:2.8.3"
+	registryInstance.Status.Version = "2.8.3"
+
+	currentV := version.Get()
+	currentV.Registry = "3.0.0"
+	versionUpgrade := &registry.VersionUpgrade{
+		Version:  currentV,
+		Client:   k8sClient,
+		Recorder: record.NewFakeRecorder(registry.RecordBufferSize),
+	}
+
+	reg, err := versionUpgrade.ManagedInstance(context.Background(), registryInstance)
+	require.NoError(t, err)
+	assert.Equal(t, "registry:2.8.3", reg.Spec.Image, "the image pinned by the users is kept")
+	assert.Equal(t, "3.0.0", reg.Status.Version)
+	require.Len(t, reg.Status.UpgradeHistory, 1)
+	assert.Empty(t, reg.Status.UpgradeHistory[0].Changes)
+}
+
+func TestV3_0_0ForcePathStyle(t *testing.T) {
+	currentV := version.Get()
+	currentV.Registry = "3.0.0"
+	versionUpgrade := &registry.VersionUpgrade{
+		Version:  currentV,
+		Client:   k8sClient,
+		Recorder: record.NewFakeRecorder(registry.RecordBufferSize),
+	}
+	selector := func(key string) *registryv1alpha1.SecretKeySelector {
+		return &registryv1alpha1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "s3"},
+			Key:                  key,
+		}
+	}
+
+	for _, tt := range []struct {
+		desc     string
+		s3       registryv1alpha1.S3StorageSource
+		expected *bool
+		changed  bool
+	}{
+		{
+			desc:     "sets it with an endpoint URL",
+			s3:       registryv1alpha1.S3StorageSource{EndpointURL: selector("endpoint")},
+			expected: ptr.To(true),
+			changed:  true,
+		},
+		{
+			desc:     "keeps the value set by the users",
+			s3:       registryv1alpha1.S3StorageSource{EndpointURL: selector("endpoint"), ForcePathStyle: ptr.To(false)},
+			expected: ptr.To(false),
+		},
+		{
+			desc: "leaves the AWS endpoints to the default",
+			s3:   registryv1alpha1.S3StorageSource{},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			registryInstance := makeRegistry(types.NamespacedName{Name: "my-instance", Namespace: "default"})
+			registryInstance.Spec.Storage.S3 = &tt.s3
+			registryInstance.Status.Version = "2.8.3"
+
+			reg, err := versionUpgrade.ManagedInstance(context.Background(), registryInstance)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, reg.Spec.Storage.S3.ForcePathStyle)
+			require.Len(t, reg.Status.UpgradeHistory, 1)
+			assert.Equal(t, tt.changed, len(reg.Status.UpgradeHistory[0].Changes) > 0)
+		})
+	}
+}
+
+func TestIgnoresConfiguration(t *testing.T) {
+	for _, tt := range []struct {
+		image    string
+		expected bool
+	}{
+		{"registry:2.8.3", true},
+		{"docker.io/library/registry:2", true},
+		{"registry:3.0.0", false},
+		{"mirror.example.com/registry:2.8.3", false},
+		{"registry@sha256:" + strings.Repeat("a", 64), false},
+		{"", false},
+	} {
+		t.Run(tt.image, func(t *testing.T) {
+			assert.Equal(t, tt.expected, registry.IgnoresConfiguration(tt.image))
+		})
+	}
+}
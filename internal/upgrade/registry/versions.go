@@ -23,7 +23,11 @@ import (
 	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
 )
 
-type upgradeFunc func(u VersionUpgrade, registry *registryv1alpha1.Registry) (*registryv1alpha1.Registry, error)
+// upgradeFunc brings a Registry to a release, returning the descriptions of the changes it made to its spec.
+type upgradeFunc func(
+	u VersionUpgrade,
+	registry *registryv1alpha1.Registry,
+) (*registryv1alpha1.Registry, []string, error)
 
 type registryVersion struct {
 	upgrade upgradeFunc
@@ -52,3 +52,7 @@ func GetRegistryImage() string {
 func GetRegistryVersion() string {
 	return config.Registry.Image.Tag
 }
+
+func GetRegistryRepository() string {
+	return config.Registry.Image.Repository
+}
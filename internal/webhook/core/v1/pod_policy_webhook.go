@@ -0,0 +1,334 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/distribution/reference"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/naming"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//nolint:lll // kubebuilder directives
+// +kubebuilder:webhook:path=/validate--v1-pod,mutating=false,failurePolicy=fail,sideEffects=None,groups="",resources=pods;pods/ephemeralcontainers,verbs=create;update,versions=v1,name=vpod-v1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=imagepolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get
+
+// maxOwnerDepth bounds the controllers walked up from a Pod to a custom resource of the operator, e.g. the
+// ReplicaSet, Deployment and Registry of a Registry Pod.
+const maxOwnerDepth = 3
+
+// operatorWorkloads maps the kinds of the custom resources of the operator to the names of the workloads it
+// creates for them, by kind of workload.
+var operatorWorkloads = map[string]map[string]func(string) string{
+	registryv1alpha1.RegistryKind: {
+		"Deployment": naming.Registry,
+		"DaemonSet":  naming.NodeMirror,
+		"CronJob":    naming.Backup,
+		"Job":        naming.Migration,
+	},
+	registryv1alpha1.RegistryRestoreKind: {
+		"Job": naming.RestoreJob,
+	},
+}
+
+// PodImagePolicyValidator checks the images of the Pods against the ImagePolicies selecting their namespace,
+// warning about the violations of the policies in Audit mode and rejecting the ones in Enforce mode.
+type PodImagePolicyValidator struct {
+	Client client.Client
+
+	// APIReader reads the controllers of the Pods of the operator from the API, not to cache every workload.
+	APIReader client.Reader
+}
+
+var _ webhook.CustomValidator = &PodImagePolicyValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Pod.
+func (v *PodImagePolicyValidator) ValidateCreate(
+	ctx context.Context,
+	obj runtime.Object,
+) (admission.Warnings, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil, fmt.Errorf("expected a Pod object but got %T", obj)
+	}
+
+	return v.validate(ctx, pod)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Pod.
+func (v *PodImagePolicyValidator) ValidateUpdate(
+	ctx context.Context,
+	oldObj, newObj runtime.Object,
+) (admission.Warnings, error) {
+	pod, ok := newObj.(*corev1.Pod)
+	if !ok {
+		return nil, fmt.Errorf("expected a Pod object for the newObj but got %T", newObj)
+	}
+	old, ok := oldObj.(*corev1.Pod)
+	if !ok {
+		return nil, fmt.Errorf("expected a Pod object for the oldObj but got %T", oldObj)
+	}
+
+	// the Pods admitted before a policy are not to be stuck, e.g. on the removal of their finalizers
+	if slices.EqualFunc(podImages(old), podImages(pod), func(a, b podImage) bool {
+		return a.path.String() == b.path.String() && a.image == b.image
+	}) {
+		return nil, nil
+	}
+
+	return v.validate(ctx, pod)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Pod.
+func (v *PodImagePolicyValidator) ValidateDelete(
+	_ context.Context,
+	_ runtime.Object,
+) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *PodImagePolicyValidator) validate(ctx context.Context, pod *corev1.Pod) (admission.Warnings, error) {
+	log := ctrl.LoggerFrom(ctx).WithName("pod-resource")
+
+	ns := pod.Namespace
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.Namespace != "" {
+		ns = req.Namespace
+	}
+
+	policies := &registryv1alpha1.ImagePolicyList{}
+	if err := v.Client.List(ctx, policies); err != nil {
+		return nil, err
+	}
+	if len(policies.Items) == 0 {
+		return nil, nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := v.Client.Get(ctx, client.ObjectKey{Name: ns}, namespace); err != nil {
+		return nil, err
+	}
+	if namespace.Labels[registryv1alpha1.ImagePolicyNamespaceLabel] != registryv1alpha1.ImagePolicyNamespaceEnabled {
+		return nil, nil
+	}
+
+	selected := slices.DeleteFunc(policies.Items, func(policy registryv1alpha1.ImagePolicy) bool {
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.NamespaceSelector)
+		return err != nil || !selector.Matches(labels.Set(namespace.Labels))
+	})
+	if len(selected) == 0 {
+		return nil, nil
+	}
+
+	if exempt, err := v.isOperatorPod(ctx, ns, pod); err != nil || exempt {
+		return nil, err
+	}
+
+	registries := &registryv1alpha1.RegistryList{}
+	if err := v.Client.List(ctx, registries); err != nil {
+		return nil, err
+	}
+
+	var (
+		warnings admission.Warnings
+		allErrs  field.ErrorList
+	)
+	for _, policy := range selected {
+		hosts := allowedHosts(policy, registries.Items)
+		for _, image := range podImages(pod) {
+			if isAllowedImage(image.image, hosts) {
+				continue
+			}
+
+			msg := fmt.Sprintf("image %s is not pulled from a Registry allowed by the ImagePolicy %s",
+				image.image, policy.Name)
+			if policy.Spec.Mode == registryv1alpha1.ImagePolicyModeEnforce {
+				allErrs = append(allErrs, field.Forbidden(image.path, msg))
+			} else {
+				warnings = append(warnings, fmt.Sprintf("%s: %s", image.path, msg))
+			}
+		}
+	}
+
+	if len(allErrs) != 0 {
+		log.V(3).Info("Rejecting Pod violating an ImagePolicy", "namespace", ns, "name", pod.Name)
+		return warnings, apierrors.NewInvalid(corev1.SchemeGroupVersion.WithKind("Pod").GroupKind(), pod.Name, allErrs)
+	}
+	return warnings, nil
+}
+
+// isOperatorPod reports whether the given Pod is run by a workload the operator created for one of its custom
+// resources, which pulls its images from upstream. Its labels and owner references can be set by anyone, so
+// its controllers are read from the API up to the custom resource, checking their UIDs and the name of the
+// workload, and the Pod must only run the images of the workload.
+func (v *PodImagePolicyValidator) isOperatorPod(ctx context.Context, namespace string, pod *corev1.Pod) (bool, error) {
+	if !hasRegistryLabels(pod) {
+		return false, nil
+	}
+
+	var workload *metav1.OwnerReference
+	ref := metav1.GetControllerOf(pod)
+	for range maxOwnerDepth {
+		if ref == nil {
+			return false, nil
+		}
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			return false, nil
+		}
+
+		obj := &metav1.PartialObjectMetadata{}
+		obj.SetGroupVersionKind(gv.WithKind(ref.Kind))
+		if err := v.APIReader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, obj); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		if obj.UID != ref.UID {
+			return false, nil
+		}
+
+		if gv.Group == registryv1alpha1.GroupVersion.Group {
+			name := operatorWorkloads[ref.Kind][workloadKind(workload)]
+			if name == nil || workload.Name != name(ref.Name) {
+				return false, nil
+			}
+			images, err := v.templateImages(ctx, namespace, workload)
+			if err != nil {
+				return false, client.IgnoreNotFound(err)
+			}
+			return !slices.ContainsFunc(podImages(pod), func(image podImage) bool {
+				return !images.Has(image.image)
+			}), nil
+		}
+		workload, ref = ref, metav1.GetControllerOf(obj)
+	}
+	return false, nil
+}
+
+// workloadKind returns the kind of the given workload, or an empty string.
+func workloadKind(workload *metav1.OwnerReference) string {
+	if workload == nil {
+		return ""
+	}
+	return workload.Kind
+}
+
+// templateImages returns the images of the Pod template of the given workload.
+func (v *PodImagePolicyValidator) templateImages(
+	ctx context.Context,
+	namespace string,
+	workload *metav1.OwnerReference,
+) (sets.Set[string], error) {
+	key := client.ObjectKey{Namespace: namespace, Name: workload.Name}
+	var spec corev1.PodSpec
+	switch workload.Kind {
+	case "Deployment":
+		obj := &appsv1.Deployment{}
+		if err := v.APIReader.Get(ctx, key, obj); err != nil {
+			return nil, err
+		}
+		spec = obj.Spec.Template.Spec
+	case "DaemonSet":
+		obj := &appsv1.DaemonSet{}
+		if err := v.APIReader.Get(ctx, key, obj); err != nil {
+			return nil, err
+		}
+		spec = obj.Spec.Template.Spec
+	case "Job":
+		obj := &batchv1.Job{}
+		if err := v.APIReader.Get(ctx, key, obj); err != nil {
+			return nil, err
+		}
+		spec = obj.Spec.Template.Spec
+	case "CronJob":
+		obj := &batchv1.CronJob{}
+		if err := v.APIReader.Get(ctx, key, obj); err != nil {
+			return nil, err
+		}
+		spec = obj.Spec.JobTemplate.Spec.Template.Spec
+	}
+
+	images := sets.New[string]()
+	for _, c := range slices.Concat(spec.InitContainers, spec.Containers) {
+		images.Insert(c.Image)
+	}
+	return images, nil
+}
+
+// allowedHosts returns the endpoints of the Registries allowed by the given policy.
+func allowedHosts(policy registryv1alpha1.ImagePolicy, registries []registryv1alpha1.Registry) sets.Set[string] {
+	hosts := sets.New[string]()
+	for _, reg := range registries {
+		allowed := len(policy.Spec.Registries) == 0 || slices.Contains(policy.Spec.Registries,
+			registryv1alpha1.RegistryReference{Namespace: reg.Namespace, Name: reg.Name})
+		if !allowed {
+			continue
+		}
+		for _, endpoint := range reg.Status.Endpoints {
+			hosts.Insert(endpoint.Address)
+		}
+	}
+	return hosts
+}
+
+// podImage is the image of a container of a Pod, with the path of its field.
+type podImage struct {
+	path  *field.Path
+	image string
+}
+
+// podImages returns the images of the containers of the given Pod.
+func podImages(pod *corev1.Pod) []podImage {
+	images := []podImage{}
+	spec := field.NewPath("spec")
+	for i, c := range pod.Spec.InitContainers {
+		images = append(images, podImage{spec.Child("initContainers").Index(i).Child("image"), c.Image})
+	}
+	for i, c := range pod.Spec.Containers {
+		images = append(images, podImage{spec.Child("containers").Index(i).Child("image"), c.Image})
+	}
+	for i, c := range pod.Spec.EphemeralContainers {
+		images = append(images, podImage{spec.Child("ephemeralContainers").Index(i).Child("image"), c.Image})
+	}
+	return images
+}
+
+// isAllowedImage reports whether the given image is pulled from one of the given hosts.
+// Invalid image references are never allowed.
+func isAllowedImage(image string, hosts sets.Set[string]) bool {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return false
+	}
+	return hosts.Has(reference.Domain(named))
+}
@@ -0,0 +1,228 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var registryGVK = registryv1alpha1.GroupVersion.WithKind(registryv1alpha1.RegistryKind)
+
+func TestPodImagePolicyValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, registryv1alpha1.AddToScheme(scheme))
+
+	registry := func(namespace, address string) *registryv1alpha1.Registry {
+		return &registryv1alpha1.Registry{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: namespace},
+			Status: registryv1alpha1.RegistryStatus{
+				Endpoints: []registryv1alpha1.Endpoint{
+					{Type: registryv1alpha1.EndpointTypeClusterIP, Address: address},
+				},
+			},
+		}
+	}
+	policy := func(name, level string, mode registryv1alpha1.ImagePolicyMode) *registryv1alpha1.ImagePolicy {
+		return &registryv1alpha1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: registryv1alpha1.ImagePolicySpec{
+				NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"level": level}},
+				Registries:        []registryv1alpha1.RegistryReference{{Namespace: "team-a", Name: "registry"}},
+				Mode:              mode,
+			},
+		}
+	}
+	namespace := func(name, level string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"level": level,
+				registryv1alpha1.ImagePolicyNamespaceLabel: registryv1alpha1.ImagePolicyNamespaceEnabled,
+			},
+		}}
+	}
+
+	// the Deployment of a Registry in an enforced namespace, and its ReplicaSet
+	owner := registry("enforced", "registry.enforced.svc:5000")
+	owner.UID = "registry-uid"
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "registry-registry",
+			Namespace:       "enforced",
+			UID:             "deployment-uid",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(owner, registryGVK)},
+		},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "distribution", Image: "registry:3"}},
+		}}},
+	}
+	impostor := deployment.DeepCopy()
+	impostor.Name, impostor.UID = "impostor", "impostor-uid"
+	replicaSet := func(d *appsv1.Deployment) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:            d.Name + "-5d4f8",
+			Namespace:       "enforced",
+			UID:             d.UID + "-rs",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(d, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		}}
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			owner, deployment, replicaSet(deployment), impostor, replicaSet(impostor),
+			registry("team-a", "registry.team-a.svc:5000"),
+			registry("team-b", "registry.team-b.svc:5000"),
+			policy("audit", "audited", registryv1alpha1.ImagePolicyModeAudit),
+			policy("enforce", "enforced", registryv1alpha1.ImagePolicyModeEnforce),
+			namespace("audited", "audited"),
+			namespace("enforced", "enforced"),
+			namespace("free", "none"),
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "unlabeled",
+				Labels: map[string]string{"level": "enforced"},
+			}},
+		).
+		Build()
+	validator := &PodImagePolicyValidator{Client: cli, APIReader: cli}
+
+	pod := func(namespace string, images ...string) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: namespace}}
+		for _, image := range images {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Image: image})
+		}
+		return p
+	}
+
+	t.Run("admits the images of the allowed Registries", func(t *testing.T) {
+		warnings, err := validator.ValidateCreate(t.Context(), pod("enforced", "registry.team-a.svc:5000/app:1"))
+		assert.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("rejects other images in Enforce mode", func(t *testing.T) {
+		_, err := validator.ValidateCreate(t.Context(), pod("enforced",
+			"registry.team-a.svc:5000/app:1",
+			"registry.team-b.svc:5000/app:1",
+			"nginx:1",
+		))
+		require.Error(t, err)
+		assert.True(t, apierrors.IsInvalid(err))
+		assert.Contains(t, err.Error(), "spec.containers[1].image")
+		assert.Contains(t, err.Error(), "spec.containers[2].image")
+		assert.NotContains(t, err.Error(), "spec.containers[0].image")
+	})
+
+	t.Run("warns about other images in Audit mode", func(t *testing.T) {
+		warnings, err := validator.ValidateCreate(t.Context(), pod("audited", "nginx:1"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"spec.containers[0].image: image nginx:1 is not pulled from a Registry allowed by the ImagePolicy audit",
+		}, []string(warnings))
+	})
+
+	t.Run("ignores the namespaces not selected", func(t *testing.T) {
+		warnings, err := validator.ValidateCreate(t.Context(), pod("free", "nginx:1"))
+		assert.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("ignores the namespaces not opted in", func(t *testing.T) {
+		warnings, err := validator.ValidateCreate(t.Context(), pod("unlabeled", "nginx:1"))
+		assert.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("admits every Pod without ImagePolicy", func(t *testing.T) {
+		// nothing else is read, the namespace of the Pod being missing
+		validator := &PodImagePolicyValidator{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+		warnings, err := validator.ValidateCreate(t.Context(), pod("enforced", "nginx:1"))
+		assert.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	registryPod := func(rs *appsv1.ReplicaSet, images ...string) *corev1.Pod {
+		p := pod("enforced", images...)
+		p.Labels = map[string]string{managedByLabel: "registry-operator"}
+		if rs != nil {
+			p.OwnerReferences = []metav1.OwnerReference{
+				*metav1.NewControllerRef(rs, appsv1.SchemeGroupVersion.WithKind("ReplicaSet")),
+			}
+		}
+		return p
+	}
+
+	t.Run("exempts the Registry pods", func(t *testing.T) {
+		_, err := validator.ValidateCreate(t.Context(), registryPod(replicaSet(deployment), "registry:3"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("does not exempt Pods forging the Registry pods", func(t *testing.T) {
+		for name, p := range map[string]*corev1.Pod{
+			"labels only":              registryPod(nil, "registry:3"),
+			"other images":             registryPod(replicaSet(deployment), "registry:3", "nginx:1"),
+			"workload of another name": registryPod(replicaSet(impostor), "registry:3"),
+			"forged owner UID": func() *corev1.Pod {
+				p := registryPod(replicaSet(deployment), "registry:3")
+				p.OwnerReferences[0].UID = "forged"
+				return p
+			}(),
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := validator.ValidateCreate(t.Context(), p)
+				assert.Error(t, err)
+			})
+		}
+	})
+
+	t.Run("checks the ephemeral containers", func(t *testing.T) {
+		old := pod("enforced", "registry.team-a.svc:5000/app:1")
+		updated := old.DeepCopy()
+		updated.Spec.EphemeralContainers = []corev1.EphemeralContainer{
+			{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debug", Image: "busybox:1"}},
+		}
+
+		_, err := validator.ValidateUpdate(t.Context(), old, updated)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "spec.ephemeralContainers[0].image")
+	})
+
+	t.Run("admits updates keeping the images", func(t *testing.T) {
+		old := pod("enforced", "nginx:1")
+		updated := old.DeepCopy()
+		updated.Finalizers = nil
+
+		_, err := validator.ValidateUpdate(t.Context(), old, updated)
+		assert.NoError(t, err)
+
+		updated.Spec.Containers[0].Image = "nginx:2"
+		_, err = validator.ValidateUpdate(t.Context(), old, updated)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,216 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/distribution/reference"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/trust"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const podMutatePath = "/mutate--v1-pod"
+
+// managedByLabel marks the Pods of the Registries, which must pull their own image from upstream.
+const managedByLabel = "app.kubernetes.io/managed-by"
+
+// SetupPodWebhookWithManager registers the webhooks for Pod in the manager.
+func SetupPodWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(podMutatePath, &webhook.Admission{
+		Handler: &PodImageRewriter{
+			Client:  mgr.GetClient(),
+			decoder: admission.NewDecoder(mgr.GetScheme()),
+		},
+	})
+
+	return ctrl.NewWebhookManagedBy(mgr).For(&corev1.Pod{}).
+		WithValidator(&PodImagePolicyValidator{Client: mgr.GetClient(), APIReader: mgr.GetAPIReader()}).
+		Complete()
+}
+
+//nolint:lll // kubebuilder directives
+// +kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod-v1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=registry-operator.dev,resources=registries,verbs=get;list;watch
+
+// PodImageRewriter rewrites the images of the Pods created in the namespaces selected by the image rewrite
+// of a Registry running as a pull-through cache, so that they are pulled through the Registry.
+type PodImageRewriter struct {
+	Client  client.Client
+	decoder admission.Decoder
+}
+
+var _ admission.Handler = &PodImageRewriter{}
+
+// rewriteRule rewrites the images starting with prefix to the pull prefix of a Registry.
+type rewriteRule struct {
+	prefix     string
+	pullPrefix string
+	dryRun     bool
+}
+
+// Handle implements admission.Handler and patches the images of the Pod, or only records the rewrites
+// in its annotations in dry-run mode.
+func (m *PodImageRewriter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	log := ctrl.LoggerFrom(ctx).WithName("pod-resource")
+
+	pod := &corev1.Pod{}
+	if err := m.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if pod.Annotations[registryv1alpha1.ImageRewriteAnnotation] == registryv1alpha1.ImageRewriteDisabled {
+		return admission.Allowed("image rewriting disabled")
+	}
+	if hasRegistryLabels(pod) {
+		return admission.Allowed("Pod managed by the operator")
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := m.Client.Get(ctx, client.ObjectKey{Name: req.Namespace}, namespace); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	registries := &registryv1alpha1.RegistryList{}
+	if err := m.Client.List(ctx, registries); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	rules := rewriteRules(registries.Items, namespace)
+	if len(rules) == 0 {
+		return admission.Allowed("")
+	}
+
+	rewrites := map[string]string{}
+	rewriteContainers := func(containers []corev1.Container) {
+		for i := range containers {
+			image, rule := rewriteImage(containers[i].Image, rules)
+			if rule == nil {
+				continue
+			}
+			rewrites[containers[i].Image] = image
+			if !rule.dryRun {
+				containers[i].Image = image
+			}
+		}
+	}
+	rewriteContainers(pod.Spec.InitContainers)
+	rewriteContainers(pod.Spec.Containers)
+	if len(rewrites) == 0 {
+		return admission.Allowed("")
+	}
+
+	recorded, err := json.Marshal(rewrites)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[registryv1alpha1.ImageRewritesAnnotation] = string(recorded)
+
+	log.V(3).Info("Rewriting Pod images", "namespace", req.Namespace, "rewrites", rewrites)
+
+	marshaled, err := json.Marshal(pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// rewriteRules returns the rules of the Registries rewriting the images of the Pods in the given namespace,
+// the longest prefixes first. Registries the namespace does not trust, or without a pull prefix reachable from
+// the nodes, are skipped.
+func rewriteRules(registries []registryv1alpha1.Registry, namespace *corev1.Namespace) []rewriteRule {
+	slices.SortFunc(registries, func(a, b registryv1alpha1.Registry) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	rules := []rewriteRule{}
+	for _, reg := range registries {
+		if reg.Spec.Proxy == nil || reg.Spec.Proxy.ImageRewrite == nil ||
+			!isNodeReachable(&reg) || reg.GetDeletionTimestamp() != nil {
+			continue
+		}
+		rewrite := reg.Spec.Proxy.ImageRewrite
+
+		selector, err := metav1.LabelSelectorAsSelector(&rewrite.NamespaceSelector)
+		if err != nil || !selector.Matches(labels.Set(namespace.Labels)) || !trust.Trusts(namespace, &reg) {
+			continue
+		}
+
+		for _, prefix := range rewrite.Prefixes {
+			rules = append(rules, rewriteRule{
+				prefix:     strings.TrimSuffix(prefix, "/") + "/",
+				pullPrefix: reg.Status.PullPrefix,
+				dryRun:     rewrite.DryRun,
+			})
+		}
+	}
+
+	// the first Registry wins among equal prefixes
+	slices.SortStableFunc(rules, func(a, b rewriteRule) int {
+		return cmp.Compare(len(b.prefix), len(a.prefix))
+	})
+	return rules
+}
+
+// rewriteImage returns the given image rewritten by the first matching rule, and the rule.
+// The rule is nil when none matches the normalized image reference, e.g. docker.io/library/nginx:1 for nginx:1.
+// Only the host of the image is replaced by the pull prefix, the Registry mirroring the paths of its remote.
+func rewriteImage(image string, rules []rewriteRule) (string, *rewriteRule) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return image, nil
+	}
+	normalized := named.String()
+
+	for i := range rules {
+		if strings.HasPrefix(normalized, rules[i].prefix) {
+			rest := strings.TrimPrefix(normalized, reference.Domain(named)+"/")
+			return rules[i].pullPrefix + "/" + rest, &rules[i]
+		}
+	}
+	return image, nil
+}
+
+// isNodeReachable reports whether the pull prefix of the given Registry is an endpoint the container runtime of
+// the nodes can pull from. The in-cluster address of its Service cannot be resolved by the nodes.
+func isNodeReachable(registry *registryv1alpha1.Registry) bool {
+	return slices.ContainsFunc(registry.Status.Endpoints, func(endpoint registryv1alpha1.Endpoint) bool {
+		return endpoint.Address == registry.Status.PullPrefix && endpoint.Type != registryv1alpha1.EndpointTypeClusterIP
+	})
+}
+
+// hasRegistryLabels reports whether the given Pod carries the labels of the Pods of the Registries. The labels
+// can be set by anyone, a Pod opting out of the rewriting like with the image-rewrite annotation.
+func hasRegistryLabels(pod *corev1.Pod) bool {
+	return pod.Labels[managedByLabel] == "registry-operator"
+}
@@ -0,0 +1,209 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gomodules.xyz/jsonpatch/v2"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestRewriteImage(t *testing.T) {
+	rules := []rewriteRule{
+		{prefix: "docker.io/library/", pullPrefix: "library.example.com"},
+		{prefix: "docker.io/", pullPrefix: "hub.example.com"},
+		{prefix: "ghcr.io/", pullPrefix: "ghcr.example.com"},
+	}
+
+	for _, tc := range []struct {
+		image    string
+		expected string
+		matched  bool
+	}{
+		{image: "nginx", expected: "library.example.com/library/nginx", matched: true},
+		{image: "nginx:1.27", expected: "library.example.com/library/nginx:1.27", matched: true},
+		{image: "docker.io/bitnami/redis:7", expected: "hub.example.com/bitnami/redis:7", matched: true},
+		{
+			image:    "ghcr.io/org/app@sha256:" + digest,
+			expected: "ghcr.example.com/org/app@sha256:" + digest,
+			matched:  true,
+		},
+		{image: "quay.io/org/app:1", expected: "quay.io/org/app:1"},
+		{image: "Invalid:Image", expected: "Invalid:Image"},
+	} {
+		t.Run(tc.image, func(t *testing.T) {
+			actual, rule := rewriteImage(tc.image, rules)
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, tc.matched, rule != nil)
+		})
+	}
+}
+
+const digest = "0000000000000000000000000000000000000000000000000000000000000000"
+
+func TestPodImageRewriter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, registryv1alpha1.AddToScheme(scheme))
+
+	mirror := func(name string, dryRun bool) *registryv1alpha1.Registry {
+		return &registryv1alpha1.Registry{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "registry"},
+			Spec: registryv1alpha1.RegistrySpec{
+				Proxy: &registryv1alpha1.Proxy{
+					RemoteURL: "https://registry-1.docker.io",
+					ImageRewrite: &registryv1alpha1.ImageRewrite{
+						Prefixes: []string{"docker.io/"},
+						NamespaceSelector: metav1.LabelSelector{
+							MatchLabels: map[string]string{"mirror": name},
+						},
+						DryRun: dryRun,
+					},
+				},
+			},
+			Status: registryv1alpha1.RegistryStatus{
+				Endpoints: []registryv1alpha1.Endpoint{
+					{Type: registryv1alpha1.EndpointTypeClusterIP, Address: name + "-registry.registry.svc:5000"},
+					{Type: registryv1alpha1.EndpointTypeIngress, Address: name + ".example.com", TLS: true},
+				},
+				PullPrefix: name + ".example.com",
+			},
+		}
+	}
+	namespace := func(name, mirror string, trusted bool) *corev1.Namespace {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"mirror": mirror},
+		}}
+		if trusted {
+			ns.Annotations = map[string]string{registryv1alpha1.TrustedRegistriesAnnotation: "registry/*"}
+		}
+		return ns
+	}
+
+	internal := mirror("internal", false)
+	internal.Status.Endpoints = internal.Status.Endpoints[:1]
+	internal.Status.PullPrefix = internal.Status.Endpoints[0].Address
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			mirror("hub", false), mirror("dry", true), internal, namespace("team-e", "internal", true),
+			namespace("team-a", "hub", true), namespace("team-b", "dry", true), namespace("team-c", "none", true),
+			namespace("team-d", "hub", false),
+		).
+		Build()
+	rewriter := &PodImageRewriter{Client: cli, decoder: admission.NewDecoder(scheme)}
+
+	handle := func(t *testing.T, namespace string, pod *corev1.Pod) admission.Response {
+		t.Helper()
+		raw, err := json.Marshal(pod)
+		require.NoError(t, err)
+		resp := rewriter.Handle(t.Context(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Namespace: namespace,
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+		require.True(t, resp.Allowed, resp.Result)
+		return resp
+	}
+	pod := func() *corev1.Pod {
+		return &corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "app"},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init", Image: "busybox:1"}},
+				Containers: []corev1.Container{
+					{Name: "app", Image: "nginx:1.27"},
+					{Name: "sidecar", Image: "quay.io/org/sidecar:1"},
+				},
+			},
+		}
+	}
+	patched := func(t *testing.T, resp admission.Response) map[string]any {
+		t.Helper()
+		ops := map[string]any{}
+		for _, op := range resp.Patches {
+			ops[op.Path] = op.Value
+		}
+		return ops
+	}
+
+	t.Run("rewrites the images in selected namespaces", func(t *testing.T) {
+		resp := handle(t, "team-a", pod())
+
+		ops := patched(t, resp)
+		assert.Equal(t, "hub.example.com/library/busybox:1", ops["/spec/initContainers/0/image"])
+		assert.Equal(t, "hub.example.com/library/nginx:1.27", ops["/spec/containers/0/image"])
+		assert.NotContains(t, ops, "/spec/containers/1/image")
+		assert.Contains(t, ops, "/metadata/annotations")
+	})
+
+	t.Run("only annotates in dry-run mode", func(t *testing.T) {
+		resp := handle(t, "team-b", pod())
+
+		require.Len(t, resp.Patches, 1)
+		assert.Equal(t, jsonpatch.Operation{
+			Operation: "add",
+			Path:      "/metadata/annotations",
+			Value: map[string]any{
+				registryv1alpha1.ImageRewritesAnnotation: `{"busybox:1":"dry.example.com/library/busybox:1",` +
+					`"nginx:1.27":"dry.example.com/library/nginx:1.27"}`,
+			},
+		}, resp.Patches[0])
+	})
+
+	t.Run("leaves other namespaces alone", func(t *testing.T) {
+		resp := handle(t, "team-c", pod())
+		assert.Empty(t, resp.Patches)
+	})
+
+	t.Run("leaves the namespaces not trusting the Registry alone", func(t *testing.T) {
+		resp := handle(t, "team-d", pod())
+		assert.Empty(t, resp.Patches)
+	})
+
+	t.Run("skips the Registries only reachable inside the cluster", func(t *testing.T) {
+		resp := handle(t, "team-e", pod())
+		assert.Empty(t, resp.Patches)
+	})
+
+	t.Run("honours the opt-out annotation", func(t *testing.T) {
+		p := pod()
+		p.Annotations = map[string]string{registryv1alpha1.ImageRewriteAnnotation: registryv1alpha1.ImageRewriteDisabled}
+		resp := handle(t, "team-a", p)
+		assert.Empty(t, resp.Patches)
+	})
+
+	t.Run("leaves the Registry pods alone", func(t *testing.T) {
+		p := pod()
+		p.Labels = map[string]string{managedByLabel: "registry-operator"}
+		resp := handle(t, "team-a", p)
+		assert.Empty(t, resp.Patches)
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"context"
+	"net/http"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const registryScaleValidatePath = "/validate-registry-operator-dev-v1alpha1-registry-scale"
+
+//nolint:lll // kubebuilder directives
+// +kubebuilder:webhook:path=/validate-registry-operator-dev-v1alpha1-registry-scale,mutating=false,failurePolicy=ignore,sideEffects=None,groups=registry-operator.dev,resources=registries/scale,verbs=update,versions=v1alpha1,name=vregistryscale-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// RegistryScaleValidator validates updates of the scale subresource of a Registry, which bypass
+// the validation of the Registry resource itself, e.g. `kubectl scale` or a HorizontalPodAutoscaler.
+type RegistryScaleValidator struct {
+	Client  client.Client
+	decoder admission.Decoder
+}
+
+var _ admission.Handler = &RegistryScaleValidator{}
+
+// Handle implements admission.Handler and attaches the replicas warnings of the scaled Registry, rejecting
+// the replicas its storage cannot be shared between.
+func (v *RegistryScaleValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	log := ctrl.LoggerFrom(ctx).WithName("registry-resource")
+
+	scale := &autoscalingv1.Scale{}
+	if err := v.decoder.Decode(req, scale); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	log.V(3).Info("Validation for Registry upon scale", "name", req.Name, "replicas", scale.Spec.Replicas)
+
+	registry := &registryv1alpha1.Registry{}
+	if err := v.Client.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: req.Name}, registry); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	storage := effectiveRegistry(ctx, v.Client, registry).Spec.Storage
+	warnings := replicasWarnings(storage, scale.Spec.Replicas)
+	if err := replicasError(ctx, v.Client, req.Namespace, storage, scale.Spec.Replicas); err != nil {
+		return admission.Denied(err.Error()).WithWarnings(warnings...)
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 The Registry Operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestRegistryScaleValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, registryv1alpha1.AddToScheme(scheme))
+
+	request := func(t *testing.T, replicas int32) admission.Request {
+		raw, err := json.Marshal(&autoscalingv1.Scale{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "autoscaling/v1", Kind: "Scale"},
+			ObjectMeta: metav1.ObjectMeta{Name: "my-instance", Namespace: "my-namespace"},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: replicas},
+		})
+		require.NoError(t, err)
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Name:      "my-instance",
+			Namespace: "my-namespace",
+			Operation: admissionv1.Update,
+			Object:    runtime.RawExtension{Raw: raw},
+		}}
+	}
+	validator := func(storage registryv1alpha1.Storage) *RegistryScaleValidator {
+		registry := &registryv1alpha1.Registry{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-instance", Namespace: "my-namespace"},
+			Spec:       registryv1alpha1.RegistrySpec{Storage: storage},
+		}
+		return &RegistryScaleValidator{
+			Client:  fake.NewClientBuilder().WithScheme(scheme).WithObjects(registry).Build(),
+			decoder: admission.NewDecoder(scheme),
+		}
+	}
+
+	t.Run("should warn when scaling filesystem storage", func(t *testing.T) {
+		v := validator(registryv1alpha1.Storage{EmptyDir: &corev1.EmptyDirVolumeSource{}})
+
+		resp := v.Handle(t.Context(), request(t, 3))
+		assert.True(t, resp.Allowed)
+		assert.Len(t, resp.Warnings, 1)
+	})
+
+	t.Run("should not warn for single replica", func(t *testing.T) {
+		v := validator(registryv1alpha1.Storage{EmptyDir: &corev1.EmptyDirVolumeSource{}})
+
+		resp := v.Handle(t.Context(), request(t, 1))
+		assert.True(t, resp.Allowed)
+		assert.Empty(t, resp.Warnings)
+	})
+
+	t.Run("should not warn when scaling S3 storage", func(t *testing.T) {
+		v := validator(registryv1alpha1.Storage{S3: &registryv1alpha1.S3StorageSource{}})
+
+		resp := v.Handle(t.Context(), request(t, 3))
+		assert.True(t, resp.Allowed)
+		assert.Empty(t, resp.Warnings)
+	})
+
+	t.Run("should reject scaling hostPath storage", func(t *testing.T) {
+		v := validator(registryv1alpha1.Storage{HostPath: &corev1.HostPathVolumeSource{Path: "/var/lib/registry"}})
+
+		resp := v.Handle(t.Context(), request(t, 2))
+		assert.False(t, resp.Allowed)
+		assert.Len(t, resp.Warnings, 1)
+	})
+}
@@ -15,29 +15,52 @@
 package v1alpha1
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/robfig/cron/v3"
 
 	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+	"github.com/registry-operator/registry-operator/internal/naming"
+	"github.com/registry-operator/registry-operator/internal/registryclass"
+	"github.com/registry-operator/registry-operator/internal/registryclient"
 	"github.com/registry-operator/registry-operator/internal/webhook/validation"
 
+	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // SetupRegistryWebhookWithManager registers the webhook for Registry in the manager.
 func SetupRegistryWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(registryScaleValidatePath, &webhook.Admission{
+		Handler: &RegistryScaleValidator{
+			Client:  mgr.GetClient(),
+			decoder: admission.NewDecoder(mgr.GetScheme()),
+		},
+	})
+
 	return ctrl.NewWebhookManagedBy(mgr).For(&registryv1alpha1.Registry{}).
-		WithValidator(&RegistryCustomValidator{}).
-		WithDefaulter(&RegistryCustomDefaulter{}).
+		WithValidator(&RegistryCustomValidator{Client: mgr.GetClient()}).
+		WithDefaulter(&RegistryCustomDefaulter{Client: mgr.GetClient()}).
 		Complete()
 }
 
@@ -46,7 +69,9 @@ func SetupRegistryWebhookWithManager(mgr ctrl.Manager) error {
 
 // RegistryCustomDefaulter struct is responsible for setting default values on the custom resource of the
 // Kind Registry when those are created or updated.
-type RegistryCustomDefaulter struct{}
+type RegistryCustomDefaulter struct {
+	Client client.Client
+}
 
 var _ webhook.CustomDefaulter = &RegistryCustomDefaulter{}
 
@@ -64,7 +89,24 @@ func (d *RegistryCustomDefaulter) Default(
 
 	log.V(4).Info("Defaulting for Registry", "name", registry.GetName())
 
-	if validation.PopulatedFields(registry.Spec.Storage) == 0 {
+	// like StorageClasses, the default class is only assigned when the Registry is created
+	req, err := admission.RequestFromContext(ctx)
+	if err == nil && req.Operation == admissionv1.Create && registry.Spec.RegistryClassName == "" {
+		class, err := registryclass.Default(ctx, d.Client)
+		if err != nil {
+			return err
+		}
+		if class != nil {
+			registry.Spec.RegistryClassName = class.Name
+		}
+	}
+
+	if s3 := registry.Spec.Storage.S3; s3 != nil && s3.RootDirectory == "" && err == nil {
+		s3.RootDirectory = s3RootDirectory(req, registry)
+	}
+
+	// the storage of a Registry using a class may come from the class
+	if registry.Spec.RegistryClassName == "" && validation.PopulatedFields(registry.Spec.Storage) == 0 {
 		registry.Spec.Storage = registryv1alpha1.Storage{
 			EmptyDir: &corev1.EmptyDirVolumeSource{
 				SizeLimit: ptr.To(resource.MustParse("200Mi")),
@@ -75,12 +117,26 @@ func (d *RegistryCustomDefaulter) Default(
 	return nil
 }
 
+// s3RootDirectory returns the root directory of the S3 storage of the given Registry: its own directory when the
+// s3 storage is set, or the root directory it is already stored under, shared when it was stored in S3 before
+// the root directories existed.
+func s3RootDirectory(req admission.Request, registry *registryv1alpha1.Registry) string {
+	old := &registryv1alpha1.Registry{}
+	if req.Operation == admissionv1.Update && json.Unmarshal(req.OldObject.Raw, old) == nil &&
+		old.Spec.Storage.S3 != nil {
+		return old.Spec.Storage.S3.RootDirectory
+	}
+	return naming.S3RootDirectory(cmp.Or(registry.Namespace, req.Namespace), registry.Name)
+}
+
 //nolint:lll // kubebuilder directives
 // +kubebuilder:webhook:path=/validate-registry-operator-dev-v1alpha1-registry,mutating=false,failurePolicy=fail,sideEffects=None,groups=registry-operator.dev,resources=registries,verbs=create;update,versions=v1alpha1,name=vregistry-v1alpha1.kb.io,admissionReviewVersions=v1
 
 // RegistryCustomValidator struct is responsible for validating the Registry resource
 // when it is created, updated, or deleted.
-type RegistryCustomValidator struct{}
+type RegistryCustomValidator struct {
+	Client client.Client
+}
 
 var _ webhook.CustomValidator = &RegistryCustomValidator{}
 
@@ -98,7 +154,7 @@ func (v *RegistryCustomValidator) ValidateCreate(
 
 	log.V(3).Info("Validation for Registry upon creation", "name", registry.GetName())
 
-	return v.warn(registry), v.validate(registry)
+	return v.warn(ctx, registry), v.validate(ctx, registry)
 }
 
 // ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Registry.
@@ -113,9 +169,31 @@ func (v *RegistryCustomValidator) ValidateUpdate(
 		return nil, fmt.Errorf("expected a Registry object for the newObj but got %T", newObj)
 	}
 
+	oldRegistry, ok := oldObj.(*registryv1alpha1.Registry)
+	if !ok {
+		return nil, fmt.Errorf("expected a Registry object for the oldObj but got %T", oldObj)
+	}
+
 	log.V(3).Info("Validation for Registry upon update", "name", newRegistry.GetName())
 
-	return v.warn(newRegistry), v.validate(newRegistry)
+	// the Registries admitted before a check was introduced must remain updatable, e.g. to remove their finalizer
+	if apiequality.Semantic.DeepEqual(oldRegistry.Spec, newRegistry.Spec) {
+		return v.warn(ctx, newRegistry), nil
+	}
+
+	oldEffective := effectiveRegistry(ctx, v.Client, oldRegistry)
+	if errs := validateStorageUpdate(oldEffective, effectiveRegistry(ctx, v.Client, newRegistry)); len(errs) != 0 {
+		return v.warn(ctx, newRegistry), apierrors.NewInvalid(
+			schema.GroupKind{
+				Group: registryv1alpha1.GroupVersion.Group,
+				Kind:  registryv1alpha1.RegistryKind,
+			},
+			newRegistry.Name,
+			errs,
+		)
+	}
+
+	return v.warn(ctx, newRegistry), v.validate(ctx, newRegistry)
 }
 
 // ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Registry.
@@ -135,8 +213,44 @@ func (v *RegistryCustomValidator) ValidateDelete(
 	return nil, nil
 }
 
-func (v *RegistryCustomValidator) validate(registry *registryv1alpha1.Registry) error {
+// effectiveRegistry returns the given Registry with the defaults of its RegistryClass applied, so that the storage
+// and resources taken from the class are validated as well. A missing class is reported by the reconciliation.
+func effectiveRegistry(
+	ctx context.Context,
+	cli client.Client,
+	registry *registryv1alpha1.Registry,
+) *registryv1alpha1.Registry {
+	if cli == nil {
+		return registry
+	}
+	class, err := registryclass.Get(ctx, cli, registry)
+	if err != nil {
+		ctrl.LoggerFrom(ctx).WithName("registry-resource").V(3).Info("Failed to get RegistryClass", "error", err)
+		return registry
+	}
+	effective := registryclass.Apply(*registry, class)
+	return &effective
+}
+
+func (v *RegistryCustomValidator) validate(ctx context.Context, registry *registryv1alpha1.Registry) error {
 	var allErrs field.ErrorList
+	spec := field.NewPath("spec")
+	effective := effectiveRegistry(ctx, v.Client, registry)
+
+	if image := registry.Spec.Image; image != "" {
+		if _, err := reference.ParseNormalizedNamed(image); err != nil {
+			allErrs = append(allErrs, field.Invalid(spec.Child("image"), image, err.Error()))
+		}
+	}
+
+	storage := effective.Spec.Storage
+	if err := replicasError(ctx, v.Client, registry.Namespace, storage, registry.Spec.Replicas); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	if res := effective.Spec.Resources; res != nil {
+		allErrs = append(allErrs, validateResources(res, spec.Child("resources"))...)
+	}
 
 	if !validation.HasAtMostOne(registry.Spec.Storage) {
 		err := field.Invalid(
@@ -147,6 +261,38 @@ func (v *RegistryCustomValidator) validate(registry *registryv1alpha1.Registry)
 		allErrs = append(allErrs, err)
 	}
 
+	if d := registry.Spec.PullSecretDistribution; d != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(
+			&d.NamespaceSelector,
+			metav1validation.LabelSelectorValidationOptions{},
+			field.NewPath("spec").Child("pullSecretDistribution", "namespaceSelector"),
+		)...)
+	}
+
+	if p := registry.Spec.Proxy; p != nil && p.ImageRewrite != nil {
+		allErrs = append(allErrs, validateImageRewrite(p, spec.Child("proxy", "imageRewrite"))...)
+	}
+
+	if a := registry.Spec.Authentication; a != nil && a.Enabled {
+		allErrs = append(allErrs, validateAuthentication(registry, spec.Child("authentication"))...)
+	}
+
+	if m := registry.Spec.NodeMirror; m != nil {
+		allErrs = append(allErrs, validateNodeMirror(m, registry.Spec.Proxy, spec.Child("nodeMirror"))...)
+	}
+
+	if b := registry.Spec.Backup; b != nil {
+		allErrs = append(allErrs, validateBackup(b, storage, spec.Child("backup"))...)
+	}
+
+	if s := registry.Spec.Snapshots; s != nil {
+		allErrs = append(allErrs, validateSnapshots(s, storage, spec.Child("snapshots"))...)
+	}
+
+	if u := registry.Spec.UpdatePolicy; u != nil {
+		allErrs = append(allErrs, validateUpdatePolicy(u, registry.Spec.Image, spec.Child("updatePolicy"))...)
+	}
+
 	if len(allErrs) != 0 {
 		return apierrors.NewInvalid(
 			schema.GroupKind{
@@ -160,10 +306,439 @@ func (v *RegistryCustomValidator) validate(registry *registryv1alpha1.Registry)
 	return nil
 }
 
-func (v *RegistryCustomValidator) warn(registry *registryv1alpha1.Registry) admission.Warnings {
+func (v *RegistryCustomValidator) warn(ctx context.Context, registry *registryv1alpha1.Registry) admission.Warnings {
+	warns := replicasWarnings(effectiveRegistry(ctx, v.Client, registry).Spec.Storage, registry.Spec.Replicas)
+	warns = append(warns, nodeMirrorWarnings(registry)...)
+	return append(warns, v.secretWarnings(ctx, registry)...)
+}
+
+// secretRef is a reference to a Secret, and optionally one of its keys, by the path of its field.
+type secretRef struct {
+	path *field.Path
+	name string
+	key  string
+}
+
+// secretWarnings warns about the referenced Secrets and keys which do not exist. They are not rejected,
+// since the Secrets may well be created right after the Registry.
+func (v *RegistryCustomValidator) secretWarnings(
+	ctx context.Context,
+	registry *registryv1alpha1.Registry,
+) admission.Warnings {
+	if v.Client == nil {
+		return nil
+	}
+	log := ctrl.LoggerFrom(ctx).WithName("registry-resource")
+
+	var refs []secretRef
+	addKeyRef := func(path *field.Path, ref *registryv1alpha1.SecretKeySelector) {
+		if ref != nil {
+			refs = append(refs, secretRef{path: path, name: ref.Name, key: ref.Key})
+		}
+	}
+	spec := field.NewPath("spec")
+	if s3 := registry.Spec.Storage.S3; s3 != nil {
+		path := spec.Child("storage", "s3")
+		addKeyRef(path.Child("bucketName"), &s3.BucketName)
+		addKeyRef(path.Child("region"), &s3.Region)
+		addKeyRef(path.Child("accessKey"), s3.AccessKey)
+		addKeyRef(path.Child("secretKey"), s3.SecretKey)
+		addKeyRef(path.Child("endpointURL"), s3.EndpointURL)
+	}
+	if proxy := registry.Spec.Proxy; proxy != nil {
+		addKeyRef(spec.Child("proxy", "username"), proxy.Username)
+		addKeyRef(spec.Child("proxy", "password"), proxy.Password)
+	}
+	if b := registry.Spec.Backup; b != nil {
+		path := spec.Child("backup", "destination")
+		addKeyRef(path.Child("bucketName"), &b.Destination.BucketName)
+		addKeyRef(path.Child("region"), &b.Destination.Region)
+		addKeyRef(path.Child("accessKey"), b.Destination.AccessKey)
+		addKeyRef(path.Child("secretKey"), b.Destination.SecretKey)
+		addKeyRef(path.Child("endpointURL"), b.Destination.EndpointURL)
+	}
+	if d := registry.Spec.PullSecretDistribution; d != nil {
+		refs = append(refs, secretRef{path: spec.Child("pullSecretDistribution", "secretRef"), name: d.SecretRef.Name})
+	}
+
+	var warns admission.Warnings
+	secrets := map[string]*corev1.Secret{}
+	for _, ref := range refs {
+		secret, ok := secrets[ref.name]
+		if !ok {
+			secret = &corev1.Secret{}
+			key := client.ObjectKey{Namespace: registry.Namespace, Name: ref.name}
+			if err := v.Client.Get(ctx, key, secret); err != nil {
+				if !apierrors.IsNotFound(err) {
+					log.Error(err, "Failed to get referenced Secret", "secret", key)
+					continue
+				}
+				secret = nil
+			}
+			secrets[ref.name] = secret
+		}
+
+		switch {
+		case secret == nil:
+			warns = append(warns, fmt.Sprintf("%s: Secret %s does not exist", ref.path, ref.name))
+		case ref.key != "" && secret.Data[ref.key] == nil:
+			warns = append(warns, fmt.Sprintf("%s: Secret %s has no key %s", ref.path, ref.name, ref.key))
+		}
+	}
+
+	return warns
+}
+
+// replicasError rejects running multiple replicas on storage which cannot be mounted by all of them:
+// a hostPath is local to a node and a volume not ReadWriteMany is attached to a single node.
+func replicasError(
+	ctx context.Context,
+	cli client.Client,
+	namespace string,
+	storage registryv1alpha1.Storage,
+	replicas int32,
+) *field.Error {
+	if replicas <= 1 {
+		return nil
+	}
+	path := field.NewPath("spec").Child("replicas")
+
+	switch {
+	case storage.HostPath != nil:
+		return field.Invalid(path, replicas, "multiple replicas cannot share a hostPath storage")
+
+	case storage.PersistentVolumeClaimTemplate != nil:
+		if !slices.Contains(storage.PersistentVolumeClaimTemplate.AccessModes, corev1.ReadWriteMany) {
+			return field.Invalid(path, replicas, "multiple replicas require a ReadWriteMany persistentVolumeClaimTemplate")
+		}
+
+	case storage.PersistentVolumeClaim != nil && cli != nil:
+		pvc := &corev1.PersistentVolumeClaim{}
+		key := client.ObjectKey{Namespace: namespace, Name: storage.PersistentVolumeClaim.ClaimName}
+		if err := cli.Get(ctx, key, pvc); err != nil {
+			// the claim may be created later on, it is then checked on the next update
+			return nil
+		}
+		if !slices.Contains(pvc.Spec.AccessModes, corev1.ReadWriteMany) {
+			return field.Invalid(path, replicas,
+				fmt.Sprintf("multiple replicas require a ReadWriteMany PersistentVolumeClaim, %s is not", key.Name))
+		}
+	}
+
+	return nil
+}
+
+// validateStorageUpdate rejects the storage changes losing the images persisted by the Registry: switching away
+// from a persistent backend or moving its location, unless allowed by AllowStorageChangeAnnotation or migrated
+// with the Migrate strategy, and shrinking the claim template, which the PersistentVolumeClaims do not support.
+func validateStorageUpdate(oldRegistry, newRegistry *registryv1alpha1.Registry) field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec").Child("storage")
+	oldStorage, newStorage := oldRegistry.Spec.Storage, newRegistry.Spec.Storage
+
+	oldBackend, newBackend := storageBackend(oldStorage), storageBackend(newStorage)
+	// the annotation allows the change of a single generation, so that it cannot be left behind to allow any
+	generation := strconv.FormatInt(oldRegistry.Generation, 10)
+	allowed := newRegistry.Annotations[registryv1alpha1.AllowStorageChangeAnnotation] == generation
+	allow := fmt.Sprintf("set the %s: %q annotation to allow it", registryv1alpha1.AllowStorageChangeAnnotation,
+		generation)
+	migrated := newRegistry.Spec.StorageUpdateStrategy == registryv1alpha1.StorageUpdateStrategyMigrate &&
+		isMigration(oldBackend, newBackend)
+	switch {
+	case migrated:
+		if s3 := cmp.Or(newStorage.S3, oldStorage.S3); s3.AccessKey == nil || s3.SecretKey == nil {
+			allErrs = append(allErrs, field.Required(path.Child("s3"),
+				"the images can only be migrated with the accessKey and secretKey of the s3 storage"))
+		}
+	case allowed || !isPersistentBackend(oldBackend):
+	case oldBackend != newBackend:
+		allErrs = append(allErrs, field.Forbidden(path, fmt.Sprintf(
+			"switching the storage from %s to %s loses the stored images, %s",
+			oldBackend, cmp.Or(newBackend, "the default"), allow,
+		)))
+	default:
+		oldLocation, newLocation := storageLocation(oldStorage), storageLocation(newStorage)
+		for _, name := range slices.Sorted(maps.Keys(oldLocation)) {
+			if oldLocation[name] != newLocation[name] {
+				allErrs = append(allErrs, field.Forbidden(path.Child(newBackend, name), fmt.Sprintf(
+					"switching the %s from %q loses the stored images, %s", name, oldLocation[name], allow,
+				)))
+			}
+		}
+	}
+
+	if oldStorage.PersistentVolumeClaimTemplate != nil && newStorage.PersistentVolumeClaimTemplate != nil {
+		oldSize, oldOK := oldStorage.PersistentVolumeClaimTemplate.Resources.Requests[corev1.ResourceStorage]
+		newSize, newOK := newStorage.PersistentVolumeClaimTemplate.Resources.Requests[corev1.ResourceStorage]
+		if oldOK && (!newOK || newSize.Cmp(oldSize) < 0) {
+			allErrs = append(allErrs, field.Forbidden(
+				path.Child("persistentVolumeClaimTemplate", "resources", "requests").Key(string(corev1.ResourceStorage)),
+				fmt.Sprintf("cannot be decreased from %s to %s", oldSize.String(), newSize.String()),
+			))
+		}
+	}
+
+	return allErrs
+}
+
+// storageBackend returns the name of the storage backend set in the given Storage, or an empty string
+// when none is set.
+func storageBackend(storage registryv1alpha1.Storage) string {
+	switch {
+	case storage.EmptyDir != nil:
+		return "emptyDir"
+	case storage.Ephemeral != nil:
+		return "ephemeral"
+	case storage.HostPath != nil:
+		return "hostPath"
+	case storage.PersistentVolumeClaim != nil:
+		return "persistentVolumeClaim"
+	case storage.PersistentVolumeClaimTemplate != nil:
+		return "persistentVolumeClaimTemplate"
+	case storage.S3 != nil:
+		return "s3"
+	default:
+		return ""
+	}
+}
+
+// storageLocation returns the fields of the given storage locating the images it persists, by name. The values
+// of the S3 fields are read from Secrets, whose references are compared.
+func storageLocation(storage registryv1alpha1.Storage) map[string]string {
+	ref := func(selector *registryv1alpha1.SecretKeySelector) string {
+		if selector == nil {
+			return ""
+		}
+		return selector.Name + "/" + selector.Key
+	}
+
+	switch {
+	case storage.HostPath != nil:
+		return map[string]string{"path": storage.HostPath.Path}
+	case storage.PersistentVolumeClaim != nil:
+		return map[string]string{"claimName": storage.PersistentVolumeClaim.ClaimName}
+	case storage.S3 != nil:
+		return map[string]string{
+			"bucketName":    ref(&storage.S3.BucketName),
+			"region":        ref(&storage.S3.Region),
+			"endpointURL":   ref(storage.S3.EndpointURL),
+			"rootDirectory": storage.S3.RootDirectory,
+		}
+	default:
+		return nil
+	}
+}
+
+// isPersistentBackend reports whether the given storage backend keeps the images across the rollouts.
+func isPersistentBackend(backend string) bool {
+	return backend != "" && backend != "emptyDir" && backend != "ephemeral"
+}
+
+// isMigration reports whether the images can be migrated from the given storage backend to the other, that is
+// between a PersistentVolumeClaim and S3.
+func isMigration(oldBackend, newBackend string) bool {
+	isVolume := func(backend string) bool {
+		return backend == "persistentVolumeClaim" || backend == "persistentVolumeClaimTemplate"
+	}
+	return (isVolume(oldBackend) && newBackend == "s3") || (oldBackend == "s3" && isVolume(newBackend))
+}
+
+// validateImageRewrite checks the namespace selector of the image rewrite, and that its prefixes are on the
+// host of the remote registry, the Registry only serving the images of its remote.
+func validateImageRewrite(p *registryv1alpha1.Proxy, path *field.Path) field.ErrorList {
+	allErrs := metav1validation.ValidateLabelSelector(
+		&p.ImageRewrite.NamespaceSelector,
+		metav1validation.LabelSelectorValidationOptions{},
+		path.Child("namespaceSelector"),
+	)
+
+	for i, prefix := range p.ImageRewrite.Prefixes {
+		host, _, _ := strings.Cut(prefix, "/")
+		if !registryclient.SameRegistry(host, p.RemoteURL) {
+			allErrs = append(allErrs, field.Invalid(path.Child("prefixes").Index(i), prefix,
+				fmt.Sprintf("must start with the host of the remote registry %s", p.RemoteURL)))
+		}
+	}
+
+	return allErrs
+}
+
+// validateAuthentication rejects the authentication of the Registries pulled from by anonymous clients: the nodes
+// pulling through the node mirror, and the rewritten Pods unless the pull Secret is distributed to them.
+func validateAuthentication(registry *registryv1alpha1.Registry, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if registry.Spec.NodeMirror != nil {
+		allErrs = append(allErrs, field.Forbidden(path, "cannot be enabled with spec.nodeMirror"))
+	}
+	if p := registry.Spec.Proxy; p != nil && p.ImageRewrite != nil && registry.Spec.PullSecretDistribution == nil {
+		allErrs = append(allErrs, field.Forbidden(path,
+			"cannot be enabled with spec.proxy.imageRewrite without spec.pullSecretDistribution"))
+	}
+
+	return allErrs
+}
+
+// nodeMirrorConfigPaths are the directories the node mirror may write into on the nodes: the default
+// registry host configuration directories of containerd, k3s and RKE2.
+var nodeMirrorConfigPaths = []string{
+	"/etc/containerd/certs.d",
+	"/var/lib/rancher/k3s/agent/etc/containerd/certs.d",
+	"/var/lib/rancher/rke2/agent/etc/containerd/certs.d",
+}
+
+// validateNodeMirror checks that the node mirror writes into a containerd configuration directory, and that
+// it mirrors the remote registry of the proxy, the only images the Registry serves.
+func validateNodeMirror(m *registryv1alpha1.NodeMirror, p *registryv1alpha1.Proxy, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if m.ConfigPath != "" && !slices.Contains(nodeMirrorConfigPaths, m.ConfigPath) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("configPath"), m.ConfigPath, nodeMirrorConfigPaths))
+	}
+
+	if p == nil {
+		return append(allErrs, field.Required(field.NewPath("spec", "proxy"), "required by spec.nodeMirror"))
+	}
+	for i, host := range m.Hosts {
+		if !registryclient.SameRegistry(host, p.RemoteURL) {
+			allErrs = append(allErrs, field.Invalid(path.Child("hosts").Index(i), host,
+				fmt.Sprintf("must be the host of the remote registry %s", p.RemoteURL)))
+		}
+	}
+
+	return allErrs
+}
+
+// nodeMirrorWarnings warns when the NetworkPolicy of the Registry restricts the sources of the registry API,
+// the nodes pulling through the mirror from their own addresses being rejected unless they are listed.
+func nodeMirrorWarnings(registry *registryv1alpha1.Registry) admission.Warnings {
+	policy := registry.Spec.NetworkPolicy
+	if registry.Spec.NodeMirror == nil || policy == nil || !policy.Enabled || len(policy.From) == 0 {
+		return nil
+	}
+	return admission.Warnings{
+		"spec.nodeMirror: the nodes pull from their own addresses, " +
+			"which must be allowed by spec.networkPolicy.from, e.g. with an ipBlock of the node CIDR",
+	}
+}
+
+// validateBackup checks the schedule of the backups, that they are uploaded with static credentials, and that
+// the storage is a PersistentVolumeClaim the backup Jobs can mount. The storage is the effective one, possibly
+// taken from the RegistryClass.
+func validateBackup(b *registryv1alpha1.Backup, storage registryv1alpha1.Storage, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if _, err := cron.ParseStandard(b.Schedule); err != nil {
+		allErrs = append(allErrs, field.Invalid(path.Child("schedule"), b.Schedule, err.Error()))
+	}
+
+	if b.Destination.RootDirectory != "" {
+		allErrs = append(allErrs, field.Forbidden(path.Child("destination", "rootDirectory"), "use prefix instead"))
+	}
+	if b.Destination.AccessKey == nil {
+		allErrs = append(allErrs, field.Required(path.Child("destination", "accessKey"),
+			"the backups are uploaded with static credentials"))
+	}
+	if b.Destination.SecretKey == nil {
+		allErrs = append(allErrs, field.Required(path.Child("destination", "secretKey"),
+			"the backups are uploaded with static credentials"))
+	}
+
+	if backend := storageBackend(storage); backend != "" &&
+		backend != "persistentVolumeClaim" && backend != "persistentVolumeClaimTemplate" {
+		allErrs = append(allErrs, field.Forbidden(path, fmt.Sprintf(
+			"requires a persistentVolumeClaim or persistentVolumeClaimTemplate storage, not %s", backend,
+		)))
+	}
+
+	return allErrs
+}
+
+// validateSnapshots checks the schedule of the VolumeSnapshots, and that the storage is a
+// persistentVolumeClaimTemplate without a data source of its own when restoring from a VolumeSnapshot.
+func validateSnapshots(
+	s *registryv1alpha1.Snapshots,
+	storage registryv1alpha1.Storage,
+	path *field.Path,
+) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if s.Schedule != "" {
+		if _, err := cron.ParseStandard(s.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("schedule"), s.Schedule, err.Error()))
+		}
+	}
+
+	if backend := storageBackend(storage); backend != "" && backend != "persistentVolumeClaimTemplate" {
+		allErrs = append(allErrs, field.Forbidden(path, fmt.Sprintf(
+			"requires a persistentVolumeClaimTemplate storage, not %s", backend,
+		)))
+	}
+
+	if template := storage.PersistentVolumeClaimTemplate; s.RestoreFrom != "" && template != nil &&
+		(template.DataSource != nil || template.DataSourceRef != nil) {
+		allErrs = append(allErrs, field.Forbidden(path.Child("restoreFrom"),
+			"cannot be set with the dataSource or dataSourceRef of the persistentVolumeClaimTemplate"))
+	}
+
+	return allErrs
+}
+
+// validateUpdatePolicy checks that the image is left to the update channel, and the source repository, interval
+// and maintenance window of the updates.
+func validateUpdatePolicy(u *registryv1alpha1.UpdatePolicy, image string, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if image != "" {
+		allErrs = append(allErrs, field.Forbidden(path, "cannot be set with spec.image"))
+	}
+
+	if source := u.Source; source != nil && source.Repository != "" {
+		if _, err := reference.ParseNormalizedNamed(source.Repository); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("source", "repository"), source.Repository, err.Error()))
+		}
+	}
+
+	if u.Interval != nil && u.Interval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("interval"), u.Interval.Duration.String(), "must be positive"))
+	}
+
+	if w := u.MaintenanceWindow; w != nil {
+		if _, err := cron.ParseStandard(w.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("maintenanceWindow", "schedule"), w.Schedule, err.Error()))
+		}
+		if w.Duration.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(
+				path.Child("maintenanceWindow", "duration"), w.Duration.Duration.String(), "must be positive",
+			))
+		}
+	}
+
+	return allErrs
+}
+
+// validateResources checks that the requests of the resources do not exceed their limits.
+func validateResources(res *corev1.ResourceRequirements, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, name := range slices.Sorted(maps.Keys(res.Requests)) {
+		request := res.Requests[name]
+		limit, ok := res.Limits[name]
+		if ok && request.Cmp(limit) > 0 {
+			allErrs = append(allErrs, field.Invalid(
+				path.Child("requests").Key(string(name)),
+				request.String(),
+				"must be less than or equal to the limit "+limit.String(),
+			))
+		}
+	}
+	return allErrs
+}
+
+// replicasWarnings warns about running multiple replicas on storage that is not shared between them.
+func replicasWarnings(storage registryv1alpha1.Storage, replicas int32) admission.Warnings {
 	var warns admission.Warnings
 
-	if registry.Spec.Replicas > 1 {
+	if replicas > 1 && storage.S3 == nil {
 		warns = append(warns,
 			"If replicas > 1 and file/block storage is used, there is no data consistency between Registry replicas.",
 		)
@@ -13,3 +13,580 @@
 // limitations under the License.
 
 package v1alpha1
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registryv1alpha1 "github.com/registry-operator/registry-operator/api/v1alpha1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestRegistryCustomValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, registryv1alpha1.AddToScheme(scheme))
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "s3", Namespace: "my-namespace"},
+				Data:       map[string][]byte{"bucket": []byte("registry")},
+			},
+			&corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "rwo", Namespace: "my-namespace"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				},
+			},
+			&corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "rwx", Namespace: "my-namespace"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+				},
+			},
+			&registryv1alpha1.RegistryClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "local"},
+				Spec: registryv1alpha1.RegistryClassSpec{
+					Storage: registryv1alpha1.Storage{HostPath: &corev1.HostPathVolumeSource{Path: "/data"}},
+				},
+			},
+		).
+		Build()
+	validator := &RegistryCustomValidator{Client: cli}
+
+	registry := func(spec registryv1alpha1.RegistrySpec) *registryv1alpha1.Registry {
+		return &registryv1alpha1.Registry{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-instance", Namespace: "my-namespace"},
+			Spec:       spec,
+		}
+	}
+	pvc := func(name string) registryv1alpha1.Storage {
+		return registryv1alpha1.Storage{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: name},
+		}
+	}
+
+	t.Run("should warn about missing Secrets and keys", func(t *testing.T) {
+		warnings, err := validator.ValidateCreate(t.Context(), registry(registryv1alpha1.RegistrySpec{
+			Storage: registryv1alpha1.Storage{
+				S3: &registryv1alpha1.S3StorageSource{
+					BucketName: registryv1alpha1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "s3"},
+						Key:                  "bucket",
+					},
+					Region: registryv1alpha1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "s3"},
+						Key:                  "region",
+					},
+					AccessKey: &registryv1alpha1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "missing"},
+						Key:                  "accessKey",
+					},
+				},
+			},
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"spec.storage.s3.region: Secret s3 has no key region",
+			"spec.storage.s3.accessKey: Secret missing does not exist",
+		}, []string(warnings))
+	})
+
+	t.Run("should warn about a node mirror behind a restrictive NetworkPolicy", func(t *testing.T) {
+		warnings, err := validator.ValidateCreate(t.Context(), registry(registryv1alpha1.RegistrySpec{
+			Proxy:      &registryv1alpha1.Proxy{RemoteURL: "https://registry-1.docker.io"},
+			NodeMirror: &registryv1alpha1.NodeMirror{Hosts: []string{"docker.io"}},
+			NetworkPolicy: &registryv1alpha1.NetworkPolicy{
+				Enabled: true,
+				From:    []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{}}},
+			},
+		}))
+		assert.NoError(t, err)
+		require.Len(t, warnings, 1)
+		assert.Contains(t, warnings[0], "spec.networkPolicy.from")
+	})
+
+	for name, tc := range map[string]struct {
+		spec  registryv1alpha1.RegistrySpec
+		field string
+	}{
+		"should reject invalid image": {
+			spec:  registryv1alpha1.RegistrySpec{Image: "Registry:3"},
+			field: "spec.image",
+		},
+		"should reject replicas on hostPath": {
+			spec: registryv1alpha1.RegistrySpec{
+				Replicas: 2,
+				Storage:  registryv1alpha1.Storage{HostPath: &corev1.HostPathVolumeSource{Path: "/data"}},
+			},
+			field: "spec.replicas",
+		},
+		"should reject replicas on the hostPath of the class": {
+			spec:  registryv1alpha1.RegistrySpec{RegistryClassName: "local", Replicas: 2},
+			field: "spec.replicas",
+		},
+		"should reject backups of the hostPath of the class": {
+			spec:  registryv1alpha1.RegistrySpec{RegistryClassName: "local", Backup: backup("@daily")},
+			field: "spec.backup",
+		},
+		"should reject replicas on ReadWriteOnce claim": {
+			spec:  registryv1alpha1.RegistrySpec{Replicas: 2, Storage: pvc("rwo")},
+			field: "spec.replicas",
+		},
+		"should reject replicas on ReadWriteOnce claim template": {
+			spec: registryv1alpha1.RegistrySpec{
+				Replicas: 2,
+				Storage: registryv1alpha1.Storage{
+					PersistentVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					},
+				},
+			},
+			field: "spec.replicas",
+		},
+		"should reject requests above limits": {
+			spec: registryv1alpha1.RegistrySpec{
+				Resources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+					Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+				},
+			},
+			field: "spec.resources.requests[memory]",
+		},
+		"should reject invalid backup schedule": {
+			spec:  registryv1alpha1.RegistrySpec{Storage: pvc("rwo"), Backup: backup("every day")},
+			field: "spec.backup.schedule",
+		},
+		"should reject backups of an emptyDir": {
+			spec: registryv1alpha1.RegistrySpec{
+				Storage: registryv1alpha1.Storage{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+				Backup:  backup("@daily"),
+			},
+			field: "spec.backup",
+		},
+		"should reject backups without static credentials": {
+			spec: func() registryv1alpha1.RegistrySpec {
+				b := backup("@daily")
+				b.Destination.SecretKey = nil
+				return registryv1alpha1.RegistrySpec{Storage: pvc("rwo"), Backup: b}
+			}(),
+			field: "spec.backup.destination.secretKey",
+		},
+		"should reject invalid snapshot schedule": {
+			spec: registryv1alpha1.RegistrySpec{
+				Storage:   registryv1alpha1.Storage{PersistentVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{}},
+				Snapshots: &registryv1alpha1.Snapshots{Schedule: "0 25 * * *"},
+			},
+			field: "spec.snapshots.schedule",
+		},
+		"should reject snapshots of a claim not created from the template": {
+			spec: registryv1alpha1.RegistrySpec{
+				Storage:   pvc("rwo"),
+				Snapshots: &registryv1alpha1.Snapshots{Schedule: "@daily"},
+			},
+			field: "spec.snapshots",
+		},
+		"should reject restoring a template with a data source": {
+			spec: registryv1alpha1.RegistrySpec{
+				Storage: registryv1alpha1.Storage{PersistentVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{
+					DataSource: &corev1.TypedLocalObjectReference{Kind: "PersistentVolumeClaim", Name: "other"},
+				}},
+				Snapshots: &registryv1alpha1.Snapshots{RestoreFrom: "my-snapshot"},
+			},
+			field: "spec.snapshots.restoreFrom",
+		},
+		"should reject image rewrite prefixes of another registry": {
+			spec: registryv1alpha1.RegistrySpec{
+				Proxy: &registryv1alpha1.Proxy{
+					RemoteURL: "https://registry-1.docker.io",
+					ImageRewrite: &registryv1alpha1.ImageRewrite{
+						Prefixes: []string{"docker.io/library/", "quay.io/"},
+					},
+				},
+			},
+			field: "spec.proxy.imageRewrite.prefixes[1]",
+		},
+		"should reject authentication with a node mirror": {
+			spec: registryv1alpha1.RegistrySpec{
+				Authentication: &registryv1alpha1.Authentication{Enabled: true},
+				Proxy:          &registryv1alpha1.Proxy{RemoteURL: "https://registry-1.docker.io"},
+				NodeMirror:     &registryv1alpha1.NodeMirror{Hosts: []string{"docker.io"}},
+			},
+			field: "spec.authentication",
+		},
+		"should reject authentication with an image rewrite without pull Secret": {
+			spec: registryv1alpha1.RegistrySpec{
+				Authentication: &registryv1alpha1.Authentication{Enabled: true},
+				Proxy: &registryv1alpha1.Proxy{
+					RemoteURL:    "https://registry-1.docker.io",
+					ImageRewrite: &registryv1alpha1.ImageRewrite{Prefixes: []string{"docker.io/"}},
+				},
+			},
+			field: "spec.authentication",
+		},
+		"should reject a node mirror without proxy": {
+			spec: registryv1alpha1.RegistrySpec{
+				NodeMirror: &registryv1alpha1.NodeMirror{Hosts: []string{"docker.io"}},
+			},
+			field: "spec.proxy",
+		},
+		"should reject a node mirror of another registry": {
+			spec: registryv1alpha1.RegistrySpec{
+				Proxy:      &registryv1alpha1.Proxy{RemoteURL: "https://registry-1.docker.io"},
+				NodeMirror: &registryv1alpha1.NodeMirror{Hosts: []string{"docker.io", "quay.io"}},
+			},
+			field: "spec.nodeMirror.hosts[1]",
+		},
+		"should reject a node mirror writing outside the containerd configuration": {
+			spec: registryv1alpha1.RegistrySpec{
+				Proxy: &registryv1alpha1.Proxy{RemoteURL: "https://registry-1.docker.io"},
+				NodeMirror: &registryv1alpha1.NodeMirror{
+					Hosts:      []string{"docker.io"},
+					ConfigPath: "/etc/kubernetes",
+				},
+			},
+			field: "spec.nodeMirror.configPath",
+		},
+		"should reject an update channel with a pinned image": {
+			spec: registryv1alpha1.RegistrySpec{
+				Image:        "distribution/distribution:3.0.0",
+				UpdatePolicy: &registryv1alpha1.UpdatePolicy{Channel: "3.0.x"},
+			},
+			field: "spec.updatePolicy",
+		},
+		"should reject invalid maintenance window": {
+			spec: registryv1alpha1.RegistrySpec{
+				UpdatePolicy: &registryv1alpha1.UpdatePolicy{
+					Channel:           "3.0.x",
+					MaintenanceWindow: &registryv1alpha1.MaintenanceWindow{Schedule: "0 2 * * 0"},
+				},
+			},
+			field: "spec.updatePolicy.maintenanceWindow.duration",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := validator.ValidateCreate(t.Context(), registry(tc.spec))
+			require.Error(t, err)
+			assert.True(t, apierrors.IsInvalid(err))
+			assert.Contains(t, err.Error(), tc.field)
+		})
+	}
+
+	t.Run("should admit valid specs", func(t *testing.T) {
+		for _, spec := range []registryv1alpha1.RegistrySpec{
+			{Image: "distribution/distribution:3.0.0"},
+			{Replicas: 2, Storage: pvc("rwx")},
+			{Replicas: 2, Storage: pvc("created-later")},
+			{Storage: pvc("rwo"), Backup: backup("0 3 * * *")},
+			{
+				Proxy: &registryv1alpha1.Proxy{
+					RemoteURL:    "https://registry-1.docker.io",
+					ImageRewrite: &registryv1alpha1.ImageRewrite{Prefixes: []string{"docker.io/", "index.docker.io/"}},
+				},
+			},
+			{
+				Proxy: &registryv1alpha1.Proxy{RemoteURL: "https://registry-1.docker.io"},
+				NodeMirror: &registryv1alpha1.NodeMirror{
+					Hosts:      []string{"docker.io"},
+					ConfigPath: "/var/lib/rancher/k3s/agent/etc/containerd/certs.d",
+				},
+			},
+			{
+				UpdatePolicy: &registryv1alpha1.UpdatePolicy{
+					Channel: "3.0.x",
+					Source:  &registryv1alpha1.UpdateSource{Repository: "ghcr.io/distribution/distribution"},
+					MaintenanceWindow: &registryv1alpha1.MaintenanceWindow{
+						Schedule: "0 2 * * 0",
+						Duration: metav1.Duration{Duration: 2 * time.Hour},
+					},
+				},
+			},
+			{
+				Storage: registryv1alpha1.Storage{PersistentVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{}},
+				Snapshots: &registryv1alpha1.Snapshots{
+					Schedule:    "0 2 * * *",
+					RestoreFrom: "my-instance-registry-20250102-020000",
+				},
+			},
+			{
+				Resources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			},
+		} {
+			_, err := validator.ValidateCreate(t.Context(), registry(spec))
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("should admit updates leaving an invalid spec untouched", func(t *testing.T) {
+		old := registry(registryv1alpha1.RegistrySpec{Replicas: 2, Storage: pvc("rwo")})
+		updated := old.DeepCopy()
+		updated.Finalizers = nil
+
+		_, err := validator.ValidateUpdate(t.Context(), old, updated)
+		assert.NoError(t, err)
+
+		updated.Spec.Replicas = 3
+		_, err = validator.ValidateUpdate(t.Context(), old, updated)
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject switching away from a persistent storage", func(t *testing.T) {
+		old := registry(registryv1alpha1.RegistrySpec{Storage: pvc("rwo")})
+		old.Generation = 3
+		updated := old.DeepCopy()
+		updated.Spec.Storage = registryv1alpha1.Storage{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+
+		_, err := validator.ValidateUpdate(t.Context(), old, updated)
+		require.Error(t, err)
+		assert.True(t, apierrors.IsInvalid(err))
+		assert.Contains(t, err.Error(), "spec.storage: Forbidden")
+
+		updated.Spec.Storage = pvc("rwx")
+		_, err = validator.ValidateUpdate(t.Context(), old, updated)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "spec.storage.persistentVolumeClaim.claimName: Forbidden")
+
+		// the annotation allows the change of its generation only
+		updated.Annotations = map[string]string{registryv1alpha1.AllowStorageChangeAnnotation: "2"}
+		_, err = validator.ValidateUpdate(t.Context(), old, updated)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `registry-operator.dev/allow-storage-change: "3" annotation`)
+
+		updated.Annotations = map[string]string{registryv1alpha1.AllowStorageChangeAnnotation: "3"}
+		_, err = validator.ValidateUpdate(t.Context(), old, updated)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject moving a persistent storage", func(t *testing.T) {
+		hostPath := func(path string) registryv1alpha1.Storage {
+			return registryv1alpha1.Storage{HostPath: &corev1.HostPathVolumeSource{Path: path}}
+		}
+		old := registry(registryv1alpha1.RegistrySpec{Storage: hostPath("/var/lib/registry")})
+		updated := old.DeepCopy()
+		updated.Spec.Storage = hostPath("/srv/registry")
+
+		_, err := validator.ValidateUpdate(t.Context(), old, updated)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "spec.storage.hostPath.path: Forbidden")
+
+		selector := func(name, key string) registryv1alpha1.SecretKeySelector {
+			return registryv1alpha1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Key:                  key,
+			}
+		}
+		s3 := &registryv1alpha1.S3StorageSource{
+			BucketName:    selector("s3", "bucket"),
+			Region:        selector("s3", "region"),
+			RootDirectory: "/registry/default/my-registry",
+		}
+		old = registry(registryv1alpha1.RegistrySpec{Storage: registryv1alpha1.Storage{S3: s3}})
+		updated = old.DeepCopy()
+		updated.Spec.Storage.S3.BucketName = selector("other", "bucket")
+		updated.Spec.Storage.S3.RootDirectory = "/registry/default/other"
+
+		_, err = validator.ValidateUpdate(t.Context(), old, updated)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "spec.storage.s3.bucketName: Forbidden")
+		assert.Contains(t, err.Error(), "spec.storage.s3.rootDirectory: Forbidden")
+
+		updated = old.DeepCopy()
+		updated.Spec.Storage.S3.AccessKey = ptr.To(selector("s3", "accessKey"))
+		_, err = validator.ValidateUpdate(t.Context(), old, updated)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject switching from a persistent storage to the storage of the class", func(t *testing.T) {
+		old := registry(registryv1alpha1.RegistrySpec{Storage: pvc("rwo")})
+		updated := old.DeepCopy()
+		updated.Spec.RegistryClassName = "local"
+		updated.Spec.Storage = registryv1alpha1.Storage{}
+
+		_, err := validator.ValidateUpdate(t.Context(), old, updated)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "from persistentVolumeClaim to hostPath")
+	})
+
+	t.Run("should admit switching away from an emptyDir storage", func(t *testing.T) {
+		old := registry(registryv1alpha1.RegistrySpec{
+			Storage: registryv1alpha1.Storage{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		updated := old.DeepCopy()
+		updated.Spec.Storage = pvc("rwo")
+
+		_, err := validator.ValidateUpdate(t.Context(), old, updated)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject shrinking the claim template", func(t *testing.T) {
+		template := func(size string) registryv1alpha1.Storage {
+			return registryv1alpha1.Storage{
+				PersistentVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+					},
+				},
+			}
+		}
+		old := registry(registryv1alpha1.RegistrySpec{Storage: template("10Gi")})
+		updated := old.DeepCopy()
+		updated.Spec.Storage = template("20Gi")
+
+		_, err := validator.ValidateUpdate(t.Context(), old, updated)
+		assert.NoError(t, err)
+
+		updated.Spec.Storage = template("5Gi")
+		updated.Annotations = map[string]string{registryv1alpha1.AllowStorageChangeAnnotation: "0"}
+		_, err = validator.ValidateUpdate(t.Context(), old, updated)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"spec.storage.persistentVolumeClaimTemplate.resources.requests[storage]: Forbidden")
+	})
+
+	t.Run("should admit migrating a persistent storage to S3", func(t *testing.T) {
+		selector := func(key string) *registryv1alpha1.SecretKeySelector {
+			return &registryv1alpha1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "s3"},
+				Key:                  key,
+			}
+		}
+		old := registry(registryv1alpha1.RegistrySpec{
+			Storage:               pvc("rwo"),
+			StorageUpdateStrategy: registryv1alpha1.StorageUpdateStrategyMigrate,
+		})
+		updated := old.DeepCopy()
+		updated.Spec.Storage = registryv1alpha1.Storage{S3: &registryv1alpha1.S3StorageSource{
+			BucketName: *selector("bucket"),
+			Region:     *selector("region"),
+		}}
+
+		_, err := validator.ValidateUpdate(t.Context(), old, updated)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "spec.storage.s3: Required value")
+
+		updated.Spec.Storage.S3.AccessKey = selector("accessKey")
+		updated.Spec.Storage.S3.SecretKey = selector("secretKey")
+		_, err = validator.ValidateUpdate(t.Context(), old, updated)
+		assert.NoError(t, err)
+
+		updated.Spec.StorageUpdateStrategy = registryv1alpha1.StorageUpdateStrategyReplace
+		_, err = validator.ValidateUpdate(t.Context(), old, updated)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "spec.storage: Forbidden")
+	})
+}
+
+func backup(schedule string) *registryv1alpha1.Backup {
+	selector := func(key string) *registryv1alpha1.SecretKeySelector {
+		return &registryv1alpha1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "backup"},
+			Key:                  key,
+		}
+	}
+	return &registryv1alpha1.Backup{
+		Schedule: schedule,
+		Destination: registryv1alpha1.BackupDestination{
+			S3StorageSource: registryv1alpha1.S3StorageSource{
+				BucketName: *selector("bucket"),
+				Region:     *selector("region"),
+				AccessKey:  selector("accessKey"),
+				SecretKey:  selector("secretKey"),
+			},
+		},
+	}
+}
+
+func TestRegistryCustomDefaulter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, registryv1alpha1.AddToScheme(scheme))
+	defaulter := &RegistryCustomDefaulter{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+
+	s3 := func(root string) registryv1alpha1.Storage {
+		return registryv1alpha1.Storage{S3: &registryv1alpha1.S3StorageSource{RootDirectory: root}}
+	}
+	request := func(t *testing.T, operation admissionv1.Operation, old *registryv1alpha1.Registry) admission.Request {
+		req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Namespace: "my-namespace",
+			Operation: operation,
+		}}
+		if old != nil {
+			raw, err := json.Marshal(old)
+			require.NoError(t, err)
+			req.OldObject = runtime.RawExtension{Raw: raw}
+		}
+		return req
+	}
+
+	for name, tc := range map[string]struct {
+		operation admissionv1.Operation
+		old       registryv1alpha1.Storage
+		storage   registryv1alpha1.Storage
+		expected  string
+	}{
+		"should assign its own root directory to a new Registry": {
+			operation: admissionv1.Create,
+			storage:   s3(""),
+			expected:  "/registry/my-namespace/my-instance",
+		},
+		"should assign its own root directory to a Registry moved to S3": {
+			operation: admissionv1.Update,
+			old:       registryv1alpha1.Storage{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			storage:   s3(""),
+			expected:  "/registry/my-namespace/my-instance",
+		},
+		"should keep the shared root directory of a Registry already in S3": {
+			operation: admissionv1.Update,
+			old:       s3(""),
+			storage:   s3(""),
+			expected:  "",
+		},
+		"should keep the root directory of a Registry when it is omitted": {
+			operation: admissionv1.Update,
+			old:       s3("/registry/my-namespace/my-instance"),
+			storage:   s3(""),
+			expected:  "/registry/my-namespace/my-instance",
+		},
+		"should keep an explicit root directory": {
+			operation: admissionv1.Create,
+			storage:   s3("/team/registry"),
+			expected:  "/team/registry",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			registry := &registryv1alpha1.Registry{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-instance"},
+				Spec:       registryv1alpha1.RegistrySpec{Storage: tc.storage},
+			}
+			var old *registryv1alpha1.Registry
+			if tc.operation == admissionv1.Update {
+				old = registry.DeepCopy()
+				old.Spec.Storage = tc.old
+			}
+
+			ctx := admission.NewContextWithRequest(t.Context(), request(t, tc.operation, old))
+			require.NoError(t, defaulter.Default(ctx, registry))
+			assert.Equal(t, tc.expected, registry.Spec.Storage.S3.RootDirectory)
+		})
+	}
+}